@@ -73,6 +73,13 @@ type SSOApplication struct {
 	OrgID             string                 `json:"orgId,omitempty"`
 }
 
+// ssoApplicationImportLookup resolves name:<name> import IDs
+var ssoApplicationImportLookup = common.ImportLookup{
+	Kind:     "SSO application",
+	ListPath: "/api/v2/applications",
+	Fields:   map[string]string{"name": "name"},
+}
+
 // ResourceSSOApplication returns the resource schema for JumpCloud SSO application
 func ResourceSSOApplication() *schema.Resource {
 	return &schema.Resource{
@@ -334,7 +341,7 @@ func ResourceSSOApplication() *schema.Resource {
 		},
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: common.ImportStateResolver(ssoApplicationImportLookup),
 		},
 	}
 }
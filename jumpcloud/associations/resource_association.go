@@ -0,0 +1,366 @@
+package associations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// associationTypePaths maps each graph object type to its v2 path segment
+var associationTypePaths = map[string]string{
+	"active_directory": "activedirectories",
+	"application":      "applications",
+	"command":          "commands",
+	"g_suite":          "gsuites",
+	"ldap_server":      "ldapservers",
+	"office_365":       "office365s",
+	"policy":           "policies",
+	"policy_group":     "policygroups",
+	"radius_server":    "radiusservers",
+	"system":           "systems",
+	"system_group":     "systemgroups",
+	"user":             "users",
+	"user_group":       "usergroups",
+}
+
+// validAssociationTargets lists, for each source type, the target types the graph accepts
+var validAssociationTargets = map[string][]string{
+	"active_directory": {"user", "user_group"},
+	"application":      {"user", "user_group"},
+	"command":          {"system", "system_group"},
+	"g_suite":          {"user", "user_group"},
+	"ldap_server":      {"user", "user_group"},
+	"office_365":       {"user", "user_group"},
+	"policy":           {"system", "system_group"},
+	"policy_group":     {"system", "system_group"},
+	"radius_server":    {"user", "user_group"},
+	"system":           {"command", "policy", "policy_group", "user", "user_group"},
+	"system_group":     {"command", "policy", "policy_group", "user", "user_group"},
+	"user":             {"active_directory", "application", "g_suite", "ldap_server", "office_365", "radius_server", "system", "system_group"},
+	"user_group":       {"active_directory", "application", "g_suite", "ldap_server", "office_365", "radius_server", "system", "system_group"},
+}
+
+// ResourceAssociation returns the resource managing a single edge of the JumpCloud v2 graph
+func ResourceAssociation() *schema.Resource {
+	types := make([]string, 0, len(associationTypePaths))
+	for t := range associationTypePaths {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return &schema.Resource{
+		CreateContext: resourceAssociationCreate,
+		ReadContext:   resourceAssociationRead,
+		UpdateContext: resourceAssociationUpdate,
+		DeleteContext: resourceAssociationDelete,
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			return validateAssociationPair(diff.Get("from_type").(string), diff.Get("to_type").(string))
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAssociationImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"from_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(types, false),
+				Description:  fmt.Sprintf("Type of the source object. One of: %s", strings.Join(types, ", ")),
+			},
+			"from_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the source object",
+			},
+			"to_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(types, false),
+				Description:  "Type of the target object. Must be a valid target for from_type",
+			},
+			"to_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the target object",
+			},
+			"attributes": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: common.SuppressEquivalentJSONDiffs,
+				Description:      "Edge attributes as a JSON object, e.g. jsonencode({ sudo = { enabled = true, withoutPassword = false } }). Only tracked when set: default attributes set by the API are ignored, and removing the argument clears the attributes of the edge",
+			},
+		},
+		Description: "Manages an association between two objects of the JumpCloud graph through the v2 /associations endpoints.",
+	}
+}
+
+// validateAssociationPair checks that the graph accepts an edge between the two types
+func validateAssociationPair(fromType, toType string) error {
+	// Unknown values are validated once they are known
+	if fromType == "" || toType == "" {
+		return nil
+	}
+
+	targets, ok := validAssociationTargets[fromType]
+	if !ok {
+		return fmt.Errorf("unsupported from_type %q", fromType)
+	}
+	for _, t := range targets {
+		if t == toType {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s cannot be associated with %s, valid to_type values are: %s", fromType, toType, strings.Join(targets, ", "))
+}
+
+// associationPath returns the /associations endpoint of the source object
+func associationPath(fromType, fromID string) string {
+	return fmt.Sprintf("/api/v2/%s/%s/associations", associationTypePaths[fromType], fromID)
+}
+
+// parseAssociationID splits an ID of the form from_type/from_id/to_type/to_id
+func parseAssociationID(id string) (string, string, string, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+		return "", "", "", "", fmt.Errorf("invalid ID format, expected 'from_type/from_id/to_type/to_id', got: %s", id)
+	}
+	return parts[0], parts[1], parts[2], parts[3], nil
+}
+
+// expandAssociationAttributes decodes the attributes JSON
+func expandAssociationAttributes(d *schema.ResourceData) (map[string]interface{}, error) {
+	raw := d.Get("attributes").(string)
+	if raw == "" {
+		return nil, nil
+	}
+
+	var attributes map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &attributes); err != nil {
+		return nil, fmt.Errorf("error parsing attributes: %v", err)
+	}
+	return attributes, nil
+}
+
+// associationUpdateOp is an update operation that always carries its attributes, since the omitempty
+// attributes of common.GraphAssociationOp would drop the empty object that clears them
+type associationUpdateOp struct {
+	common.GraphAssociationOp
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// sendAssociationOp posts an add, update or remove operation to the association endpoint
+func sendAssociationOp(ctx context.Context, c common.ClientInterface, fromType, fromID string, op common.GraphAssociationOp) error {
+	var body interface{} = op
+	if op.Op == "update" {
+		attributes := op.Attributes
+		if attributes == nil {
+			attributes = map[string]interface{}{}
+		}
+		body = associationUpdateOp{GraphAssociationOp: op, Attributes: attributes}
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("error serializing request body: %v", err)
+	}
+
+	path := associationPath(fromType, fromID)
+	tflog.Debug(ctx, fmt.Sprintf("Sending association %s to %s for %s %s", op.Op, path, op.Type, op.ID))
+
+	_, err = c.DoRequest(http.MethodPost, path, jsonData)
+	return err
+}
+
+func resourceAssociationCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	fromType := d.Get("from_type").(string)
+	fromID := d.Get("from_id").(string)
+	toType := d.Get("to_type").(string)
+	toID := d.Get("to_id").(string)
+
+	attributes, err := expandAssociationAttributes(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	op := common.GraphAssociationOp{Op: "add", Type: toType, ID: toID, Attributes: attributes}
+	if err := sendAssociationOp(ctx, c, fromType, fromID, op); err != nil {
+		return diag.FromErr(fmt.Errorf("error associating %s %s with %s %s: %v", fromType, fromID, toType, toID, err))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s/%s/%s", fromType, fromID, toType, toID))
+
+	return resourceAssociationRead(ctx, d, meta)
+}
+
+func resourceAssociationRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	fromType, fromID, toType, toID, err := parseAssociationID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	association, err := findAssociation(ctx, c, fromType, fromID, toType, toID)
+	if err != nil {
+		if common.IsNotFoundError(err) {
+			tflog.Warn(ctx, fmt.Sprintf("%s %s not found, removing association from state", fromType, fromID))
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(fmt.Errorf("error reading associations of %s %s: %v", fromType, fromID, err))
+	}
+	if association == nil {
+		tflog.Warn(ctx, fmt.Sprintf("Association %s not found, removing from state", d.Id()))
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("from_type", fromType); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting from_type: %v", err))
+	}
+	if err := d.Set("from_id", fromID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting from_id: %v", err))
+	}
+	if err := d.Set("to_type", toType); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting to_type: %v", err))
+	}
+	if err := d.Set("to_id", toID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting to_id: %v", err))
+	}
+
+	// Attributes are only refreshed when configured, so that the defaults the API sets on some
+	// edges (e.g. sudo on user to system) do not show up as a diff
+	if current := d.Get("attributes").(string); current != "" {
+		attributes := association.Attributes
+		if len(attributes) == 0 {
+			attributes = association.To.Attributes
+		}
+		if len(attributes) > 0 {
+			if err := d.Set("attributes", common.FlattenMapToJSON(attributes)); err != nil {
+				return diag.FromErr(fmt.Errorf("error setting attributes: %v", err))
+			}
+		} else if !common.SuppressEquivalentJSONDiffs("attributes", current, "{}", d) {
+			// Attributes removed outside of Terraform show up as drift
+			if err := d.Set("attributes", ""); err != nil {
+				return diag.FromErr(fmt.Errorf("error setting attributes: %v", err))
+			}
+		}
+	}
+
+	return diags
+}
+
+func resourceAssociationUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	fromType, fromID, toType, toID, err := parseAssociationID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChange("attributes") {
+		attributes, err := expandAssociationAttributes(d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		op := common.GraphAssociationOp{Op: "update", Type: toType, ID: toID, Attributes: attributes}
+		if err := sendAssociationOp(ctx, c, fromType, fromID, op); err != nil {
+			return diag.FromErr(fmt.Errorf("error updating association %s: %v", d.Id(), err))
+		}
+	}
+
+	return resourceAssociationRead(ctx, d, meta)
+}
+
+func resourceAssociationDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	fromType, fromID, toType, toID, err := parseAssociationID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	op := common.GraphAssociationOp{Op: "remove", Type: toType, ID: toID}
+	if err := sendAssociationOp(ctx, c, fromType, fromID, op); err != nil {
+		// Ignore error if either side was already removed
+		if common.IsNotFoundError(err) {
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(fmt.Errorf("error removing association %s: %v", d.Id(), err))
+	}
+
+	d.SetId("")
+
+	return diags
+}
+
+func resourceAssociationImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	fromType, _, toType, _, err := parseAssociationID(d.Id())
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := associationTypePaths[fromType]; !ok {
+		return nil, fmt.Errorf("unsupported from_type %q", fromType)
+	}
+	if err := validateAssociationPair(fromType, toType); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// findAssociation looks up the edge to the target among the associations of the source object.
+// It returns nil when the edge does not exist.
+func findAssociation(ctx context.Context, c common.ClientInterface, fromType, fromID, toType, toID string) (*common.GraphAssociation, error) {
+	path := fmt.Sprintf("%s?targets=%s", associationPath(fromType, fromID), toType)
+	tflog.Debug(ctx, fmt.Sprintf("Reading associations with URL: %s", path))
+
+	edges, err := common.ListGraphAssociations(c, path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range edges {
+		if edges[i].To.ID == toID && edges[i].To.Type == toType {
+			return &edges[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,151 @@
+package associations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// TestResourceAssociationSchema tests the schema structure of the association resource
+func TestResourceAssociationSchema(t *testing.T) {
+	s := ResourceAssociation()
+
+	for _, field := range []string{"from_type", "from_id", "to_type", "to_id"} {
+		if s.Schema[field] == nil || !s.Schema[field].Required || !s.Schema[field].ForceNew {
+			t.Errorf("Expected %s to be a required ForceNew field", field)
+		}
+	}
+	if s.Schema["attributes"] == nil || !s.Schema["attributes"].Optional || s.Schema["attributes"].ForceNew {
+		t.Error("Expected attributes to be an optional updatable field")
+	}
+	if s.Importer == nil {
+		t.Error("Expected the resource to be importable")
+	}
+}
+
+func TestValidateAssociationPair(t *testing.T) {
+	cases := []struct {
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{"user_group", "ldap_server", false},
+		{"user_group", "radius_server", false},
+		{"system_group", "policy", false},
+		{"user_group", "g_suite", false},
+		{"user", "system", false},
+		{"", "system", false},
+		{"user", "user", true},
+		{"policy", "user_group", true},
+		{"command", "user", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.from+"->"+tc.to, func(t *testing.T) {
+			err := validateAssociationPair(tc.from, tc.to)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAssociationTypesHavePaths(t *testing.T) {
+	for from, targets := range validAssociationTargets {
+		if _, ok := associationTypePaths[from]; !ok {
+			t.Errorf("Missing path for source type %s", from)
+		}
+		for _, to := range targets {
+			if _, ok := associationTypePaths[to]; !ok {
+				t.Errorf("Missing path for target type %s", to)
+			}
+		}
+	}
+}
+
+func TestParseAssociationID(t *testing.T) {
+	fromType, fromID, toType, toID, err := parseAssociationID("user_group/g1/ldap_server/l1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fromType != "user_group" || fromID != "g1" || toType != "ldap_server" || toID != "l1" {
+		t.Errorf("Unexpected parts: %s %s %s %s", fromType, fromID, toType, toID)
+	}
+
+	for _, id := range []string{"user_group/g1/ldap_server", "user_group//ldap_server/l1", "g1:l1"} {
+		if _, _, _, _, err := parseAssociationID(id); err == nil {
+			t.Errorf("Expected error for %q", id)
+		}
+	}
+}
+
+// associationTestClient serves one edge with the given attributes and records the operations
+type associationTestClient struct {
+	attributes map[string]interface{}
+	ops        []map[string]interface{}
+}
+
+func (c *associationTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	switch method {
+	case http.MethodGet:
+		return json.Marshal([]map[string]interface{}{{
+			"attributes": c.attributes,
+			"to":         map[string]interface{}{"id": "s1", "type": "system"},
+		}})
+	case http.MethodPost:
+		var op map[string]interface{}
+		if err := json.Unmarshal(body, &op); err != nil {
+			return nil, err
+		}
+		c.ops = append(c.ops, op)
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected request %s %s", method, path)
+}
+
+func (c *associationTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *associationTestClient) GetApiKey() string { return "test" }
+
+func (c *associationTestClient) GetOrgID() string { return "" }
+
+func TestResourceAssociationAttributes(t *testing.T) {
+	r := ResourceAssociation()
+	client := &associationTestClient{attributes: map[string]interface{}{"sudo": map[string]interface{}{"enabled": true}}}
+	edge := map[string]interface{}{"from_type": "user", "from_id": "u1", "to_type": "system", "to_id": "s1"}
+	state := &terraform.InstanceState{ID: "user/u1/system/s1", Attributes: map[string]string{
+		"id": "user/u1/system/s1", "from_type": "user", "from_id": "u1", "to_type": "system", "to_id": "s1",
+	}}
+
+	// Default attributes set by the API are ignored when attributes is not configured
+	d := r.Data(state)
+	if diags := resourceAssociationRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if attributes := d.Get("attributes"); attributes != "" {
+		t.Errorf("expected the default attributes to be ignored, got %v", attributes)
+	}
+
+	// Removing configured attributes clears them with an empty object
+	state.Attributes["attributes"] = `{"sudo":{"enabled":true}}`
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(edge), client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.attributes = nil
+	if _, diags := r.Apply(context.Background(), state, diff, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(client.ops) != 1 || client.ops[0]["op"] != "update" {
+		t.Fatalf("expected one update, got %v", client.ops)
+	}
+	if attributes, ok := client.ops[0]["attributes"].(map[string]interface{}); !ok || len(attributes) != 0 {
+		t.Errorf("expected the update to send empty attributes, got %v", client.ops[0])
+	}
+}
@@ -31,6 +31,13 @@ type IPList struct {
 	Updated     string           `json:"updated,omitempty"`
 }
 
+// ipListImportLookup resolves name:<name> import IDs
+var ipListImportLookup = common.ImportLookup{
+	Kind:     "IP list",
+	ListPath: "/api/v2/ip-lists",
+	Fields:   map[string]string{"name": "name"},
+}
+
 func ResourceList() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceListCreate,
@@ -94,7 +101,7 @@ func ResourceList() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: common.ImportStateResolver(ipListImportLookup),
 		},
 	}
 }
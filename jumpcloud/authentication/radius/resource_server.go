@@ -28,6 +28,13 @@ type RadiusServer struct {
 	Updated                      string   `json:"updated,omitempty"`
 }
 
+// radiusServerImportLookup resolves name:<name> import IDs
+var radiusServerImportLookup = common.ImportLookup{
+	Kind:     "RADIUS server",
+	ListPath: "/api/v2/radiusservers",
+	Fields:   map[string]string{"name": "name"},
+}
+
 // ResourceServer returns the resource for managing RADIUS servers
 func ResourceServer() *schema.Resource {
 	return &schema.Resource{
@@ -103,7 +110,7 @@ func ResourceServer() *schema.Resource {
 		},
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: common.ImportStateResolver(radiusServerImportLookup),
 		},
 		Description: "Manages RADIUS servers in JumpCloud. This resource allows creating, updating, and deleting RADIUS server configurations.",
 	}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// graphAssociationsPageSize is the number of edges requested per page of the v2 graph endpoints
+const graphAssociationsPageSize = 100
+
+// ListGraphAssociations pages through a v2 associations or members endpoint and returns every edge.
+// The path may already carry a query, e.g. the targets filter
+func ListGraphAssociations(c ClientInterface, path string) ([]GraphAssociation, error) {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	var edges []GraphAssociation
+	for skip := 0; ; {
+		resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("%s%slimit=%d&skip=%d", path, separator, graphAssociationsPageSize, skip), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []GraphAssociation
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing associations response: %v", err)
+		}
+		edges = append(edges, page...)
+
+		if len(page) < graphAssociationsPageSize {
+			return edges, nil
+		}
+		skip += len(page)
+	}
+}
@@ -0,0 +1,50 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// graphTestClient serves the edges in pages of 100
+type graphTestClient struct {
+	edges    int
+	requests []string
+}
+
+func (c *graphTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	c.requests = append(c.requests, path)
+	var skip int
+	fmt.Sscanf(path[strings.Index(path, "skip=")+5:], "%d", &skip)
+	page := make([]map[string]interface{}, 0)
+	for i := skip; i < c.edges && i < skip+100; i++ {
+		page = append(page, map[string]interface{}{"to": map[string]string{"id": fmt.Sprintf("s%d", i), "type": "system"}})
+	}
+	return json.Marshal(page)
+}
+
+func (c *graphTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *graphTestClient) GetApiKey() string { return "test" }
+
+func (c *graphTestClient) GetOrgID() string { return "" }
+
+func TestListGraphAssociations(t *testing.T) {
+	client := &graphTestClient{edges: 230}
+	edges, err := ListGraphAssociations(client, "/api/v2/policies/p1/associations?targets=system")
+	if err != nil || len(edges) != 230 || edges[229].To.ID != "s229" {
+		t.Fatalf("expected 230 edges over 3 pages, got %d: %v", len(edges), err)
+	}
+	if client.requests[0] != "/api/v2/policies/p1/associations?targets=system&limit=100&skip=0" {
+		t.Errorf("unexpected first request %s", client.requests[0])
+	}
+
+	client = &graphTestClient{}
+	if _, err := ListGraphAssociations(client, "/api/v2/policygroups/g1/members"); err != nil || client.requests[0] != "/api/v2/policygroups/g1/members?limit=100&skip=0" {
+		t.Errorf("unexpected request %v: %v", client.requests, err)
+	}
+}
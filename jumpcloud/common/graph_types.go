@@ -0,0 +1,40 @@
+package common
+
+// GraphObject identifies a node of the JumpCloud v2 graph
+type GraphObject struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// GraphPathEdge represents one hop of a graph traversal path
+type GraphPathEdge struct {
+	To         GraphObject            `json:"to"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// GraphConnection represents a resource returned by the v2 graph traversal endpoints,
+// together with every path that connects it to the queried object
+type GraphConnection struct {
+	ID                 string                 `json:"id"`
+	Type               string                 `json:"type"`
+	CompiledAttributes map[string]interface{} `json:"compiledAttributes,omitempty"`
+	Paths              [][]GraphPathEdge      `json:"paths,omitempty"`
+}
+
+// GraphAssociation represents a direct association returned by the v2 /associations endpoints
+type GraphAssociation struct {
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	To         struct {
+		ID         string                 `json:"id"`
+		Type       string                 `json:"type"`
+		Attributes map[string]interface{} `json:"attributes,omitempty"`
+	} `json:"to"`
+}
+
+// GraphAssociationOp is the request body used to add, update or remove a v2 association
+type GraphAssociationOp struct {
+	Op         string                 `json:"op"`
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
@@ -0,0 +1,180 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// importPageSize is the number of objects requested per page while resolving an import ID
+const importPageSize = 100
+
+// ImportLookup describes how human-readable import IDs are resolved for a resource type
+type ImportLookup struct {
+	// Kind is the name of the object used in error messages, e.g. "user group"
+	Kind string
+	// ListPath is the endpoint listing the objects, e.g. "/api/v2/usergroups"
+	ListPath string
+	// Fields maps the accepted import prefixes to the API field they match, e.g. "name" -> "name"
+	Fields map[string]string
+}
+
+// ImportID is the parsed form of an import ID
+type ImportID struct {
+	OrgID string
+	Field string
+	Value string
+}
+
+// ParseImportID splits an import ID of the form [org/<org_id>/][<field>:]<value>.
+// A value without a known field prefix is an object ID.
+func ParseImportID(raw string, fields map[string]string) ImportID {
+	var id ImportID
+
+	if strings.HasPrefix(raw, "org/") {
+		parts := strings.SplitN(strings.TrimPrefix(raw, "org/"), "/", 2)
+		if len(parts) == 2 {
+			id.OrgID = parts[0]
+			raw = parts[1]
+		}
+	}
+
+	if i := strings.Index(raw, ":"); i > 0 {
+		if _, ok := fields[raw[:i]]; ok {
+			id.Field = raw[:i]
+			id.Value = raw[i+1:]
+			return id
+		}
+	}
+
+	id.Value = raw
+	return id
+}
+
+// ResolveImportID resolves an import ID into the ID of a single object.
+// It fails when the organization does not match the provider or when a lookup
+// matches no object or several objects.
+func ResolveImportID(ctx context.Context, c ClientInterface, lookup ImportLookup, raw string) (string, error) {
+	id := ParseImportID(raw, lookup.Fields)
+
+	if id.OrgID != "" && c.GetOrgID() != "" && id.OrgID != c.GetOrgID() {
+		return "", fmt.Errorf("cannot import %s %q: organization %s does not match the provider organization %s", lookup.Kind, raw, id.OrgID, c.GetOrgID())
+	}
+
+	if id.Value == "" {
+		return "", fmt.Errorf("cannot import %s: empty import ID %q", lookup.Kind, raw)
+	}
+
+	if id.Field == "" {
+		return id.Value, nil
+	}
+
+	objects, err := listImportCandidates(ctx, c, lookup.ListPath)
+	if err != nil {
+		return "", fmt.Errorf("error listing %ss to resolve import ID %q: %v", lookup.Kind, raw, err)
+	}
+
+	matches := MatchImportCandidates(objects, lookup.Fields[id.Field], id.Value)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no %s found with %s %q", lookup.Kind, id.Field, id.Value)
+	case 1:
+		tflog.Debug(ctx, fmt.Sprintf("Resolved import ID %q to %s %s", raw, lookup.Kind, matches[0]))
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("import ID %q is ambiguous: %d %ss have %s %q (%s), import by ID instead", raw, len(matches), lookup.Kind, id.Field, id.Value, strings.Join(matches, ", "))
+	}
+}
+
+// MatchImportCandidates returns the sorted IDs of the objects whose field equals value exactly
+func MatchImportCandidates(objects []map[string]interface{}, field, value string) []string {
+	matches := make([]string, 0)
+
+	for _, obj := range objects {
+		v, ok := obj[field].(string)
+		if !ok || v != value {
+			continue
+		}
+		if id := importObjectID(obj); id != "" {
+			matches = append(matches, id)
+		}
+	}
+
+	sort.Strings(matches)
+	return matches
+}
+
+// ImportStateResolver returns an import function resolving human-readable import IDs with the given lookup
+func ImportStateResolver(lookup ImportLookup) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		c, diagErr := GetClientFromMeta(meta)
+		if diagErr != nil {
+			return nil, fmt.Errorf("error getting client: %s", diagErr[0].Summary)
+		}
+
+		id, err := ResolveImportID(ctx, c, lookup, d.Id())
+		if err != nil {
+			return nil, err
+		}
+
+		d.SetId(id)
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// importObjectID returns the ID of an object, v1 endpoints use "_id" and v2 endpoints "id"
+func importObjectID(obj map[string]interface{}) string {
+	if id, ok := obj["id"].(string); ok && id != "" {
+		return id
+	}
+	if id, ok := obj["_id"].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// listImportCandidates retrieves every object of a list endpoint, following pagination.
+// Both v2 arrays and v1 {"results": [...]} envelopes are supported.
+func listImportCandidates(ctx context.Context, c ClientInterface, listPath string) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+
+	separator := "?"
+	if strings.Contains(listPath, "?") {
+		separator = "&"
+	}
+
+	for skip := 0; ; skip += importPageSize {
+		path := fmt.Sprintf("%s%slimit=%d&skip=%d", listPath, separator, importPageSize, skip)
+		tflog.Debug(ctx, fmt.Sprintf("Listing import candidates with URL: %s", path))
+
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []map[string]interface{}
+		if err := json.Unmarshal(resp, &page); err != nil {
+			var envelope struct {
+				Results []map[string]interface{} `json:"results"`
+			}
+			if err := json.Unmarshal(resp, &envelope); err != nil {
+				return nil, fmt.Errorf("error parsing list response: %v", err)
+			}
+			page = envelope.Results
+		}
+
+		objects = append(objects, page...)
+
+		if len(page) < importPageSize {
+			break
+		}
+	}
+
+	return objects, nil
+}
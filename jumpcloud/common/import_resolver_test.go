@@ -0,0 +1,103 @@
+package common
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// importTestClient serves a fixed list response for every request
+type importTestClient struct {
+	orgID    string
+	response string
+	requests []string
+}
+
+func (c *importTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	c.requests = append(c.requests, path)
+	return []byte(c.response), nil
+}
+
+func (c *importTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *importTestClient) GetApiKey() string { return "test" }
+
+func (c *importTestClient) GetOrgID() string { return c.orgID }
+
+func TestParseImportID(t *testing.T) {
+	fields := map[string]string{"username": "username", "email": "email"}
+
+	cases := []struct {
+		raw  string
+		want ImportID
+	}{
+		{"5f1b881dc9e9a9b7e8d6c5a4", ImportID{Value: "5f1b881dc9e9a9b7e8d6c5a4"}},
+		{"username:jdoe", ImportID{Field: "username", Value: "jdoe"}},
+		{"email:j@x.com", ImportID{Field: "email", Value: "j@x.com"}},
+		{"name:Engineering", ImportID{Value: "name:Engineering"}},
+		{"org/abc/5f1b881dc9e9a9b7e8d6c5a4", ImportID{OrgID: "abc", Value: "5f1b881dc9e9a9b7e8d6c5a4"}},
+		{"org/abc/username:jdoe", ImportID{OrgID: "abc", Field: "username", Value: "jdoe"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.raw, func(t *testing.T) {
+			if got := ParseImportID(tc.raw, fields); got != tc.want {
+				t.Errorf("Expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMatchImportCandidates(t *testing.T) {
+	objects := []map[string]interface{}{
+		{"id": "b", "name": "Engineering"},
+		{"_id": "a", "name": "Engineering"},
+		{"id": "c", "name": "engineering"},
+	}
+
+	if got := MatchImportCandidates(objects, "name", "Engineering"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", got)
+	}
+	if got := MatchImportCandidates(objects, "name", "Sales"); len(got) != 0 {
+		t.Errorf("Expected no matches, got %v", got)
+	}
+}
+
+func TestResolveImportID(t *testing.T) {
+	lookup := ImportLookup{Kind: "user group", ListPath: "/api/v2/usergroups", Fields: map[string]string{"name": "name"}}
+	ctx := context.Background()
+
+	c := &importTestClient{orgID: "org1", response: `[{"id":"g1","name":"Engineering"},{"id":"g2","name":"Sales"},{"id":"g3","name":"Sales"}]`}
+
+	id, err := ResolveImportID(ctx, c, lookup, "g9")
+	if err != nil || id != "g9" {
+		t.Errorf("Expected plain ID to pass through, got %q, %v", id, err)
+	}
+	if len(c.requests) != 0 {
+		t.Errorf("Expected no request for a plain ID, got %v", c.requests)
+	}
+
+	id, err = ResolveImportID(ctx, c, lookup, "org/org1/name:Engineering")
+	if err != nil || id != "g1" {
+		t.Errorf("Expected g1, got %q, %v", id, err)
+	}
+
+	if _, err := ResolveImportID(ctx, c, lookup, "name:Sales"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous error, got %v", err)
+	}
+	if _, err := ResolveImportID(ctx, c, lookup, "name:Marketing"); err == nil || !strings.Contains(err.Error(), "no user group found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if _, err := ResolveImportID(ctx, c, lookup, "org/other/g1"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected organization mismatch error, got %v", err)
+	}
+
+	v1 := &importTestClient{response: `{"results":[{"_id":"u1","username":"jdoe"}],"totalCount":1}`}
+	id, err = ResolveImportID(ctx, v1, ImportLookup{Kind: "user", ListPath: "/api/systemusers", Fields: map[string]string{"username": "username"}}, "username:jdoe")
+	if err != nil || id != "u1" {
+		t.Errorf("Expected u1, got %q, %v", id, err)
+	}
+}
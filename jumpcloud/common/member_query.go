@@ -0,0 +1,369 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// ExpandMemberQuery converts the Terraform schema representation of a member query to the API format
+func ExpandMemberQuery(input []interface{}, convertField func(string) string) (interface{}, error) {
+	if len(input) == 0 || input[0] == nil {
+		return nil, nil
+	}
+
+	queryMap := input[0].(map[string]interface{})
+	queryType := queryMap["query_type"].(string)
+
+	// Handle both TypeSet and TypeList for backward compatibility
+	var filters []interface{}
+	if filterSet, ok := queryMap["filter"].(*schema.Set); ok {
+		filters = filterSet.List()
+	} else if filterList, ok := queryMap["filter"].([]interface{}); ok {
+		filters = filterList
+	}
+
+	if len(filters) > 0 {
+		switch queryType {
+		case "FilterQuery":
+			// Use FilterQuery specific type
+			query := &UserGroupFilterQuery{
+				QueryType: queryType,
+				Filters:   make([]UserGroupFilter, 0, len(filters)),
+			}
+
+			for _, f := range filters {
+				filterMap := f.(map[string]interface{})
+				filter := UserGroupFilter{
+					Field:    filterMap["field"].(string),
+					Operator: filterMap["operator"].(string),
+					Value:    filterMap["value"].(string),
+				}
+				query.Filters = append(query.Filters, filter)
+			}
+
+			return query, nil
+		case "Search":
+			// Convert filters to searchFilters format for Search query
+			var searchFilters []string
+			for _, f := range filters {
+				filterMap := f.(map[string]interface{})
+				field := filterMap["field"].(string)
+				operator := filterMap["operator"].(string)
+				value := filterMap["value"].(string)
+
+				// Map the field to its API name, e.g. custom attributes
+				field = convertField(field)
+
+				// Convert operator format and handle special cases
+				if operator == "in" {
+					// For 'in' operator, split values and create separate filters with $eq
+					values := strings.Split(value, "|")
+					for _, val := range values {
+						val = strings.TrimSpace(val)
+						if val != "" {
+							searchFilter := fmt.Sprintf("%s:$eq:%s", field, val)
+							searchFilters = append(searchFilters, searchFilter)
+						}
+					}
+				} else {
+					var searchOp string
+					switch operator {
+					case "eq":
+						searchOp = "$eq"
+					case "ne":
+						searchOp = "$ne"
+					case "regex":
+						searchOp = "$regex"
+					default:
+						searchOp = "$eq" // default to equals
+					}
+
+					searchFilter := fmt.Sprintf("%s:%s:%s", field, searchOp, value)
+					searchFilters = append(searchFilters, searchFilter)
+				}
+			}
+
+			// Create SearchQuery specific type
+			if len(searchFilters) > 0 {
+				// Create searchFilters as JSON string (as expected by JumpCloud API)
+				searchFiltersObj := map[string]interface{}{
+					"filter": searchFilters,
+				}
+				searchFiltersJSON, _ := json.Marshal(searchFiltersObj)
+
+				query := &UserGroupSearchQuery{
+					QueryType:     queryType,
+					SearchFilters: string(searchFiltersJSON), // Send as string, not object
+				}
+
+				return query, nil
+			}
+		}
+	}
+
+	// Return basic query if no filters
+	return &UserGroupQuery{QueryType: queryType}, nil
+}
+
+// ExpandMemberQueryExemptions converts the Terraform schema representation of member query exemptions to the API format
+func ExpandMemberQueryExemptions(input []interface{}) ([]UserGroupExemption, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	exemptions := make([]UserGroupExemption, 0, len(input))
+	for _, item := range input {
+		exemptionMap := item.(map[string]interface{})
+		exemption := UserGroupExemption{
+			ID:   exemptionMap["id"].(string),
+			Type: exemptionMap["type"].(string),
+		}
+		exemptions = append(exemptions, exemption)
+	}
+
+	return exemptions, nil
+}
+
+// FlattenMemberQuery converts the API representation of a member query to the Terraform schema format
+func FlattenMemberQuery(query interface{}, convertField func(string) string) []interface{} {
+	if query == nil {
+		return []interface{}{}
+	}
+
+	result := make(map[string]interface{})
+	var filters []interface{}
+
+	switch q := query.(type) {
+	case *UserGroupFilterQuery:
+		result["query_type"] = q.QueryType
+		if len(q.Filters) > 0 {
+			// Sort filters by field name for consistent ordering
+			sortedFilters := make([]UserGroupFilter, len(q.Filters))
+			copy(sortedFilters, q.Filters)
+			sort.Slice(sortedFilters, func(i, j int) bool {
+				return sortedFilters[i].Field < sortedFilters[j].Field
+			})
+
+			filters = make([]interface{}, 0, len(sortedFilters))
+			for _, filter := range sortedFilters {
+				filterMap := make(map[string]interface{})
+				filterMap["field"] = filter.Field
+				filterMap["operator"] = filter.Operator
+				filterMap["value"] = filter.Value
+				filters = append(filters, filterMap)
+			}
+		}
+	case *UserGroupSearchQuery:
+		result["query_type"] = q.QueryType
+		if q.SearchFilters != "" {
+			filters = parseSearchFilters(q.SearchFilters, convertField)
+		}
+	case *UserGroupQuery:
+		result["query_type"] = q.QueryType
+		if q.QueryType == "FilterQuery" && len(q.Filters) > 0 {
+			filters = make([]interface{}, 0, len(q.Filters))
+			for _, filter := range q.Filters {
+				filterMap := make(map[string]interface{})
+				filterMap["field"] = filter.Field
+				filterMap["operator"] = filter.Operator
+				filterMap["value"] = filter.Value
+				filters = append(filters, filterMap)
+			}
+		} else if q.QueryType == "Search" && q.SearchFilters != nil {
+			filters = parseSearchFiltersFromObject(q.SearchFilters, convertField)
+		}
+	case map[string]interface{}:
+		// Handle the case when JSON is deserialized as generic map
+		if queryType, ok := q["queryType"].(string); ok {
+			result["query_type"] = queryType
+
+			switch queryType {
+			case "FilterQuery":
+				if filtersArray, ok := q["filters"].([]interface{}); ok {
+					// Create a temporary slice to sort filters
+					var tempFilters []map[string]interface{}
+					for _, f := range filtersArray {
+						if filterMap, ok := f.(map[string]interface{}); ok {
+							newFilter := make(map[string]interface{})
+							if field, ok := filterMap["field"].(string); ok {
+								newFilter["field"] = field
+							}
+							if operator, ok := filterMap["operator"].(string); ok {
+								newFilter["operator"] = operator
+							}
+							if value, ok := filterMap["value"].(string); ok {
+								newFilter["value"] = value
+							}
+							tempFilters = append(tempFilters, newFilter)
+						}
+					}
+
+					// Sort filters by field name for consistent ordering
+					sort.Slice(tempFilters, func(i, j int) bool {
+						return tempFilters[i]["field"].(string) < tempFilters[j]["field"].(string)
+					})
+
+					// Convert to interface{} slice
+					filters = make([]interface{}, 0, len(tempFilters))
+					for _, filter := range tempFilters {
+						filters = append(filters, filter)
+					}
+				}
+			case "Search":
+				if searchFilters, ok := q["searchFilters"].(string); ok {
+					filters = parseSearchFilters(searchFilters, convertField)
+				}
+			}
+		}
+	default:
+		return []interface{}{}
+	}
+
+	result["filter"] = filters
+	return []interface{}{result}
+}
+
+// parseSearchFilters converts searchFilters JSON string back to filter array format
+func parseSearchFilters(searchFilters string, convertField func(string) string) []interface{} {
+	var filters []interface{}
+
+	// Parse the JSON string to extract filters
+	// Example: {"filter":["company:$eq:Agilize","department:$eq:Administration","state:$eq:ACTIVATED","attributes[name=area].value:$eq:asd"]}
+
+	var searchFiltersObj map[string]interface{}
+	if err := json.Unmarshal([]byte(searchFilters), &searchFiltersObj); err != nil {
+		return filters
+	}
+
+	if filterArray, ok := searchFiltersObj["filter"].([]interface{}); ok {
+		// Group equality filters by field to handle multiple values (like jobTitle)
+		fieldGroups := make(map[string][]string)
+		var otherFilters []interface{}
+
+		for _, filterItem := range filterArray {
+			if filterStr, ok := filterItem.(string); ok {
+				parts := strings.Split(filterStr, ":")
+				if len(parts) >= 3 {
+					field := parts[0]
+					value := strings.Join(parts[2:], ":") // In case value contains colons
+
+					// Convert back from API format
+					field = convertField(field)
+
+					// Only equality filters are merged into an "in" filter, the others keep their operator
+					if parts[1] != "$eq" {
+						otherFilters = append(otherFilters, map[string]interface{}{
+							"field":    field,
+							"operator": searchFilterOperator(parts[1]),
+							"value":    value,
+						})
+						continue
+					}
+
+					// Group values by field
+					if _, exists := fieldGroups[field]; !exists {
+						fieldGroups[field] = []string{}
+					}
+					fieldGroups[field] = append(fieldGroups[field], value)
+				}
+			}
+		}
+
+		// Convert grouped filters back to Terraform format
+		// Sort fields to ensure consistent ordering
+		var sortedFields []string
+		for field := range fieldGroups {
+			sortedFields = append(sortedFields, field)
+		}
+		sort.Strings(sortedFields)
+
+		for _, field := range sortedFields {
+			values := fieldGroups[field]
+			filterMap := make(map[string]interface{})
+			filterMap["field"] = field
+
+			if len(values) > 1 {
+				// Multiple values = "in" operator
+				filterMap["operator"] = "in"
+				filterMap["value"] = strings.Join(values, "|")
+			} else {
+				// Single value = "eq" operator
+				filterMap["operator"] = "eq"
+				filterMap["value"] = values[0]
+			}
+
+			filters = append(filters, filterMap)
+		}
+
+		filters = append(filters, otherFilters...)
+	}
+
+	return filters
+}
+
+// searchFilterOperator converts a search filter operator such as $ne back to its Terraform name
+func searchFilterOperator(operator string) string {
+	return strings.TrimPrefix(operator, "$")
+}
+
+// parseSearchFiltersFromObject converts searchFilters object back to filter array format
+func parseSearchFiltersFromObject(searchFilters map[string]interface{}, convertField func(string) string) []interface{} {
+	var filters []interface{}
+
+	if filterArray, ok := searchFilters["filter"].([]interface{}); ok {
+		for _, filterItem := range filterArray {
+			if filterStr, ok := filterItem.(string); ok {
+				parts := strings.Split(filterStr, ":")
+				if len(parts) >= 3 {
+					field := parts[0]
+					operator := parts[1]
+					value := strings.Join(parts[2:], ":") // In case value contains colons
+
+					// Convert back from API format
+					field = convertField(field)
+
+					// Convert operator format
+					switch operator {
+					case "$eq":
+						operator = "eq"
+					case "$ne":
+						operator = "ne"
+					case "$in":
+						operator = "in"
+					case "$regex":
+						operator = "regex"
+					}
+
+					filterMap := make(map[string]interface{})
+					filterMap["field"] = field
+					filterMap["operator"] = operator
+					filterMap["value"] = value
+					filters = append(filters, filterMap)
+				}
+			}
+		}
+	}
+
+	return filters
+}
+
+// FlattenMemberQueryExemptions converts the API representation of member query exemptions to the Terraform schema format
+func FlattenMemberQueryExemptions(exemptions []UserGroupExemption) []interface{} {
+	if len(exemptions) == 0 {
+		return []interface{}{}
+	}
+
+	result := make([]interface{}, 0, len(exemptions))
+	for _, exemption := range exemptions {
+		exemptionMap := make(map[string]interface{})
+		exemptionMap["id"] = exemption.ID
+		exemptionMap["type"] = exemption.Type
+		result = append(result, exemptionMap)
+	}
+
+	return result
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchMemberQueryRoundTrip(t *testing.T) {
+	identity := func(field string) string { return field }
+	filters := []interface{}{
+		map[string]interface{}{"field": "os", "operator": "in", "value": "Mac OS X|Windows"},
+		map[string]interface{}{"field": "arch", "operator": "eq", "value": "arm64"},
+		map[string]interface{}{"field": "hostname", "operator": "ne", "value": "build-01"},
+		map[string]interface{}{"field": "hostname", "operator": "regex", "value": "^mac-"},
+	}
+	query, err := ExpandMemberQuery([]interface{}{map[string]interface{}{"query_type": "Search", "filter": filters}}, identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flattened := FlattenMemberQuery(query, identity)[0].(map[string]interface{})["filter"].([]interface{})
+	expected := []interface{}{
+		map[string]interface{}{"field": "arch", "operator": "eq", "value": "arm64"},
+		map[string]interface{}{"field": "os", "operator": "in", "value": "Mac OS X|Windows"},
+		map[string]interface{}{"field": "hostname", "operator": "ne", "value": "build-01"},
+		map[string]interface{}{"field": "hostname", "operator": "regex", "value": "^mac-"},
+	}
+	if !reflect.DeepEqual(flattened, expected) {
+		t.Errorf("expected %v, got %v", expected, flattened)
+	}
+}
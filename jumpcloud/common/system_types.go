@@ -5,6 +5,7 @@ type System struct {
 	ID                             string                 `json:"_id,omitempty"`
 	DisplayName                    string                 `json:"displayName"`
 	HostName                       string                 `json:"hostname,omitempty"`
+	SerialNumber                   string                 `json:"serialNumber,omitempty"`
 	OS                             string                 `json:"os,omitempty"`
 	SystemType                     string                 `json:"systemType,omitempty"` // linux, windows, mac, etc.
 	Version                        string                 `json:"version,omitempty"`
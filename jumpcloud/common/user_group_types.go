@@ -48,3 +48,18 @@ type UserGroup struct {
 	Updated                 string                 `json:"updated,omitempty"`
 	MemberCount             int                    `json:"memberCount,omitempty"`
 }
+
+// UserGroupSuggestionUser represents the user referenced by a membership suggestion
+type UserGroupSuggestionUser struct {
+	ID        string `json:"_id"`
+	Username  string `json:"username,omitempty"`
+	Email     string `json:"email,omitempty"`
+	FirstName string `json:"firstname,omitempty"`
+	LastName  string `json:"lastname,omitempty"`
+}
+
+// UserGroupMemberSuggestion represents a pending membership suggestion of a dynamic user group
+type UserGroupMemberSuggestion struct {
+	Op     string                  `json:"op"`
+	Object UserGroupSuggestionUser `json:"object"`
+}
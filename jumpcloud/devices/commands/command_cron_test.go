@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	valid := []string{"* * * * *", "*/15 9-17 * * mon-fri", "0 0 1,15 * *", "30 2 * jan,jul 0", "0 12 * * 7", "@daily", "5/10 * * * *"}
+	for _, expr := range valid {
+		if _, err := parseCronExpression(expr); err != nil {
+			t.Errorf("expected %q to parse, got %v", expr, err)
+		}
+	}
+
+	invalid := []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "* * * * funday", "@every 5m"}
+	for _, expr := range invalid {
+		if _, err := parseCronExpression(expr); err == nil {
+			t.Errorf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestCronScheduleNextRuns(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  []string
+	}{
+		{"every quarter hour", "*/15 * * * *", time.Date(2024, 6, 1, 10, 7, 30, 0, time.UTC),
+			[]string{"2024-06-01T10:15:00Z", "2024-06-01T10:30:00Z", "2024-06-01T10:45:00Z"}},
+		{"weekdays", "0 9 * * mon-fri", time.Date(2024, 6, 7, 9, 0, 0, 0, time.UTC),
+			[]string{"2024-06-10T09:00:00Z", "2024-06-11T09:00:00Z", "2024-06-12T09:00:00Z"}},
+		{"day of month or weekday", "0 0 13 * fri", time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-09-06T00:00:00Z", "2024-09-13T00:00:00Z", "2024-09-20T00:00:00Z"}},
+		{"leap day", "0 0 29 2 *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"2028-02-29T00:00:00Z", "2032-02-29T00:00:00Z", "2036-02-29T00:00:00Z"}},
+		{"skips times missing on dst change", "30 2 * * *", time.Date(2024, 3, 30, 12, 0, 0, 0, paris),
+			[]string{"2024-04-01T02:30:00+02:00", "2024-04-02T02:30:00+02:00", "2024-04-03T02:30:00+02:00"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cron, err := parseCronExpression(tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, run := range cron.nextRuns(tc.after, 3) {
+				got = append(got, run.Format(time.RFC3339))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+
+	never, _ := parseCronExpression("0 0 30 2 *")
+	if runs := never.nextRuns(time.Now(), 3); len(runs) != 0 {
+		t.Errorf("expected no runs for February 30, got %v", runs)
+	}
+}
+
+func TestCommandScheduleNextRuns(t *testing.T) {
+	now := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	runs, err := commandScheduleNextRuns("cron", "0 8 * * *", "America/New_York", now, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"2024-06-01T08:00:00-04:00", "2024-06-02T08:00:00-04:00"}; !reflect.DeepEqual(runs, want) {
+		t.Errorf("expected %v, got %v", want, runs)
+	}
+
+	runs, err = commandScheduleNextRuns("one-time", "2024-06-02T12:00:00Z", "Asia/Tokyo", now, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"2024-06-02T21:00:00+09:00"}; !reflect.DeepEqual(runs, want) {
+		t.Errorf("expected %v, got %v", want, runs)
+	}
+
+	invalid := []struct {
+		scheduleType, expr, timezone, field string
+	}{
+		{"cron", "0 8 * *", "UTC", "schedule"},
+		{"one-time", "tomorrow at noon", "UTC", "schedule"},
+		{"cron", "0 8 * * *", "Mars/Olympus_Mons", "timezone"},
+		{"cron", "0 8 * * *", "Local", "timezone"},
+	}
+	for _, tc := range invalid {
+		_, err := commandScheduleNextRuns(tc.scheduleType, tc.expr, tc.timezone, now, 5)
+		if err == nil || !strings.HasPrefix(err.Error(), tc.field+":") {
+			t.Errorf("expected a %s error for %+v, got %v", tc.field, tc, err)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// commandFile is a file uploaded to /api/files and written to the device before the command runs
+type commandFile struct {
+	Source      string
+	Content     string
+	Destination string
+	FileID      string
+}
+
+// commandFileUpload is the request body of /api/files. The content is base64 encoded so binary
+// files such as installers survive the JSON request
+type commandFileUpload struct {
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+	Content     string `json:"content"`
+}
+
+// commandFileSchema returns the schema of the file block of a command
+func commandFileSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Files uploaded to JumpCloud and written to the device before the command runs",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"source": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path of a local file to upload. Conflicts with content",
+				},
+				"content": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Content of the file to upload. Conflicts with source",
+				},
+				"destination": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Path the file is written to on the device",
+				},
+				"file_id": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "ID of the uploaded file",
+				},
+			},
+		},
+	}
+}
+
+// expandCommandFiles converts the file blocks of the configuration
+func expandCommandFiles(raw []interface{}) []commandFile {
+	files := make([]commandFile, 0, len(raw))
+	for _, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		files = append(files, commandFile{
+			Source:      m["source"].(string),
+			Content:     m["content"].(string),
+			Destination: m["destination"].(string),
+			FileID:      m["file_id"].(string),
+		})
+	}
+	return files
+}
+
+// flattenCommandFiles converts the files to their state representation
+func flattenCommandFiles(files []commandFile) []interface{} {
+	items := make([]interface{}, 0, len(files))
+	for _, f := range files {
+		items = append(items, map[string]interface{}{
+			"source":      f.Source,
+			"content":     f.Content,
+			"destination": f.Destination,
+			"file_id":     f.FileID,
+		})
+	}
+	return items
+}
+
+// setCommandFiles records the uploaded files and their hashes in the state
+func setCommandFiles(d *schema.ResourceData, files []commandFile) error {
+	if err := d.Set("file", flattenCommandFiles(files)); err != nil {
+		return fmt.Errorf("error setting file: %v", err)
+	}
+	hashes, err := commandFileHashes(files)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("file_hashes", hashes); err != nil {
+		return fmt.Errorf("error setting file_hashes: %v", err)
+	}
+	return nil
+}
+
+// read returns the bytes to upload, from the local file or the inline content
+func (f commandFile) read() ([]byte, error) {
+	if f.Source != "" {
+		data, err := os.ReadFile(f.Source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file %s: %v", f.Source, err)
+		}
+		return data, nil
+	}
+	return []byte(f.Content), nil
+}
+
+// validateCommandFiles checks that each file has exactly one of source or content and a unique destination
+func validateCommandFiles(files []commandFile) error {
+	destinations := make(map[string]bool)
+	for _, f := range files {
+		if (f.Source == "") == (f.Content == "") {
+			return fmt.Errorf("file %s: exactly one of source or content must be set", f.Destination)
+		}
+		if destinations[f.Destination] {
+			return fmt.Errorf("file %s: destination is used by more than one file", f.Destination)
+		}
+		destinations[f.Destination] = true
+	}
+	return nil
+}
+
+// commandFileHashes returns the SHA-256 of each file, keyed by destination
+func commandFileHashes(files []commandFile) (map[string]interface{}, error) {
+	hashes := make(map[string]interface{}, len(files))
+	for _, f := range files {
+		data, err := f.read()
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		hashes[f.Destination] = hex.EncodeToString(sum[:])
+	}
+	return hashes, nil
+}
+
+// customizeCommandFileDiff plans a new upload when the content of a file changed, including
+// local files whose path did not change
+func customizeCommandFileDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	raw := d.Get("file").([]interface{})
+	for i := range raw {
+		if !d.NewValueKnown(fmt.Sprintf("file.%d.source", i)) || !d.NewValueKnown(fmt.Sprintf("file.%d.content", i)) {
+			return d.SetNewComputed("file_hashes")
+		}
+	}
+
+	files := expandCommandFiles(raw)
+	if err := validateCommandFiles(files); err != nil {
+		return err
+	}
+
+	hashes, err := commandFileHashes(files)
+	if err != nil {
+		return err
+	}
+	if old, _ := d.GetChange("file_hashes"); !sameCommandFileHashes(old.(map[string]interface{}), hashes) {
+		return d.SetNew("file_hashes", hashes)
+	}
+	return nil
+}
+
+// sameCommandFileHashes compares two hash maps, treating a missing map as empty
+func sameCommandFileHashes(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// uploadCommandFile uploads a file to /api/files and returns its ID
+func uploadCommandFile(c common.ClientInterface, f commandFile) (string, error) {
+	data, err := f.read()
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(commandFileUpload{
+		Name:        path.Base(f.Destination),
+		Destination: f.Destination,
+		Content:     base64.StdEncoding.EncodeToString(data),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error serializing file %s: %v", f.Destination, err)
+	}
+
+	resp, err := c.DoRequest(http.MethodPost, "/api/files", body)
+	if err != nil {
+		return "", fmt.Errorf("error uploading file %s: %v", f.Destination, err)
+	}
+
+	var uploaded struct {
+		ID string `json:"_id"`
+	}
+	if err := json.Unmarshal(resp, &uploaded); err != nil {
+		return "", fmt.Errorf("error parsing file upload response: %v", err)
+	}
+	if uploaded.ID == "" {
+		return "", fmt.Errorf("error uploading file %s: no ID in the response", f.Destination)
+	}
+	return uploaded.ID, nil
+}
+
+// deleteCommandFiles removes uploaded files, ignoring the ones that are already gone
+func deleteCommandFiles(ctx context.Context, c common.ClientInterface, fileIDs []string) error {
+	for _, id := range fileIDs {
+		_, err := c.DoRequest(http.MethodDelete, fmt.Sprintf("/api/files/%s", id), nil)
+		if err != nil {
+			if common.IsNotFoundError(err) {
+				tflog.Warn(ctx, fmt.Sprintf("File %s was already deleted", id))
+				continue
+			}
+			return fmt.Errorf("error deleting file %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// syncCommandFiles uploads the new and changed files. Files whose destination and hash did not
+// change keep their ID. It returns the files with their IDs and the IDs of the replaced uploads
+func syncCommandFiles(c common.ClientInterface, oldFiles, newFiles []commandFile, oldHashes, newHashes map[string]interface{}) ([]commandFile, []string, error) {
+	previous := make(map[string]string, len(oldFiles))
+	for _, f := range oldFiles {
+		if f.FileID != "" {
+			previous[f.Destination] = f.FileID
+		}
+	}
+
+	synced := make([]commandFile, 0, len(newFiles))
+	kept := make(map[string]bool)
+	for _, f := range newFiles {
+		if id, ok := previous[f.Destination]; ok && oldHashes[f.Destination] == newHashes[f.Destination] {
+			f.FileID = id
+			kept[id] = true
+		} else {
+			id, err := uploadCommandFile(c, f)
+			if err != nil {
+				return synced, nil, err
+			}
+			f.FileID = id
+		}
+		synced = append(synced, f)
+	}
+
+	orphaned := make([]string, 0)
+	for _, f := range oldFiles {
+		if f.FileID != "" && !kept[f.FileID] {
+			orphaned = append(orphaned, f.FileID)
+		}
+	}
+	return synced, orphaned, nil
+}
+
+// commandFileIDs returns the IDs of the uploaded files
+func commandFileIDs(files []commandFile) []string {
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.FileID != "" {
+			ids = append(ids, f.FileID)
+		}
+	}
+	return ids
+}
+
+// mergeCommandFileIDs appends the uploaded file IDs to the files referenced by ID
+func mergeCommandFileIDs(fileIDs []string, files []commandFile) []string {
+	merged := append([]string{}, fileIDs...)
+	seen := make(map[string]bool, len(fileIDs))
+	for _, id := range fileIDs {
+		seen[id] = true
+	}
+	for _, id := range commandFileIDs(files) {
+		if !seen[id] {
+			merged = append(merged, id)
+		}
+	}
+	return merged
+}
+
+// unmanagedCommandFileIDs returns the file IDs of the command that are not uploaded by a file block
+func unmanagedCommandFileIDs(fileIDs []string, files []commandFile) []string {
+	managed := make(map[string]bool)
+	for _, id := range commandFileIDs(files) {
+		managed[id] = true
+	}
+	unmanaged := make([]string, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		if !managed[id] {
+			unmanaged = append(unmanaged, id)
+		}
+	}
+	return unmanaged
+}
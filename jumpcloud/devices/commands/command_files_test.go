@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// commandFilesTestClient records the uploaded and deleted files
+type commandFilesTestClient struct {
+	uploads    []commandFileUpload
+	deleted    []string
+	failUpdate bool
+}
+
+func (c *commandFilesTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	switch {
+	case method == "POST" && path == "/api/files":
+		var upload commandFileUpload
+		if err := json.Unmarshal(body, &upload); err != nil {
+			return nil, err
+		}
+		c.uploads = append(c.uploads, upload)
+		return json.Marshal(map[string]string{"_id": fmt.Sprintf("file%d", len(c.uploads))})
+	case method == "DELETE" && strings.HasPrefix(path, "/api/files/"):
+		c.deleted = append(c.deleted, strings.TrimPrefix(path, "/api/files/"))
+		return nil, nil
+	case method == "PUT" && strings.HasPrefix(path, "/api/commands/") && c.failUpdate:
+		return nil, fmt.Errorf("status code 500")
+	}
+	return nil, fmt.Errorf("unexpected request %s %s", method, path)
+}
+
+func (c *commandFilesTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *commandFilesTestClient) GetApiKey() string { return "test" }
+
+func (c *commandFilesTestClient) GetOrgID() string { return "" }
+
+func TestResourceCommandFileSchema(t *testing.T) {
+	s := ResourceCommand().Schema
+	if s["file"] == nil || s["file_hashes"] == nil {
+		t.Fatal("expected file and file_hashes in the schema")
+	}
+	if !s["file_hashes"].Computed {
+		t.Error("expected file_hashes to be computed")
+	}
+	if fileID := s["file"].Elem.(*schema.Resource).Schema["file_id"]; fileID == nil || !fileID.Computed {
+		t.Error("expected a computed file_id in the file block")
+	}
+}
+
+func TestValidateCommandFiles(t *testing.T) {
+	valid := []commandFile{
+		{Content: "a", Destination: "/tmp/a"},
+		{Source: "b.pkg", Destination: "/tmp/b.pkg"},
+	}
+	if err := validateCommandFiles(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := [][]commandFile{
+		{{Destination: "/tmp/a"}},
+		{{Source: "a", Content: "a", Destination: "/tmp/a"}},
+		{{Content: "a", Destination: "/tmp/a"}, {Content: "b", Destination: "/tmp/a"}},
+	}
+	for _, files := range invalid {
+		if err := validateCommandFiles(files); err == nil {
+			t.Errorf("expected an error for %v", files)
+		}
+	}
+}
+
+func TestCommandFileHashes(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "installer.pkg")
+	if err := os.WriteFile(source, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hashes, err := commandFileHashes([]commandFile{
+		{Source: source, Destination: "/tmp/installer.pkg"},
+		{Content: "hello", Destination: "/tmp/inline"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sum := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if hashes["/tmp/installer.pkg"] != sum || hashes["/tmp/inline"] != sum {
+		t.Errorf("unexpected hashes %v", hashes)
+	}
+
+	if _, err := commandFileHashes([]commandFile{{Source: source + ".missing", Destination: "/tmp/x"}}); err == nil {
+		t.Error("expected an error for a missing source file")
+	}
+}
+
+func TestSyncCommandFiles(t *testing.T) {
+	client := &commandFilesTestClient{}
+	oldFiles := []commandFile{
+		{Content: "same", Destination: "/tmp/same", FileID: "old1"},
+		{Content: "before", Destination: "/tmp/changed", FileID: "old2"},
+		{Content: "gone", Destination: "/tmp/removed", FileID: "old3"},
+	}
+	newFiles := []commandFile{
+		{Content: "same", Destination: "/tmp/same"},
+		{Content: "after", Destination: "/tmp/changed"},
+		{Content: "new", Destination: "/tmp/added"},
+	}
+	oldHashes, _ := commandFileHashes(oldFiles)
+	newHashes, _ := commandFileHashes(newFiles)
+
+	synced, orphaned, err := syncCommandFiles(client, oldFiles, newFiles, oldHashes, newHashes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := commandFileIDs(synced); !reflect.DeepEqual(ids, []string{"old1", "file1", "file2"}) {
+		t.Errorf("unexpected file IDs %v", ids)
+	}
+	if !reflect.DeepEqual(orphaned, []string{"old2", "old3"}) {
+		t.Errorf("expected the replaced and removed uploads to be orphaned, got %v", orphaned)
+	}
+	if len(client.uploads) != 2 {
+		t.Fatalf("expected 2 uploads, got %d", len(client.uploads))
+	}
+	upload := client.uploads[0]
+	if upload.Name != "changed" || upload.Destination != "/tmp/changed" || upload.Content != base64.StdEncoding.EncodeToString([]byte("after")) {
+		t.Errorf("unexpected upload %+v", upload)
+	}
+
+	if err := deleteCommandFiles(context.Background(), client, orphaned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(client.deleted, orphaned) {
+		t.Errorf("expected %v to be deleted, got %v", orphaned, client.deleted)
+	}
+}
+
+func TestCommandFileIDLists(t *testing.T) {
+	files := []commandFile{{Destination: "/tmp/a", FileID: "f1"}, {Destination: "/tmp/b", FileID: "f2"}}
+
+	if merged := mergeCommandFileIDs([]string{"x", "f1"}, files); !reflect.DeepEqual(merged, []string{"x", "f1", "f2"}) {
+		t.Errorf("unexpected merged IDs %v", merged)
+	}
+	if unmanaged := unmanagedCommandFileIDs([]string{"x", "f1", "f2"}, files); !reflect.DeepEqual(unmanaged, []string{"x"}) {
+		t.Errorf("unexpected unmanaged IDs %v", unmanaged)
+	}
+}
+
+func TestResourceCommandUpdateCleansUpUploads(t *testing.T) {
+	client := &commandFilesTestClient{failUpdate: true}
+	r := ResourceCommand()
+	hashes, _ := commandFileHashes([]commandFile{{Content: "same", Destination: "/tmp/same"}})
+
+	state := &terraform.InstanceState{ID: "cmd1", Attributes: map[string]string{
+		"id":                    "cmd1",
+		"name":                  "deploy",
+		"command":               "echo deploy",
+		"command_type":          "linux",
+		"file.#":                "1",
+		"file.0.content":        "same",
+		"file.0.destination":    "/tmp/same",
+		"file.0.file_id":        "old1",
+		"file_hashes.%":         "1",
+		"file_hashes./tmp/same": hashes["/tmp/same"].(string),
+	}}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":         "deploy",
+		"command":      "echo deploy",
+		"command_type": "linux",
+		"file": []interface{}{
+			map[string]interface{}{"content": "same", "destination": "/tmp/same"},
+			map[string]interface{}{"content": "new", "destination": "/tmp/added"},
+		},
+	})
+	diff, err := r.Diff(context.Background(), state, config, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, diags := r.Apply(context.Background(), state, diff, client); !diags.HasError() {
+		t.Fatal("expected the failed update to return an error")
+	}
+	if !reflect.DeepEqual(client.deleted, []string{"file1"}) {
+		t.Errorf("expected only the new upload to be deleted, got %v", client.deleted)
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// defaultCommandUser is the user of the command resource when none is configured
+const defaultCommandUser = "root"
+
+// commandPlatformRule describes the settings JumpCloud accepts for a command type
+type commandPlatformRule struct {
+	Shells       []string
+	DefaultShell string
+	Sudo         bool
+	// CustomUser is false on platforms where commands always run as the system account
+	CustomUser bool
+}
+
+// commandPlatformRules is the rule matrix applied at plan time, keyed by command type
+var commandPlatformRules = map[string]commandPlatformRule{
+	"linux":   {Shells: []string{"bash", "sh", "zsh"}, DefaultShell: "bash", Sudo: true, CustomUser: true},
+	"mac":     {Shells: []string{"bash", "sh", "zsh"}, DefaultShell: "zsh", Sudo: true, CustomUser: true},
+	"windows": {Shells: []string{"powershell"}, DefaultShell: "powershell", Sudo: false, CustomUser: false},
+}
+
+// commandLaunchTypesWithSchedule lists the launch types that need a schedule
+var commandLaunchTypesWithSchedule = map[string]bool{
+	"repeated": true,
+	"schedule": true,
+}
+
+// commandSettings holds the settings checked against the platform rules
+type commandSettings struct {
+	CommandType string
+	Shell       string
+	Sudo        bool
+	User        string
+	LaunchType  string
+	Trigger     string
+	Schedule    string
+}
+
+// validateCommandSettings checks the settings against the rule of their command type. The error is
+// scoped to the offending attribute so Terraform points at it in the configuration
+func validateCommandSettings(s commandSettings) error {
+	rule, ok := commandPlatformRules[s.CommandType]
+	if !ok {
+		return cty.GetAttrPath("command_type").NewErrorf("unsupported command type %q", s.CommandType)
+	}
+
+	if s.Shell != "" && !containsString(rule.Shells, s.Shell) {
+		return cty.GetAttrPath("shell").NewErrorf("%s commands do not support the %s shell, use one of: %s",
+			s.CommandType, s.Shell, strings.Join(rule.Shells, ", "))
+	}
+
+	if s.Sudo && !rule.Sudo {
+		return cty.GetAttrPath("sudo").NewErrorf("sudo is not supported on %s commands", s.CommandType)
+	}
+
+	if rule.CustomUser && s.User == "" {
+		return cty.GetAttrPath("user").NewErrorf("%s commands need a user to run as", s.CommandType)
+	}
+	if !rule.CustomUser && s.User != "" && s.User != defaultCommandUser {
+		return cty.GetAttrPath("user").NewErrorf("%s commands run as the system account, remove user %q", s.CommandType, s.User)
+	}
+
+	if s.LaunchType == "trigger" && (s.Trigger == "" || s.Trigger == "manual") {
+		return cty.GetAttrPath("trigger").NewErrorf("launch_type trigger needs the name of the trigger that runs the command")
+	}
+	if commandLaunchTypesWithSchedule[s.LaunchType] && s.Schedule == "" {
+		return cty.GetAttrPath("schedule").NewErrorf("launch_type %s needs a schedule", s.LaunchType)
+	}
+
+	return nil
+}
+
+// customizeCommandPlatformDiff validates the command against its platform and defaults the shell
+// of the platform when none is configured
+func customizeCommandPlatformDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"command_type", "shell", "sudo", "user", "launch_type", "trigger", "schedule"} {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	s := commandSettings{
+		CommandType: d.Get("command_type").(string),
+		Shell:       d.Get("shell").(string),
+		Sudo:        d.Get("sudo").(bool),
+		User:        d.Get("user").(string),
+		LaunchType:  d.Get("launch_type").(string),
+		Trigger:     d.Get("trigger").(string),
+		Schedule:    d.Get("schedule").(string),
+	}
+
+	// shell is computed, so an unset shell reads the previous one from the state
+	if !commandShellConfigured(d) {
+		if rule, ok := commandPlatformRules[s.CommandType]; ok {
+			s.Shell = rule.DefaultShell
+			if d.Get("shell").(string) != s.Shell {
+				if err := d.SetNew("shell", s.Shell); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return validateCommandSettings(s)
+}
+
+// commandShellConfigured reports whether shell is set in the configuration
+func commandShellConfigured(d *schema.ResourceDiff) bool {
+	raw := d.GetRawConfig()
+	if raw.IsNull() || !raw.IsKnown() {
+		return true
+	}
+	return !raw.GetAttr("shell").IsNull()
+}
+
+// containsString reports whether the slice contains the value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
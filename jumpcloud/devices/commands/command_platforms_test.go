@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestCommandPlatformMatrix(t *testing.T) {
+	// valid lists the shell and sudo combinations JumpCloud accepts for each command type
+	valid := map[string]map[string][]bool{
+		"linux":   {"bash": {false, true}, "sh": {false, true}, "zsh": {false, true}},
+		"mac":     {"bash": {false, true}, "sh": {false, true}, "zsh": {false, true}},
+		"windows": {"powershell": {false}},
+	}
+
+	for _, commandType := range []string{"linux", "mac", "windows"} {
+		for _, shell := range []string{"bash", "sh", "zsh", "powershell"} {
+			for _, sudo := range []bool{false, true} {
+				s := commandSettings{CommandType: commandType, Shell: shell, Sudo: sudo, User: defaultCommandUser, LaunchType: "manual", Trigger: "manual"}
+				want := false
+				for _, allowed := range valid[commandType][shell] {
+					if allowed == sudo {
+						want = true
+					}
+				}
+				if err := validateCommandSettings(s); (err == nil) != want {
+					t.Errorf("%s/%s/sudo=%v: expected valid=%v, got %v", commandType, shell, sudo, want, err)
+				}
+			}
+		}
+	}
+}
+
+func TestValidateCommandSettings(t *testing.T) {
+	base := commandSettings{CommandType: "linux", Shell: "bash", User: "root", LaunchType: "manual", Trigger: "manual"}
+
+	cases := []struct {
+		name      string
+		change    func(s *commandSettings)
+		attribute string
+	}{
+		{"defaults", func(s *commandSettings) {}, ""},
+		{"shell defaulted later", func(s *commandSettings) { s.Shell = "" }, ""},
+		{"bash on windows", func(s *commandSettings) { s.CommandType = "windows" }, "shell"},
+		{"sudo on windows", func(s *commandSettings) { s.CommandType, s.Shell, s.Sudo = "windows", "powershell", true }, "sudo"},
+		{"default user on windows", func(s *commandSettings) { s.CommandType, s.Shell = "windows", "powershell" }, ""},
+		{"custom user on windows", func(s *commandSettings) { s.CommandType, s.Shell, s.User = "windows", "powershell", "admin" }, "user"},
+		{"no user on mac", func(s *commandSettings) { s.CommandType, s.User = "mac", "" }, "user"},
+		{"trigger without name", func(s *commandSettings) { s.LaunchType = "trigger" }, "trigger"},
+		{"trigger with name", func(s *commandSettings) { s.LaunchType, s.Trigger = "trigger", "deploy_hook" }, ""},
+		{"repeated without schedule", func(s *commandSettings) { s.LaunchType = "repeated" }, "schedule"},
+		{"repeated with schedule", func(s *commandSettings) { s.LaunchType, s.Schedule = "repeated", "0 * * * *" }, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := base
+			tc.change(&s)
+			err := validateCommandSettings(s)
+			if tc.attribute == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			pathErr, ok := err.(cty.PathError)
+			if !ok {
+				t.Fatalf("expected an error on %s, got %v", tc.attribute, err)
+			}
+			if !pathErr.Path.Equals(cty.GetAttrPath(tc.attribute)) {
+				t.Errorf("expected the error on %s, got %#v", tc.attribute, pathErr.Path)
+			}
+		})
+	}
+}
+
+func TestCommandPlatformDefaultShells(t *testing.T) {
+	for commandType, rule := range commandPlatformRules {
+		if !containsString(rule.Shells, rule.DefaultShell) {
+			t.Errorf("default shell %s of %s is not an allowed shell", rule.DefaultShell, commandType)
+		}
+		if !CommandTypes()[commandType] {
+			t.Errorf("%s is not a command type", commandType)
+		}
+	}
+}
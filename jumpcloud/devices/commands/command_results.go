@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// CommandResult represents the result of a command execution on a system (/api/commandresults)
+type CommandResult struct {
+	ID                 string `json:"_id"`
+	V2ID               string `json:"id,omitempty"`
+	Command            string `json:"command,omitempty"`
+	Name               string `json:"name,omitempty"`
+	System             string `json:"system,omitempty"`
+	SystemID           string `json:"systemId"`
+	WorkflowID         string `json:"workflowId,omitempty"`
+	WorkflowInstanceID string `json:"workflowInstanceId,omitempty"`
+	RequestTime        string `json:"requestTime,omitempty"`
+	ResponseTime       string `json:"responseTime,omitempty"`
+	ExitCode           *int   `json:"exitCode,omitempty"`
+	Response           struct {
+		ID    string `json:"id,omitempty"`
+		Error string `json:"error,omitempty"`
+		Data  struct {
+			ExitCode *int   `json:"exitCode,omitempty"`
+			Output   string `json:"output,omitempty"`
+		} `json:"data"`
+	} `json:"response"`
+}
+
+// reportedExitCode returns the exit code reported by the system, preferring the detailed response,
+// and whether the system reported one
+func (r CommandResult) reportedExitCode() (int, bool) {
+	if r.Response.Data.ExitCode != nil {
+		return *r.Response.Data.ExitCode, true
+	}
+	if r.ExitCode != nil {
+		return *r.ExitCode, true
+	}
+	return 0, false
+}
+
+// finished reports whether the system reported an exit code or an error
+func (r CommandResult) finished() bool {
+	_, ok := r.reportedExitCode()
+	return ok || r.Response.Error != ""
+}
+
+// succeeded reports whether the command exited cleanly on the system. A result without exit code
+// has not finished and never counts as a success
+func (r CommandResult) succeeded() bool {
+	code, ok := r.reportedExitCode()
+	return ok && code == 0 && r.Response.Error == ""
+}
+
+// listCommandResults pages through the command results endpoint (/api/commandresults or
+// /api/v2/commandresults) with the given query
+func listCommandResults(ctx context.Context, c common.ClientInterface, basePath string, query url.Values) ([]CommandResult, error) {
+	var results []CommandResult
+	skip := 0
+	for {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("limit", "100")
+		q.Set("skip", strconv.Itoa(skip))
+
+		tflog.Debug(ctx, "Listing JumpCloud command results", map[string]interface{}{"path": basePath, "query": q.Encode()})
+		resp, err := c.DoRequest(http.MethodGet, basePath+"?"+q.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("error listing command results: %v", err)
+		}
+
+		page, err := parseCommandResultsPage(resp)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, page...)
+
+		if len(page) < 100 {
+			return results, nil
+		}
+		skip += len(page)
+	}
+}
+
+// parseCommandResultsPage reads a page in the v1 ({results, totalCount}) or v2 (array) format
+func parseCommandResultsPage(resp []byte) ([]CommandResult, error) {
+	var results []CommandResult
+	if strings.HasPrefix(strings.TrimSpace(string(resp)), "[") {
+		if err := json.Unmarshal(resp, &results); err != nil {
+			return nil, fmt.Errorf("error parsing command results response: %v", err)
+		}
+	} else {
+		var page struct {
+			TotalCount int             `json:"totalCount"`
+			Results    []CommandResult `json:"results"`
+		}
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing command results response: %v", err)
+		}
+		results = page.Results
+	}
+
+	// v2 results carry their ID in "id" instead of "_id"
+	for i := range results {
+		if results[i].ID == "" {
+			results[i].ID = results[i].V2ID
+		}
+	}
+	return results, nil
+}
+
+// getCommandResult fetches a single command result including the response output
+func getCommandResult(c common.ClientInterface, id string) (*CommandResult, error) {
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/commandresults/%s", id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading command result %s: %v", id, err)
+	}
+
+	var result CommandResult
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("error parsing command result response: %v", err)
+	}
+	return &result, nil
+}
+
+// commandResultElem returns the schema of a command result in the state
+func commandResultElem() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"result_id":     {Type: schema.TypeString, Computed: true},
+			"command_id":    {Type: schema.TypeString, Computed: true},
+			"name":          {Type: schema.TypeString, Computed: true},
+			"system_id":     {Type: schema.TypeString, Computed: true},
+			"system":        {Type: schema.TypeString, Computed: true},
+			"exit_code":     {Type: schema.TypeInt, Computed: true},
+			"output":        {Type: schema.TypeString, Computed: true},
+			"error":         {Type: schema.TypeString, Computed: true},
+			"succeeded":     {Type: schema.TypeBool, Computed: true},
+			"request_time":  {Type: schema.TypeString, Computed: true},
+			"response_time": {Type: schema.TypeString, Computed: true},
+		},
+	}
+}
+
+// commandID returns the ID of the command that produced the result
+func (r CommandResult) commandID() string {
+	if r.WorkflowID != "" {
+		return r.WorkflowID
+	}
+	return r.Command
+}
+
+// flattenCommandResult converts a command result to its state representation, the exit code is -1
+// until the system reports one
+func flattenCommandResult(result CommandResult) map[string]interface{} {
+	exitCode, ok := result.reportedExitCode()
+	if !ok {
+		exitCode = -1
+	}
+	return map[string]interface{}{
+		"result_id":     result.ID,
+		"command_id":    result.commandID(),
+		"name":          result.Name,
+		"system_id":     result.SystemID,
+		"system":        result.System,
+		"exit_code":     exitCode,
+		"output":        result.Response.Data.Output,
+		"error":         result.Response.Error,
+		"succeeded":     result.succeeded(),
+		"request_time":  result.RequestTime,
+		"response_time": result.ResponseTime,
+	}
+}
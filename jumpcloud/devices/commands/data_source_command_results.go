@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// commandResultsPaths maps the API version to the command results endpoint
+var commandResultsPaths = map[string]string{
+	"v1": "/api/commandresults",
+	"v2": "/api/v2/commandresults",
+}
+
+// commandResultsFilter holds the criteria results are matched against
+type commandResultsFilter struct {
+	CommandID string
+	SystemID  string
+	ExitCodes map[int]bool
+	Since     time.Time
+	Until     time.Time
+}
+
+// DataSourceCommandResults returns the data source that lists the execution history of commands
+func DataSourceCommandResults() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceCommandResultsRead,
+		Schema: map[string]*schema.Schema{
+			"command_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return results of this command",
+			},
+			"system_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return results of this system",
+			},
+			"exit_codes": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "Only return results that exited with one of these codes",
+			},
+			"since": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+				Description:  "Only return results requested at or after this time (RFC3339)",
+			},
+			"until": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+				Description:  "Only return results requested before this time (RFC3339)",
+			},
+			"latest_per_system": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Only return the most recent result of each system and command",
+			},
+			"include_output": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fetch the output of each result. This makes one request per result",
+			},
+			"api_version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "v1",
+				ValidateFunc: validation.StringInSlice([]string{"v1", "v2"}, false),
+				Description:  "Command results API to read from (v1 or v2)",
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching command results, most recent first",
+				Elem:        commandResultElem(),
+			},
+			"total": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of matching results",
+			},
+			"system_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the systems with at least one matching result",
+			},
+			"all_succeeded": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether every matching result succeeded. False when nothing matched",
+			},
+		},
+		Description: "Lists the execution history of JumpCloud commands, filtered by command, system, exit code and time window.",
+	}
+}
+
+func dataSourceCommandResultsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	filter := commandResultsFilter{
+		CommandID: d.Get("command_id").(string),
+		SystemID:  d.Get("system_id").(string),
+		ExitCodes: make(map[int]bool),
+	}
+	for _, code := range d.Get("exit_codes").(*schema.Set).List() {
+		filter.ExitCodes[code.(int)] = true
+	}
+	// The values were validated as RFC3339 in the schema
+	if v, ok := d.GetOk("since"); ok {
+		filter.Since, _ = time.Parse(time.RFC3339, v.(string))
+	}
+	if v, ok := d.GetOk("until"); ok {
+		filter.Until, _ = time.Parse(time.RFC3339, v.(string))
+	}
+
+	basePath := commandResultsPaths[d.Get("api_version").(string)]
+	results, err := listCommandResults(ctx, c, basePath, commandResultsQuery(filter))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	results = filterCommandResults(results, filter)
+	if d.Get("latest_per_system").(bool) {
+		results = latestCommandResults(results)
+	}
+
+	if d.Get("include_output").(bool) {
+		tflog.Debug(ctx, fmt.Sprintf("Fetching the output of %d command results", len(results)))
+		for i := range results {
+			detail, err := getCommandResult(c, results[i].ID)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			results[i].Response = detail.Response
+		}
+	}
+
+	items := make([]interface{}, 0, len(results))
+	systemIDs := make([]string, 0)
+	seen := make(map[string]bool)
+	allSucceeded := len(results) > 0
+	for _, result := range results {
+		items = append(items, flattenCommandResult(result))
+		if !seen[result.SystemID] {
+			seen[result.SystemID] = true
+			systemIDs = append(systemIDs, result.SystemID)
+		}
+		if !result.succeeded() {
+			allSucceeded = false
+		}
+	}
+	sort.Strings(systemIDs)
+
+	if err := d.Set("results", items); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting results: %v", err))
+	}
+	if err := d.Set("total", len(items)); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting total: %v", err))
+	}
+	if err := d.Set("system_ids", systemIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting system_ids: %v", err))
+	}
+	if err := d.Set("all_succeeded", allSucceeded); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting all_succeeded: %v", err))
+	}
+
+	d.SetId(fmt.Sprintf("command-results-%d", time.Now().Unix()))
+
+	return nil
+}
+
+// commandResultsQuery pushes the command and system filters down to the API. The other
+// criteria are only evaluated locally
+func commandResultsQuery(filter commandResultsFilter) url.Values {
+	query := url.Values{"sort": []string{"-requestTime"}}
+	if filter.CommandID != "" {
+		query.Add("filter", "workflowId:$eq:"+filter.CommandID)
+	}
+	if filter.SystemID != "" {
+		query.Add("filter", "systemId:$eq:"+filter.SystemID)
+	}
+	return query
+}
+
+// filterCommandResults keeps the results matching every criteria, sorted by request time, most recent first
+func filterCommandResults(results []CommandResult, filter commandResultsFilter) []CommandResult {
+	matched := make([]CommandResult, 0, len(results))
+	for _, result := range results {
+		if filter.CommandID != "" && result.commandID() != filter.CommandID {
+			continue
+		}
+		if filter.SystemID != "" && result.SystemID != filter.SystemID {
+			continue
+		}
+		if len(filter.ExitCodes) > 0 {
+			if code, ok := result.reportedExitCode(); !ok || !filter.ExitCodes[code] {
+				continue
+			}
+		}
+		if !filter.Since.IsZero() || !filter.Until.IsZero() {
+			requested, err := time.Parse(time.RFC3339, result.RequestTime)
+			if err != nil {
+				continue
+			}
+			if !filter.Since.IsZero() && requested.Before(filter.Since) {
+				continue
+			}
+			if !filter.Until.IsZero() && !requested.Before(filter.Until) {
+				continue
+			}
+		}
+		matched = append(matched, result)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].RequestTime > matched[j].RequestTime
+	})
+	return matched
+}
+
+// latestCommandResults keeps the first result of each system and command, the input being sorted most recent first
+func latestCommandResults(results []CommandResult) []CommandResult {
+	seen := make(map[string]bool)
+	latest := make([]CommandResult, 0, len(results))
+	for _, result := range results {
+		key := result.commandID() + "/" + result.SystemID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		latest = append(latest, result)
+	}
+	return latest
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDataSourceCommandResultsSchema(t *testing.T) {
+	s := DataSourceCommandResults().Schema
+	for _, key := range []string{"command_id", "system_id", "exit_codes", "since", "until", "include_output", "results", "all_succeeded"} {
+		if s[key] == nil {
+			t.Errorf("expected %s in the schema", key)
+		}
+	}
+}
+
+func TestCommandResultsQuery(t *testing.T) {
+	query := commandResultsQuery(commandResultsFilter{CommandID: "cmd", SystemID: "sys"})
+	filters := query["filter"]
+	if len(filters) != 2 || filters[0] != "workflowId:$eq:cmd" || filters[1] != "systemId:$eq:sys" {
+		t.Errorf("unexpected filters %v", filters)
+	}
+	if query.Get("sort") != "-requestTime" {
+		t.Errorf("expected results sorted by request time, got %s", query.Get("sort"))
+	}
+}
+
+func TestFilterCommandResults(t *testing.T) {
+	results := []CommandResult{
+		{ID: "r1", WorkflowID: "cmd", SystemID: "sys1", ExitCode: intPtr(0), RequestTime: "2024-06-01T10:00:00Z"},
+		{ID: "r2", WorkflowID: "cmd", SystemID: "sys1", ExitCode: intPtr(1), RequestTime: "2024-06-02T10:00:00Z"},
+		{ID: "r3", WorkflowID: "cmd", SystemID: "sys2", ExitCode: intPtr(0), RequestTime: "2024-06-03T10:00:00.500Z"},
+		{ID: "r4", Command: "other", SystemID: "sys2", ExitCode: intPtr(0), RequestTime: "2024-06-03T11:00:00Z"},
+		{ID: "r5", Command: "other", SystemID: "sys3", RequestTime: "2024-05-31T10:00:00Z"},
+	}
+
+	cases := []struct {
+		name   string
+		filter commandResultsFilter
+		want   []string
+	}{
+		{"command", commandResultsFilter{CommandID: "cmd"}, []string{"r3", "r2", "r1"}},
+		{"system", commandResultsFilter{SystemID: "sys2"}, []string{"r4", "r3"}},
+		{"exit code", commandResultsFilter{ExitCodes: map[int]bool{1: true}}, []string{"r2"}},
+		{"no exit code reported", commandResultsFilter{ExitCodes: map[int]bool{0: true}}, []string{"r4", "r3", "r1"}},
+		{"window", commandResultsFilter{
+			Since: time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2024, 6, 3, 11, 0, 0, 0, time.UTC),
+		}, []string{"r3", "r2"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := filterCommandResults(results, tc.filter)
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			for i, id := range tc.want {
+				if got[i].ID != id {
+					t.Errorf("expected %s at position %d, got %s", id, i, got[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestLatestCommandResults(t *testing.T) {
+	results := filterCommandResults([]CommandResult{
+		{ID: "r1", WorkflowID: "cmd", SystemID: "sys1", RequestTime: "2024-06-01T10:00:00Z"},
+		{ID: "r2", WorkflowID: "cmd", SystemID: "sys1", RequestTime: "2024-06-02T10:00:00Z"},
+		{ID: "r3", WorkflowID: "cmd", SystemID: "sys2", RequestTime: "2024-06-01T10:00:00Z"},
+	}, commandResultsFilter{})
+
+	latest := latestCommandResults(results)
+	if len(latest) != 2 || latest[0].ID != "r2" || latest[1].ID != "r3" {
+		t.Errorf("expected the latest result per system, got %v", latest)
+	}
+}
+
+func TestParseCommandResultsPage(t *testing.T) {
+	v1, err := parseCommandResultsPage([]byte(`{"totalCount": 1, "results": [{"_id": "r1", "systemId": "sys1"}]}`))
+	if err != nil || len(v1) != 1 || v1[0].ID != "r1" {
+		t.Errorf("unexpected v1 page %v: %v", v1, err)
+	}
+
+	v2, err := parseCommandResultsPage([]byte(`[{"id": "r2", "systemId": "sys1"}]`))
+	if err != nil || len(v2) != 1 || v2[0].ID != "r2" {
+		t.Errorf("unexpected v2 page %v: %v", v2, err)
+	}
+}
@@ -5,15 +5,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
 )
 
+// commandImportLookup resolves name:<name> import IDs
+var commandImportLookup = common.ImportLookup{
+	Kind:     "command",
+	ListPath: "/api/commands",
+	Fields:   map[string]string{"name": "name"},
+}
+
 // ResourceCommand returns the resource schema for JumpCloud commands
 func ResourceCommand() *schema.Resource {
 	return &schema.Resource{
@@ -45,8 +54,8 @@ func ResourceCommand() *schema.Resource {
 			"user": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				Default:     "root",
-				Description: "User that will execute the command (default: root)",
+				Default:     defaultCommandUser,
+				Description: "User that will execute the command (default: root). Ignored on windows, where commands run as the system account",
 			},
 			"schedule": {
 				Type:        schema.TypeString,
@@ -63,13 +72,14 @@ func ResourceCommand() *schema.Resource {
 				Type:         schema.TypeString,
 				Optional:     true,
 				Default:      "manual",
-				Description:  "Command execution trigger",
-				ValidateFunc: validation.StringInSlice([]string{"manual", "automatic", "deadline", "periodic"}, false),
+				Description:  "Command execution trigger, or the name of the trigger when launch_type is trigger",
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[A-Za-z0-9_-]+$`), "must only contain letters, digits, dashes and underscores"),
 			},
 			"shell": {
 				Type:         schema.TypeString,
 				Optional:     true,
-				Description:  "Shell used to execute the command",
+				Computed:     true,
+				Description:  "Shell used to execute the command. Defaults to bash on linux, zsh on mac and powershell on windows",
 				ValidateFunc: validation.StringInSlice([]string{"bash", "powershell", "sh", "zsh"}, false),
 			},
 			"sudo": {
@@ -83,7 +93,7 @@ func ResourceCommand() *schema.Resource {
 				Optional:     true,
 				Default:      "manual",
 				Description:  "Command launch type",
-				ValidateFunc: validation.StringInSlice([]string{"manual", "auto"}, false),
+				ValidateFunc: validation.StringInSlice([]string{"manual", "auto", "trigger", "repeated", "schedule"}, false),
 			},
 			"timeout": {
 				Type:         schema.TypeInt,
@@ -100,6 +110,15 @@ func ResourceCommand() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"file": commandFileSchema(),
+			"file_hashes": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "SHA-256 of each file uploaded by a file block, keyed by destination",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"environments": {
 				Type:        schema.TypeList,
 				Optional:    true,
@@ -127,8 +146,12 @@ func ResourceCommand() *schema.Resource {
 				Description: "Command creation date",
 			},
 		},
+		CustomizeDiff: customdiff.Sequence(
+			customizeCommandPlatformDiff,
+			customizeCommandFileDiff,
+		),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: common.ImportStateResolver(commandImportLookup),
 		},
 		Description: "Manages commands in JumpCloud. This resource allows creating, updating, and deleting commands for execution on systems.",
 		Timeouts: &schema.ResourceTimeout{
@@ -178,6 +201,16 @@ func resourceCommandCreate(ctx context.Context, d *schema.ResourceData, meta int
 		cmd.Attributes = common.ExpandAttributes(v.(map[string]interface{}))
 	}
 
+	// Upload the files written to the device before the command runs
+	files, _, err := syncCommandFiles(c, nil, expandCommandFiles(d.Get("file").([]interface{})), nil, d.Get("file_hashes").(map[string]interface{}))
+	if err != nil {
+		if cleanupErr := deleteCommandFiles(ctx, c, commandFileIDs(files)); cleanupErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Could not clean up uploaded files: %v", cleanupErr))
+		}
+		return diag.FromErr(err)
+	}
+	cmd.Files = mergeCommandFileIDs(cmd.Files, files)
+
 	// Convert to JSON
 	jsonData, err := json.Marshal(cmd)
 	if err != nil {
@@ -187,6 +220,9 @@ func resourceCommandCreate(ctx context.Context, d *schema.ResourceData, meta int
 	// Send request to create the command
 	resp, err := c.DoRequest(http.MethodPost, "/api/commands", jsonData)
 	if err != nil {
+		if cleanupErr := deleteCommandFiles(ctx, c, commandFileIDs(files)); cleanupErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Could not clean up uploaded files: %v", cleanupErr))
+		}
 		return diag.FromErr(fmt.Errorf("error creating command: %v", err))
 	}
 
@@ -199,6 +235,10 @@ func resourceCommandCreate(ctx context.Context, d *schema.ResourceData, meta int
 	// Set resource ID
 	d.SetId(createdCommand.ID)
 
+	if err := setCommandFiles(d, files); err != nil {
+		return diag.FromErr(err)
+	}
+
 	// Read the resource to update the state
 	return resourceCommandRead(ctx, d, meta)
 }
@@ -249,7 +289,7 @@ func resourceCommandRead(ctx context.Context, d *schema.ResourceData, meta inter
 		"launch_type":     command.LaunchType,
 		"timeout":         command.Timeout,
 		"description":     command.Description,
-		"files":           command.Files,
+		"files":           unmanagedCommandFileIDs(command.Files, expandCommandFiles(d.Get("file").([]interface{}))),
 		"environments":    command.Environments,
 	}
 
@@ -325,6 +365,22 @@ func resourceCommandUpdate(ctx context.Context, d *schema.ResourceData, meta int
 		cmd.Attributes = common.ExpandAttributes(v.(map[string]interface{}))
 	}
 
+	// Upload the new and changed files, the replaced uploads are deleted once the command no longer references them
+	oldFileBlocks, newFileBlocks := d.GetChange("file")
+	oldHashes, newHashes := d.GetChange("file_hashes")
+	oldFiles := expandCommandFiles(oldFileBlocks.([]interface{}))
+	files, orphaned, err := syncCommandFiles(c, oldFiles, expandCommandFiles(newFileBlocks.([]interface{})),
+		oldHashes.(map[string]interface{}), newHashes.(map[string]interface{}))
+	// The kept files stay referenced by the command, only this update's uploads are cleaned up on failure
+	uploaded := unmanagedCommandFileIDs(commandFileIDs(files), oldFiles)
+	if err != nil {
+		if cleanupErr := deleteCommandFiles(ctx, c, uploaded); cleanupErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Could not clean up uploaded files: %v", cleanupErr))
+		}
+		return diag.FromErr(err)
+	}
+	cmd.Files = mergeCommandFileIDs(cmd.Files, files)
+
 	// Convert to JSON
 	jsonData, err := json.Marshal(cmd)
 	if err != nil {
@@ -334,9 +390,19 @@ func resourceCommandUpdate(ctx context.Context, d *schema.ResourceData, meta int
 	// Send request to update the command
 	_, err = c.DoRequest(http.MethodPut, fmt.Sprintf("/api/commands/%s", commandID), jsonData)
 	if err != nil {
+		if cleanupErr := deleteCommandFiles(ctx, c, uploaded); cleanupErr != nil {
+			tflog.Warn(ctx, fmt.Sprintf("Could not clean up uploaded files: %v", cleanupErr))
+		}
 		return diag.FromErr(fmt.Errorf("error updating command: %v", err))
 	}
 
+	if err := setCommandFiles(d, files); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := deleteCommandFiles(ctx, c, orphaned); err != nil {
+		return diag.FromErr(err)
+	}
+
 	return resourceCommandRead(ctx, d, meta)
 }
 
@@ -364,6 +430,11 @@ func resourceCommandDelete(ctx context.Context, d *schema.ResourceData, meta int
 		return diag.FromErr(fmt.Errorf("error deleting command: %v", err))
 	}
 
+	// Remove the files uploaded for the command
+	if err := deleteCommandFiles(ctx, c, commandFileIDs(expandCommandFiles(d.Get("file").([]interface{})))); err != nil {
+		return diag.FromErr(err)
+	}
+
 	// Clear ID to mark resource as deleted
 	d.SetId("")
 
@@ -0,0 +1,355 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// commandRunClockSkew tolerates differences between the local clock and the request time
+// recorded by JumpCloud when matching results to a run
+const commandRunClockSkew = 30 * time.Second
+
+// ResourceCommandRun returns the resource that runs a command on systems and waits for the results
+func ResourceCommandRun() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCommandRunCreate,
+		ReadContext:   resourceCommandRunRead,
+		DeleteContext: resourceCommandRunDelete,
+		Schema: map[string]*schema.Schema{
+			"command_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the command to run",
+			},
+			"system_ids": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ForceNew:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"system_ids", "group_ids"},
+				Description:  "IDs of the systems to run the command on",
+			},
+			"group_ids": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ForceNew:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				AtLeastOneOf: []string{"system_ids", "group_ids"},
+				Description:  "IDs of the device groups whose members run the command",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values that run the command again when changed",
+			},
+			"success_threshold": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      100,
+				ValidateFunc: validation.FloatBetween(0, 100),
+				Description:  "Minimum percentage of targets that must succeed for the apply to pass. Targets without a result when the timeout hits count as failed",
+			},
+			"poll_interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      10,
+				ValidateFunc: validation.IntBetween(1, 300),
+				Description:  "Seconds between two polls of the command results",
+			},
+			"triggered_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time the command was triggered (RFC3339)",
+			},
+			"target_system_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the systems the command was run on, including the group members",
+			},
+			"pending_system_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the systems that had not reported when the timeout hit",
+			},
+			"succeeded_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of systems where the command succeeded",
+			},
+			"failed_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of systems where the command failed or did not report",
+			},
+			"results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Result of the command on each system that reported",
+				Elem:        commandResultElem(),
+			},
+		},
+		Description: "Runs a JumpCloud command on systems or device groups and waits until every target reports its result.",
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(10 * time.Minute),
+		},
+	}
+}
+
+func resourceCommandRunCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	commandID := d.Get("command_id").(string)
+	systemIDs := common.ExpandStringList(d.Get("system_ids").(*schema.Set).List())
+	groupIDs := common.ExpandStringList(d.Get("group_ids").(*schema.Set).List())
+
+	targets, err := resolveCommandRunTargets(c, systemIDs, groupIDs)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if len(targets) == 0 {
+		return diag.FromErr(fmt.Errorf("no systems to run command %s on, the device groups are empty", commandID))
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"_id": commandID, "systemIds": targets})
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing command run: %v", err))
+	}
+
+	triggeredAt := time.Now().UTC()
+	tflog.Info(ctx, fmt.Sprintf("Running command %s on %d systems", commandID, len(targets)))
+	resp, err := c.DoRequest(http.MethodPost, "/api/runCommand", body)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error running command %s: %v", commandID, err))
+	}
+
+	var run struct {
+		WorkflowInstanceID string `json:"workflowInstanceId"`
+	}
+	_ = json.Unmarshal(resp, &run)
+
+	// The ID is set before waiting so a failed run is tainted and runs again on the next apply
+	d.SetId(fmt.Sprintf("%s:%d", commandID, triggeredAt.Unix()))
+	if err := d.Set("triggered_at", triggeredAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting triggered_at: %v", err))
+	}
+	if err := d.Set("target_system_ids", targets); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting target_system_ids: %v", err))
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	interval := time.Duration(d.Get("poll_interval").(int)) * time.Second
+	collected := make(map[string]CommandResult)
+	query := url.Values{"filter": []string{"workflowId:$eq:" + commandID}, "sort": []string{"-requestTime"}}
+
+	for waiting := true; waiting; {
+		results, err := listCommandResults(waitCtx, c, "/api/commandresults", query)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		for systemID, result := range matchCommandRunResults(results, commandID, run.WorkflowInstanceID, targets, triggeredAt) {
+			if _, seen := collected[systemID]; seen {
+				continue
+			}
+			// The list endpoint omits the output, fetch it once per system
+			if detail, err := getCommandResult(c, result.ID); err == nil {
+				result.Response = detail.Response
+			} else {
+				tflog.Warn(ctx, fmt.Sprintf("Could not read the output of command result %s: %v", result.ID, err))
+			}
+			// A result without exit code is still running, poll it again
+			if !result.finished() {
+				continue
+			}
+			collected[systemID] = result
+		}
+
+		if len(collected) == len(targets) {
+			break
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("Command %s reported on %d of %d systems", commandID, len(collected), len(targets)))
+		select {
+		case <-waitCtx.Done():
+			tflog.Warn(ctx, fmt.Sprintf("Timed out waiting for command %s, %d systems did not report", commandID, len(targets)-len(collected)))
+			waiting = false
+		case <-time.After(interval):
+		}
+	}
+
+	succeeded, pending := summarizeCommandRun(collected, targets)
+	failed := len(targets) - succeeded
+
+	if err := d.Set("results", flattenCommandRunResults(collected, targets)); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting results: %v", err))
+	}
+	if err := d.Set("pending_system_ids", pending); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting pending_system_ids: %v", err))
+	}
+	if err := d.Set("succeeded_count", succeeded); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting succeeded_count: %v", err))
+	}
+	if err := d.Set("failed_count", failed); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting failed_count: %v", err))
+	}
+
+	threshold := d.Get("success_threshold").(float64)
+	if !commandRunMeetsThreshold(succeeded, len(targets), threshold) {
+		return diag.FromErr(fmt.Errorf("command %s succeeded on %d of %d systems (%d pending), below the success threshold of %g%%",
+			commandID, succeeded, len(targets), len(pending), threshold))
+	}
+
+	return nil
+}
+
+// resourceCommandRunRead keeps the recorded results: a run is a point in time and is never refreshed
+func resourceCommandRunRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+// resourceCommandRunDelete only removes the run from the state, the results stay in JumpCloud
+func resourceCommandRunDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// resolveCommandRunTargets returns the sorted union of the systems and the members of the device groups
+func resolveCommandRunTargets(c common.ClientInterface, systemIDs, groupIDs []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var targets []string
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			targets = append(targets, id)
+		}
+	}
+
+	for _, id := range systemIDs {
+		add(id)
+	}
+
+	for _, groupID := range groupIDs {
+		for skip := 0; ; {
+			resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/systemgroups/%s/membership?limit=100&skip=%d", groupID, skip), nil)
+			if err != nil {
+				return nil, fmt.Errorf("error reading members of device group %s: %v", groupID, err)
+			}
+
+			var members []struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(resp, &members); err != nil {
+				return nil, fmt.Errorf("error parsing device group membership response: %v", err)
+			}
+			for _, member := range members {
+				if member.Type == "" || member.Type == "system" {
+					add(member.ID)
+				}
+			}
+
+			if len(members) < 100 {
+				break
+			}
+			skip += len(members)
+		}
+	}
+
+	sort.Strings(targets)
+	return targets, nil
+}
+
+// matchCommandRunResults returns the latest result of the run for each target. Results are matched
+// on the workflow instance when JumpCloud returned one, otherwise on the command and request time
+func matchCommandRunResults(results []CommandResult, commandID, instanceID string, targets []string, since time.Time) map[string]CommandResult {
+	isTarget := make(map[string]bool, len(targets))
+	for _, id := range targets {
+		isTarget[id] = true
+	}
+
+	matched := make(map[string]CommandResult)
+	for _, result := range results {
+		if !isTarget[result.SystemID] {
+			continue
+		}
+		if instanceID != "" && result.WorkflowInstanceID != "" {
+			if result.WorkflowInstanceID != instanceID {
+				continue
+			}
+		} else {
+			if result.WorkflowID != commandID && result.Command != commandID {
+				continue
+			}
+			requested, err := time.Parse(time.RFC3339, result.RequestTime)
+			if err != nil || requested.Before(since.Add(-commandRunClockSkew)) {
+				continue
+			}
+		}
+
+		if current, ok := matched[result.SystemID]; !ok || result.RequestTime > current.RequestTime {
+			matched[result.SystemID] = result
+		}
+	}
+	return matched
+}
+
+// summarizeCommandRun counts the successful targets and lists the ones without a finished result
+func summarizeCommandRun(collected map[string]CommandResult, targets []string) (int, []string) {
+	succeeded := 0
+	pending := make([]string, 0)
+	for _, id := range targets {
+		result, ok := collected[id]
+		if !ok || !result.finished() {
+			pending = append(pending, id)
+			continue
+		}
+		if result.succeeded() {
+			succeeded++
+		}
+	}
+	return succeeded, pending
+}
+
+// commandRunMeetsThreshold reports whether the share of successful targets reaches the threshold
+func commandRunMeetsThreshold(succeeded, total int, threshold float64) bool {
+	if total == 0 {
+		return true
+	}
+	return float64(succeeded)*100 >= threshold*float64(total)
+}
+
+// flattenCommandRunResults converts the collected results in target order
+func flattenCommandRunResults(collected map[string]CommandResult, targets []string) []interface{} {
+	items := make([]interface{}, 0, len(collected))
+	for _, id := range targets {
+		result, ok := collected[id]
+		if !ok {
+			continue
+		}
+		items = append(items, flattenCommandResult(result))
+	}
+	return items
+}
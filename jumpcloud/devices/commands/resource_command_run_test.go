@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// commandRunTestClient answers device group membership and command result requests
+type commandRunTestClient struct {
+	members  map[string][]string
+	results  []CommandResult
+	requests []string
+}
+
+func (c *commandRunTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	c.requests = append(c.requests, path)
+	switch {
+	case strings.HasPrefix(path, "/api/v2/systemgroups/"):
+		groupID := strings.Split(strings.TrimPrefix(path, "/api/v2/systemgroups/"), "/")[0]
+		var skip int
+		fmt.Sscanf(path[strings.Index(path, "skip=")+5:], "%d", &skip)
+		members := make([]map[string]string, 0)
+		for i, id := range c.members[groupID] {
+			if i >= skip && i < skip+100 {
+				members = append(members, map[string]string{"id": id, "type": "system"})
+			}
+		}
+		return json.Marshal(members)
+	case strings.HasPrefix(path, "/api/commandresults?"):
+		var skip int
+		fmt.Sscanf(path[strings.Index(path, "skip=")+5:], "%d", &skip)
+		end := skip + 100
+		if end > len(c.results) {
+			end = len(c.results)
+		}
+		return json.Marshal(map[string]interface{}{"totalCount": len(c.results), "results": c.results[skip:end]})
+	}
+	return nil, fmt.Errorf("unexpected request %s %s", method, path)
+}
+
+func (c *commandRunTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *commandRunTestClient) GetApiKey() string { return "test" }
+
+func (c *commandRunTestClient) GetOrgID() string { return "" }
+
+func intPtr(v int) *int { return &v }
+
+func TestResourceCommandRunSchema(t *testing.T) {
+	r := ResourceCommandRun()
+	for _, key := range []string{"command_id", "system_ids", "group_ids", "triggers", "success_threshold", "results", "pending_system_ids"} {
+		if r.Schema[key] == nil {
+			t.Errorf("expected %s in the schema", key)
+		}
+	}
+	for _, key := range []string{"command_id", "system_ids", "group_ids", "triggers", "success_threshold", "poll_interval"} {
+		if !r.Schema[key].ForceNew {
+			t.Errorf("expected %s to force a new run", key)
+		}
+	}
+	if r.Timeouts == nil || r.Timeouts.Create == nil {
+		t.Error("expected a create timeout")
+	}
+}
+
+func TestResolveCommandRunTargets(t *testing.T) {
+	client := &commandRunTestClient{members: map[string][]string{
+		"group1": {"sys3", "sys1"},
+		"group2": {"sys2"},
+	}}
+
+	targets, err := resolveCommandRunTargets(client, []string{"sys1"}, []string{"group1", "group2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"sys1", "sys2", "sys3"}; !reflect.DeepEqual(targets, want) {
+		t.Errorf("expected %v, got %v", want, targets)
+	}
+
+	for i := 0; i < 250; i++ {
+		client.members["large"] = append(client.members["large"], fmt.Sprintf("member%03d", i))
+	}
+	targets, err = resolveCommandRunTargets(client, nil, []string{"large"})
+	if err != nil || len(targets) != 250 {
+		t.Errorf("expected the 250 members over 3 pages, got %d: %v", len(targets), err)
+	}
+}
+
+func TestMatchCommandRunResults(t *testing.T) {
+	since := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	results := []CommandResult{
+		{ID: "r1", SystemID: "sys1", WorkflowID: "cmd", RequestTime: "2024-06-01T12:00:05Z"},
+		{ID: "r0", SystemID: "sys1", WorkflowID: "cmd", RequestTime: "2024-05-31T08:00:00Z"},
+		{ID: "r2", SystemID: "sys2", WorkflowID: "cmd", RequestTime: "2024-06-01T11:59:50Z"},
+		{ID: "r3", SystemID: "sys3", WorkflowID: "cmd", RequestTime: "2024-06-01T12:00:10Z"},
+		{ID: "r4", SystemID: "sys2", WorkflowID: "other", RequestTime: "2024-06-01T12:00:10Z"},
+	}
+
+	matched := matchCommandRunResults(results, "cmd", "", []string{"sys1", "sys2"}, since)
+	if len(matched) != 2 {
+		t.Fatalf("expected 2 matched results, got %v", matched)
+	}
+	if matched["sys1"].ID != "r1" {
+		t.Errorf("expected the result of this run for sys1, got %s", matched["sys1"].ID)
+	}
+	if matched["sys2"].ID != "r2" {
+		t.Errorf("expected results within the clock skew to match, got %s", matched["sys2"].ID)
+	}
+
+	results = append(results, CommandResult{ID: "r5", SystemID: "sys1", WorkflowID: "cmd", WorkflowInstanceID: "old", RequestTime: "2024-06-01T12:00:20Z"})
+	results[0].WorkflowInstanceID = "run"
+	matched = matchCommandRunResults(results, "cmd", "run", []string{"sys1"}, since)
+	if matched["sys1"].ID != "r1" {
+		t.Errorf("expected results to match on the workflow instance, got %s", matched["sys1"].ID)
+	}
+}
+
+func TestSummarizeCommandRun(t *testing.T) {
+	collected := map[string]CommandResult{
+		"sys1": {SystemID: "sys1", ExitCode: intPtr(0)},
+		"sys2": {SystemID: "sys2", ExitCode: intPtr(1)},
+	}
+	failedDetail := CommandResult{SystemID: "sys3"}
+	failedDetail.Response.Data.ExitCode = intPtr(2)
+	collected["sys3"] = failedDetail
+	collected["sys5"] = CommandResult{SystemID: "sys5"}
+
+	succeeded, pending := summarizeCommandRun(collected, []string{"sys1", "sys2", "sys3", "sys4", "sys5"})
+	if succeeded != 1 {
+		t.Errorf("expected 1 successful system, got %d", succeeded)
+	}
+	if !reflect.DeepEqual(pending, []string{"sys4", "sys5"}) {
+		t.Errorf("expected sys4 and the result without exit code to be pending, got %v", pending)
+	}
+
+	items := flattenCommandRunResults(collected, []string{"sys1", "sys2", "sys3", "sys4", "sys5"})
+	if len(items) != 4 {
+		t.Fatalf("expected 4 flattened results, got %d", len(items))
+	}
+	if code := items[2].(map[string]interface{})["exit_code"]; code != 2 {
+		t.Errorf("expected the detailed exit code, got %v", code)
+	}
+	if item := items[3].(map[string]interface{}); item["exit_code"] != -1 || item["succeeded"] != false {
+		t.Errorf("expected a result without exit code to be unknown, got %v", item)
+	}
+}
+
+func TestCommandResultWithoutExitCode(t *testing.T) {
+	var result CommandResult
+	if result.finished() || result.succeeded() {
+		t.Error("expected a result without exit code to be pending")
+	}
+	result.Response.Error = "agent offline"
+	if !result.finished() || result.succeeded() {
+		t.Error("expected a result with an error to be finished and failed")
+	}
+}
+
+func TestCommandRunMeetsThreshold(t *testing.T) {
+	cases := []struct {
+		succeeded, total int
+		threshold        float64
+		want             bool
+	}{
+		{3, 3, 100, true},
+		{2, 3, 100, false},
+		{2, 3, 66, true},
+		{9, 10, 90, true},
+		{0, 4, 0, true},
+		{0, 0, 100, true},
+	}
+	for _, tc := range cases {
+		if got := commandRunMeetsThreshold(tc.succeeded, tc.total, tc.threshold); got != tc.want {
+			t.Errorf("commandRunMeetsThreshold(%d, %d, %g) = %v, want %v", tc.succeeded, tc.total, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+func TestListCommandResultsPaginates(t *testing.T) {
+	client := &commandRunTestClient{}
+	for i := 0; i < 130; i++ {
+		client.results = append(client.results, CommandResult{ID: fmt.Sprintf("r%d", i), SystemID: "sys"})
+	}
+
+	results, err := listCommandResults(context.Background(), client, "/api/commandresults", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 130 || len(client.requests) != 2 {
+		t.Errorf("expected 130 results in 2 requests, got %d in %d", len(results), len(client.requests))
+	}
+}
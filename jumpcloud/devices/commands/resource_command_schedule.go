@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezones are validated the same way whatever the tz database of the host
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -62,7 +65,20 @@ func ResourceCommandSchedule() *schema.Resource {
 				Type:        schema.TypeString,
 				Optional:    true,
 				Default:     "UTC",
-				Description: "Timezone for the schedule (e.g., America/New_York)",
+				Description: "IANA timezone for the schedule (e.g., America/New_York)",
+			},
+			"next_runs_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntBetween(1, 50),
+				Description:  "Number of upcoming executions listed in next_runs",
+			},
+			"next_runs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Next execution times of the schedule in its timezone (RFC3339)",
 			},
 			"target_systems": {
 				Type:        schema.TypeSet,
@@ -93,6 +109,7 @@ func ResourceCommandSchedule() *schema.Resource {
 			},
 		},
 
+		CustomizeDiff: customizeCommandScheduleDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -267,9 +284,99 @@ func resourceCommandScheduleRead(ctx context.Context, d *schema.ResourceData, me
 		}
 	}
 
+	// Refresh the upcoming executions, a schedule that no longer parses keeps the previous list
+	if runs, err := commandScheduleNextRuns(schedule.ScheduleType, schedule.Schedule, schedule.Timezone, time.Now(), d.Get("next_runs_count").(int)); err == nil {
+		if err := d.Set("next_runs", runs); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting next_runs: %v", err))
+		}
+	} else {
+		tflog.Warn(ctx, fmt.Sprintf("Could not compute the next runs of command schedule %s: %v", id, err))
+	}
+
 	return diags
 }
 
+// customizeCommandScheduleDiff validates the schedule against its type and timezone at plan time and
+// lists the upcoming executions. Past one-time schedules are only rejected when the schedule changes,
+// so a schedule that already ran does not break later plans
+func customizeCommandScheduleDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"schedule", "schedule_type", "timezone", "next_runs_count"} {
+		if !d.NewValueKnown(key) {
+			return d.SetNewComputed("next_runs")
+		}
+	}
+
+	scheduleType := d.Get("schedule_type").(string)
+	expr := d.Get("schedule").(string)
+	timezone := d.Get("timezone").(string)
+	now := time.Now()
+
+	changed := d.Id() == "" || d.HasChanges("schedule", "schedule_type", "timezone", "next_runs_count")
+	if changed && scheduleType == "one-time" {
+		if at, err := time.Parse(time.RFC3339, strings.TrimSpace(expr)); err == nil && !at.After(now) {
+			return fmt.Errorf("schedule: one-time schedule %s is in the past", expr)
+		}
+	}
+
+	runs, err := commandScheduleNextRuns(scheduleType, expr, timezone, now, d.Get("next_runs_count").(int))
+	if err != nil {
+		return err
+	}
+	if changed {
+		return d.SetNew("next_runs", runs)
+	}
+	return nil
+}
+
+// loadScheduleLocation resolves an IANA timezone. An empty timezone means UTC
+func loadScheduleLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	if timezone == "Local" {
+		return nil, fmt.Errorf("timezone: %q depends on the host, use an IANA timezone such as Europe/Paris", timezone)
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone: %q is not a valid IANA timezone", timezone)
+	}
+	return loc, nil
+}
+
+// commandScheduleNextRuns validates the schedule and returns up to count executions after now, formatted in the timezone
+func commandScheduleNextRuns(scheduleType, expr, timezone string, now time.Time, count int) ([]string, error) {
+	loc, err := loadScheduleLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []time.Time
+	switch scheduleType {
+	case "cron":
+		cron, err := parseCronExpression(expr)
+		if err != nil {
+			return nil, fmt.Errorf("schedule: %v", err)
+		}
+		runs = cron.nextRuns(now.In(loc), count)
+	case "one-time":
+		at, err := time.Parse(time.RFC3339, strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("schedule: one-time schedules must be an RFC3339 timestamp such as 2030-01-02T15:04:05Z, got %q", expr)
+		}
+		if at.After(now) {
+			runs = append(runs, at)
+		}
+	default:
+		return nil, fmt.Errorf("schedule_type: unsupported schedule type %q", scheduleType)
+	}
+
+	formatted := make([]string, 0, len(runs))
+	for _, run := range runs {
+		formatted = append(formatted, run.In(loc).Format(time.RFC3339))
+	}
+	return formatted, nil
+}
+
 // resourceCommandScheduleUpdate updates an existing command schedule
 func resourceCommandScheduleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	// Get client
@@ -0,0 +1,240 @@
+package mdm
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// AppleMDM represents the Apple MDM server of a JumpCloud organization, which holds the APNs push
+// certificate and the Automated Device Enrollment (ADE) token
+type AppleMDM struct {
+	ID                      string `json:"id"`
+	Name                    string `json:"name,omitempty"`
+	APNSCertExpiry          string `json:"apnsCertExpiry,omitempty"`
+	APNSPushTopic           string `json:"apnsPushTopic,omitempty"`
+	AppleCertCreatorAppleID string `json:"appleCertCreatorAppleID,omitempty"`
+	AppleCertSerialNumber   string `json:"appleCertSerialNumber,omitempty"`
+	DEPAccessTokenExpiry    string `json:"depAccessTokenExpiry,omitempty"`
+	DEPServerTokenState     string `json:"depServerTokenState,omitempty"`
+}
+
+// AppleMDMUpdate holds the files uploaded to the Apple MDM server
+type AppleMDMUpdate struct {
+	AppleSignedCert         string `json:"appleSignedCert,omitempty"`
+	AppleCertCreatorAppleID string `json:"appleCertCreatorAppleID,omitempty"`
+	EncryptedDEPServerToken string `json:"encryptedDepServerToken,omitempty"`
+}
+
+// errAppleMDMNotFound is returned when the Apple MDM server does not exist anymore
+var errAppleMDMNotFound = errors.New("Apple MDM server not found")
+
+// defaultExpiryWarningDays is how long before expiry the plan starts warning about a renewal
+const defaultExpiryWarningDays = 30
+
+// appleMDMIDSchema returns the schema of the Apple MDM server the resource manages
+func appleMDMIDSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		ForceNew:    true,
+		Description: "ID of the Apple MDM server, defaults to the only Apple MDM server of the organization",
+	}
+}
+
+// expiryWarningDaysSchema returns the schema of the renewal reminder threshold
+func expiryWarningDaysSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		Default:      defaultExpiryWarningDays,
+		ValidateFunc: validation.IntBetween(0, 365),
+		Description:  "Number of days before expiry from which every plan warns about the renewal, 0 disables the warning",
+	}
+}
+
+// listAppleMDMs returns the Apple MDM servers of the organization
+func listAppleMDMs(c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}) ([]AppleMDM, error) {
+	resp, err := c.DoRequest(http.MethodGet, "/api/v2/applemdms", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var servers []AppleMDM
+	if err := json.Unmarshal(resp, &servers); err != nil {
+		return nil, fmt.Errorf("error deserializing Apple MDM servers: %v", err)
+	}
+	return servers, nil
+}
+
+// resolveAppleMDMID returns the configured server ID, or the ID of the only server of the organization
+func resolveAppleMDMID(c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}, id string) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+
+	servers, err := listAppleMDMs(c)
+	if err != nil {
+		return "", err
+	}
+	switch len(servers) {
+	case 0:
+		return "", errors.New("the organization has no Apple MDM server")
+	case 1:
+		return servers[0].ID, nil
+	}
+	return "", fmt.Errorf("the organization has %d Apple MDM servers, set apple_mdm_id", len(servers))
+}
+
+// getAppleMDM returns the Apple MDM server with the given ID, the API only lists them
+func getAppleMDM(c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}, id string) (*AppleMDM, error) {
+	servers, err := listAppleMDMs(c)
+	if err != nil {
+		return nil, err
+	}
+	for i := range servers {
+		if servers[i].ID == id {
+			return &servers[i], nil
+		}
+	}
+	return nil, errAppleMDMNotFound
+}
+
+// updateAppleMDM uploads files to the Apple MDM server
+func updateAppleMDM(c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}, id string, update AppleMDMUpdate) error {
+	body, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("error serializing Apple MDM update: %v", err)
+	}
+	_, err = c.DoRequest(http.MethodPut, fmt.Sprintf("/api/v2/applemdms/%s", id), body)
+	return err
+}
+
+// getAppleMDMFile downloads a text file of the Apple MDM server, returned either as a JSON string or
+// as is
+func getAppleMDMFile(c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}, id, file string) (string, error) {
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/applemdms/%s/%s", id, file), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var s string
+	if err := json.Unmarshal(resp, &s); err == nil {
+		return s, nil
+	}
+	return string(resp), nil
+}
+
+// expiryWarning returns a warning when the expiry date is within the given number of days. The
+// resources return it from Read, which runs on every plan, so the renewal stays visible until done
+func expiryWarning(what, expiresAt string, days int, now time.Time) diag.Diagnostics {
+	if expiresAt == "" || days == 0 {
+		return nil
+	}
+	expiry, err := time.Parse(time.RFC3339, expiresAt)
+	if err != nil {
+		return nil
+	}
+
+	remaining := expiry.Sub(now)
+	if remaining > time.Duration(days)*24*time.Hour {
+		return nil
+	}
+	if remaining <= 0 {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("The %s has expired", what),
+			Detail:   fmt.Sprintf("The %s expired on %s. Renew it to restore MDM communication with the devices.", what, expiry.Format(time.RFC3339)),
+		}}
+	}
+	days = int(math.Ceil(remaining.Hours() / 24))
+	unit := "days"
+	if days == 1 {
+		unit = "day"
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("The %s expires in %d %s", what, days, unit),
+		Detail:   fmt.Sprintf("The %s expires on %s. Renew it before this date to keep managing the devices.", what, expiry.Format(time.RFC3339)),
+	}}
+}
+
+// parsePushCertificate decodes the PEM certificate issued by the Apple Push Certificates Portal
+func parsePushCertificate(certificate string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("certificate must be a PEM encoded certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// validatePushCertificate rejects certificates that cannot be parsed
+func validatePushCertificate(i interface{}, path cty.Path) diag.Diagnostics {
+	if _, err := parsePushCertificate(i.(string)); err != nil {
+		return diag.Diagnostics{{Severity: diag.Error, Summary: "Invalid push certificate", Detail: err.Error(), AttributePath: path}}
+	}
+	return nil
+}
+
+// checkPushCertificateExpiry rejects a certificate that has already expired
+func checkPushCertificateExpiry(certificate string, now time.Time) error {
+	cert, err := parsePushCertificate(certificate)
+	if err != nil {
+		return err
+	}
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("the certificate expired on %s, renew it on the Apple Push Certificates Portal", cert.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// customizePushCertificateDiff rejects an expired certificate when it is uploaded. A certificate that
+// expires once applied keeps planning, Read warns about the renewal instead
+func customizePushCertificateDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.HasChange("certificate") || !d.NewValueKnown("certificate") {
+		return nil
+	}
+	certificate := d.Get("certificate").(string)
+	if certificate == "" {
+		return nil
+	}
+	if err := checkPushCertificateExpiry(certificate, time.Now()); err != nil {
+		return cty.GetAttrPath("certificate").NewErrorf("%v", err)
+	}
+	return nil
+}
+
+// pushCertificateTopic returns the APNs topic of a push certificate, stored in its subject UID
+func pushCertificateTopic(cert *x509.Certificate) string {
+	for _, name := range cert.Subject.Names {
+		if name.Type.Equal(asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}) {
+			if uid, ok := name.Value.(string); ok {
+				return uid
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package mdm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// appleMDMTestClient lists the configured Apple MDM servers
+type appleMDMTestClient struct {
+	servers []AppleMDM
+}
+
+func (c *appleMDMTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	if method != "GET" || path != "/api/v2/applemdms" {
+		return nil, fmt.Errorf("unexpected request %s %s", method, path)
+	}
+	return json.Marshal(c.servers)
+}
+
+// testPushCertificate returns a self-signed PEM certificate with the given APNs topic and validity
+func testPushCertificate(t *testing.T, topic string, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName: "APSP:" + topic,
+			ExtraNames: []pkix.AttributeTypeAndValue{{Type: asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}, Value: topic}},
+		},
+		NotBefore: notAfter.AddDate(-1, 0, 0),
+		NotAfter:  notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestResolveAppleMDMID(t *testing.T) {
+	client := &appleMDMTestClient{}
+	if _, err := resolveAppleMDMID(client, ""); err == nil {
+		t.Error("expected an error without Apple MDM server")
+	}
+
+	client.servers = []AppleMDM{{ID: "first"}}
+	if id, err := resolveAppleMDMID(client, ""); err != nil || id != "first" {
+		t.Errorf("expected the only server, got %q: %v", id, err)
+	}
+
+	client.servers = append(client.servers, AppleMDM{ID: "second"})
+	if _, err := resolveAppleMDMID(client, ""); err == nil || !strings.Contains(err.Error(), "set apple_mdm_id") {
+		t.Errorf("expected an error asking for apple_mdm_id, got %v", err)
+	}
+	if id, err := resolveAppleMDMID(client, "second"); err != nil || id != "second" {
+		t.Errorf("expected the configured server, got %q: %v", id, err)
+	}
+
+	if _, err := getAppleMDM(client, "third"); err != errAppleMDMNotFound {
+		t.Errorf("expected errAppleMDMNotFound, got %v", err)
+	}
+}
+
+func TestExpiryWarning(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		expiresAt string
+		days      int
+		summary   string
+	}{
+		{"far from expiry", "2024-09-01T00:00:00Z", 30, ""},
+		{"within the threshold", "2024-06-20T12:00:00Z", 30, "expires in 19 days"},
+		{"expires today", "2024-06-01T18:00:00Z", 30, "expires in 1 day"},
+		{"expired", "2024-05-01T00:00:00Z", 30, "has expired"},
+		{"warning disabled", "2024-06-02T00:00:00Z", 0, ""},
+		{"no certificate yet", "", 30, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := expiryWarning("APNs push certificate", tc.expiresAt, tc.days, now)
+			if tc.summary == "" {
+				if len(diags) != 0 {
+					t.Errorf("expected no warning, got %v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Severity != diag.Warning || !strings.Contains(diags[0].Summary, tc.summary) {
+				t.Errorf("expected a warning containing %q, got %v", tc.summary, diags)
+			}
+		})
+	}
+}
+
+func TestValidatePushCertificate(t *testing.T) {
+	path := cty.GetAttrPath("certificate")
+
+	valid := testPushCertificate(t, "com.apple.mgmt.External.test", time.Now().AddDate(1, 0, 0))
+	if diags := validatePushCertificate(valid, path); diags.HasError() {
+		t.Errorf("unexpected error: %v", diags)
+	}
+	cert, _ := parsePushCertificate(valid)
+	if topic := pushCertificateTopic(cert); topic != "com.apple.mgmt.External.test" {
+		t.Errorf("expected the topic of the certificate, got %q", topic)
+	}
+
+	expired := testPushCertificate(t, "com.apple.mgmt.External.test", time.Now().AddDate(0, 0, -1))
+	if diags := validatePushCertificate(expired, path); diags.HasError() {
+		t.Errorf("expected validation to only parse the certificate, got %v", diags)
+	}
+	if err := checkPushCertificateExpiry(expired, time.Now()); err == nil || !strings.Contains(err.Error(), "expired on") {
+		t.Errorf("expected an expired certificate error, got %v", err)
+	}
+
+	if diags := validatePushCertificate("not a certificate", path); !diags.HasError() {
+		t.Error("expected an error on an invalid certificate")
+	}
+}
+
+func TestPushCertificateExpiredUnchanged(t *testing.T) {
+	expiry := time.Now().AddDate(0, 0, -1)
+	expired := testPushCertificate(t, "com.apple.mgmt.External.test", expiry)
+	r := ResourcePushCertificate()
+
+	// Uploading an expired certificate fails at plan time
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"apple_id": "admin@example.com", "certificate": expired})
+	if _, err := r.Diff(context.Background(), nil, config, nil); err == nil || !strings.Contains(err.Error(), "expired on") {
+		t.Errorf("expected an expired certificate error on upload, got %v", err)
+	}
+
+	// Once applied, the same certificate keeps planning
+	state := &terraform.InstanceState{ID: "mdm1", Attributes: map[string]string{
+		"id":                  "mdm1",
+		"apple_mdm_id":        "mdm1",
+		"apple_id":            "admin@example.com",
+		"certificate":         expired,
+		"expiry_warning_days": "30",
+		"csr":                 "csr",
+	}}
+	if _, err := r.Diff(context.Background(), state, config, nil); err != nil {
+		t.Errorf("unexpected error on an unchanged certificate: %v", err)
+	}
+
+	// and every refresh warns about the renewal
+	client := &appleMDMTestClient{servers: []AppleMDM{{ID: "mdm1", APNSCertExpiry: expiry.Format(time.RFC3339)}}}
+	d := r.Data(state)
+	diags := resourceMDMPushCertificateRead(context.Background(), d, client)
+	if diags.HasError() || len(diags) != 1 || diags[0].Severity != diag.Warning || diags[0].Summary != "The APNs push certificate has expired" {
+		t.Errorf("expected an expiry warning, got %v", diags)
+	}
+}
+
+func TestPushCertificateCreateKeepsTopic(t *testing.T) {
+	client := &appleMDMTestClient{servers: []AppleMDM{{ID: "mdm1", APNSPushTopic: "com.apple.mgmt.External.test"}}}
+	d := schema.TestResourceDataRaw(t, ResourcePushCertificate().Schema, map[string]interface{}{
+		"apple_id":    "admin@example.com",
+		"certificate": testPushCertificate(t, "com.apple.mgmt.External.other", time.Now().AddDate(1, 0, 0)),
+	})
+
+	// The server already has a certificate, so a certificate for another topic is not uploaded
+	diags := resourceMDMPushCertificateCreate(context.Background(), d, client)
+	if !diags.HasError() || !strings.Contains(diags[0].Summary, "does not match the current topic") {
+		t.Errorf("expected a topic mismatch error, got %v", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("expected the resource not to be created, got %q", d.Id())
+	}
+}
@@ -0,0 +1,323 @@
+package mdm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// deviceActionSpec describes an MDM action of the catalog
+type deviceActionSpec struct {
+	// Block is the parameter block of the action, empty when the action takes no parameters
+	Block string
+	// BlockRequired is true when the action cannot run without its parameters
+	BlockRequired bool
+}
+
+// deviceActionCatalog lists the supported MDM actions, keyed by action type
+var deviceActionCatalog = map[string]deviceActionSpec{
+	"lock":                 {Block: "lock"},
+	"wipe":                 {Block: "wipe"},
+	"restart":              {Block: "restart"},
+	"shutdown":             {},
+	"clear_passcode":       {},
+	"erase_and_reinstall":  {Block: "erase_and_reinstall"},
+	"renew_enrollment":     {},
+	"schedule_os_update":   {Block: "schedule_os_update"},
+	"enable_lost_mode":     {Block: "lost_mode", BlockRequired: true},
+	"disable_lost_mode":    {},
+	"play_lost_mode_sound": {},
+}
+
+// deviceActionParameters maps the fields of each parameter block to their API names
+var deviceActionParameters = map[string]map[string]string{
+	"lock": {
+		"pin":          "pin",
+		"message":      "message",
+		"phone_number": "phoneNumber",
+	},
+	"wipe": {
+		"pin":                      "pin",
+		"preserve_data_plan":       "preserveDataPlan",
+		"disallow_proximity_setup": "disallowProximitySetup",
+		"obliteration_behavior":    "obliterationBehavior",
+	},
+	"restart": {
+		"notify_user":          "notifyUser",
+		"rebuild_kernel_cache": "rebuildKernelCache",
+	},
+	"erase_and_reinstall": {
+		"pin":                   "pin",
+		"obliteration_behavior": "obliterationBehavior",
+	},
+	"schedule_os_update": {
+		"install_action":     "installAction",
+		"product_version":    "productVersion",
+		"max_user_deferrals": "maxUserDeferrals",
+		"priority":           "priority",
+	},
+	"lost_mode": {
+		"message":      "message",
+		"phone_number": "phoneNumber",
+		"footnote":     "footnote",
+	},
+}
+
+// Statuses that end the wait for an action
+var (
+	deviceActionSucceededStatuses = map[string]bool{"completed": true, "acknowledged": true}
+	deviceActionFailedStatuses    = map[string]bool{"failed": true, "error": true, "canceled": true, "cancelled": true}
+)
+
+// Bounds of the exponential backoff between two polls of the action status
+var (
+	deviceActionPollInitial = 2 * time.Second
+	deviceActionPollMax     = 30 * time.Second
+)
+
+// errDeviceActionTimeout is returned when the action is still pending at the end of the wait
+var errDeviceActionTimeout = errors.New("timeout waiting for MDM device action to complete")
+
+var devicePINRegexp = regexp.MustCompile(`^[0-9]{6}$`)
+
+// deviceActionTypes returns the sorted action types of the catalog
+func deviceActionTypes() []string {
+	types := make([]string, 0, len(deviceActionCatalog))
+	for t := range deviceActionCatalog {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// devicePINSchema returns the schema of the six digit PIN of lock and erase actions
+func devicePINSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Sensitive:    true,
+		ValidateFunc: validation.StringMatch(devicePINRegexp, "must be a six digit PIN"),
+		Description:  "Six digit PIN required to unlock the device (macOS)",
+	}
+}
+
+// obliterationBehaviorSchema returns the schema of the obliteration behavior of erase actions
+func obliterationBehaviorSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		Default:      "Default",
+		ValidateFunc: validation.StringInSlice([]string{"Default", "DoNotObliterate", "ObliterateWithWarning", "Always"}, false),
+		Description:  "What the device does when Erase All Content and Settings fails (macOS): Default, DoNotObliterate, ObliterateWithWarning or Always",
+	}
+}
+
+// deviceActionBlockSchemas returns the parameter blocks of the actions
+func deviceActionBlockSchemas() map[string]*schema.Schema {
+	block := func(description string, fields map[string]*schema.Schema) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeList,
+			Optional:    true,
+			ForceNew:    true,
+			MaxItems:    1,
+			Description: description,
+			Elem:        &schema.Resource{Schema: fields},
+		}
+	}
+
+	return map[string]*schema.Schema{
+		"lock": block("Parameters of the lock action", map[string]*schema.Schema{
+			"pin":          devicePINSchema(),
+			"message":      {Type: schema.TypeString, Optional: true, Description: "Message displayed on the lock screen"},
+			"phone_number": {Type: schema.TypeString, Optional: true, Description: "Phone number displayed on the lock screen"},
+		}),
+		"wipe": block("Parameters of the wipe action", map[string]*schema.Schema{
+			"pin":                      devicePINSchema(),
+			"preserve_data_plan":       {Type: schema.TypeBool, Optional: true, Description: "Keep the cellular data plan of the device (iOS)"},
+			"disallow_proximity_setup": {Type: schema.TypeBool, Optional: true, Description: "Prevent Proximity Setup after the wipe (iOS)"},
+			"obliteration_behavior":    obliterationBehaviorSchema(),
+		}),
+		"restart": block("Parameters of the restart action", map[string]*schema.Schema{
+			"notify_user":          {Type: schema.TypeBool, Optional: true, Description: "Let the user save their work before the restart (macOS)"},
+			"rebuild_kernel_cache": {Type: schema.TypeBool, Optional: true, Description: "Rebuild the kernel cache during the restart (macOS)"},
+		}),
+		"erase_and_reinstall": block("Parameters of the erase_and_reinstall action", map[string]*schema.Schema{
+			"pin":                   devicePINSchema(),
+			"obliteration_behavior": obliterationBehaviorSchema(),
+		}),
+		"schedule_os_update": block("Parameters of the schedule_os_update action", map[string]*schema.Schema{
+			"install_action": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "Default",
+				ValidateFunc: validation.StringInSlice([]string{"Default", "DownloadOnly", "InstallASAP", "NotifyOnly", "InstallLater", "InstallForceRestart"}, false),
+				Description:  "How the update is installed",
+			},
+			"product_version": {Type: schema.TypeString, Optional: true, Description: "OS version to install, the latest one when empty"},
+			"max_user_deferrals": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 100),
+				Description:  "Number of times the user can defer the update (macOS, InstallLater only)",
+			},
+			"priority": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "Low",
+				ValidateFunc: validation.StringInSlice([]string{"Low", "High"}, false),
+				Description:  "Priority of the update (macOS)",
+			},
+		}),
+		"lost_mode": block("Parameters of the enable_lost_mode action", map[string]*schema.Schema{
+			"message":      {Type: schema.TypeString, Optional: true, Description: "Message displayed on the lock screen"},
+			"phone_number": {Type: schema.TypeString, Optional: true, Description: "Phone number displayed on the lock screen"},
+			"footnote":     {Type: schema.TypeString, Optional: true, Description: "Footnote displayed on the lock screen"},
+		}),
+	}
+}
+
+// deviceActionBlock returns the parameter block of the configuration, nil when it is not set
+func deviceActionBlock(raw interface{}) map[string]interface{} {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	block, _ := list[0].(map[string]interface{})
+	return block
+}
+
+// validateDeviceActionBlocks checks that only the parameter block of the action is set and that
+// required parameters are present. blocks maps each block name to its configuration
+func validateDeviceActionBlocks(actionType string, blocks map[string]interface{}) error {
+	spec, ok := deviceActionCatalog[actionType]
+	if !ok {
+		return cty.GetAttrPath("action_type").NewErrorf("unsupported action type %q", actionType)
+	}
+
+	for name := range deviceActionParameters {
+		if name != spec.Block && deviceActionBlock(blocks[name]) != nil {
+			return cty.GetAttrPath(name).NewErrorf("the %s block does not apply to the %s action", name, actionType)
+		}
+	}
+
+	if spec.Block == "" {
+		return nil
+	}
+	block := deviceActionBlock(blocks[spec.Block])
+	if spec.BlockRequired && block == nil {
+		return cty.GetAttrPath(spec.Block).NewErrorf("the %s action needs a %s block", actionType, spec.Block)
+	}
+	if spec.Block == "lost_mode" && block != nil && block["message"] == "" && block["phone_number"] == "" {
+		return cty.GetAttrPath(spec.Block).NewErrorf("lost mode needs a message or a phone_number")
+	}
+	return nil
+}
+
+// customizeDeviceActionDiff validates the parameter blocks against the action type at plan time
+func customizeDeviceActionDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("action_type") {
+		return nil
+	}
+	blocks := make(map[string]interface{}, len(deviceActionParameters))
+	for name := range deviceActionParameters {
+		blocks[name] = d.Get(name)
+	}
+	return validateDeviceActionBlocks(d.Get("action_type").(string), blocks)
+}
+
+// expandDeviceActionParameters converts the parameter block of the action to its API representation
+func expandDeviceActionParameters(actionType string, blocks map[string]interface{}) map[string]interface{} {
+	spec := deviceActionCatalog[actionType]
+	block := deviceActionBlock(blocks[spec.Block])
+	if block == nil {
+		return nil
+	}
+
+	params := make(map[string]interface{})
+	for field, apiName := range deviceActionParameters[spec.Block] {
+		switch v := block[field].(type) {
+		case string:
+			if v != "" {
+				params[apiName] = v
+			}
+		case bool:
+			if v {
+				params[apiName] = v
+			}
+		case int:
+			if v != 0 {
+				params[apiName] = v
+			}
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// getDeviceAction reads the current state of an action
+func getDeviceAction(c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}, deviceID, actionID string) (*MDMDeviceAction, error) {
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/mdm/devices/%s/actions/%s", deviceID, actionID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var action MDMDeviceAction
+	if err := json.Unmarshal(resp, &action); err != nil {
+		return nil, fmt.Errorf("error deserializing action status response: %v", err)
+	}
+	return &action, nil
+}
+
+// waitForDeviceAction polls the action status with an exponential backoff until it completes, fails
+// or the timeout expires. It returns the last known state of the action with errDeviceActionTimeout
+// when the action is still pending
+func waitForDeviceAction(ctx context.Context, c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}, deviceID, actionID string, timeout time.Duration) (*MDMDeviceAction, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var last *MDMDeviceAction
+	delay := deviceActionPollInitial
+	for {
+		select {
+		case <-waitCtx.Done():
+			return last, errDeviceActionTimeout
+		case <-time.After(delay):
+		}
+
+		action, err := getDeviceAction(c, deviceID, actionID)
+		if err != nil {
+			return last, fmt.Errorf("error checking MDM device action status: %v", err)
+		}
+		last = action
+
+		switch {
+		case deviceActionSucceededStatuses[action.Status]:
+			tflog.Debug(ctx, fmt.Sprintf("MDM device action %s completed with status: %s", actionID, action.Status))
+			return action, nil
+		case deviceActionFailedStatuses[action.Status]:
+			return action, fmt.Errorf("MDM device action %s ended with status %s", actionID, action.Status)
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("MDM device action status: %s, checking again in %s", action.Status, delay))
+		delay *= 2
+		if delay > deviceActionPollMax {
+			delay = deviceActionPollMax
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package mdm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+// deviceActionTestClient returns the configured statuses in sequence, repeating the last one
+type deviceActionTestClient struct {
+	statuses []string
+	polls    int
+}
+
+func (c *deviceActionTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	if method != "GET" {
+		return nil, fmt.Errorf("unexpected request %s %s", method, path)
+	}
+	status := c.statuses[len(c.statuses)-1]
+	if c.polls < len(c.statuses) {
+		status = c.statuses[c.polls]
+	}
+	c.polls++
+	return json.Marshal(MDMDeviceAction{ID: "action", DeviceID: "device", Status: status})
+}
+
+func withFastDeviceActionPolls(t *testing.T) {
+	initial, max := deviceActionPollInitial, deviceActionPollMax
+	deviceActionPollInitial, deviceActionPollMax = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { deviceActionPollInitial, deviceActionPollMax = initial, max })
+}
+
+func TestDeviceActionCatalog(t *testing.T) {
+	r := ResourceDeviceAction()
+	for actionType, spec := range deviceActionCatalog {
+		if spec.Block == "" {
+			continue
+		}
+		if r.Schema[spec.Block] == nil {
+			t.Errorf("%s: missing %s block in the schema", actionType, spec.Block)
+		}
+		if deviceActionParameters[spec.Block] == nil {
+			t.Errorf("%s: missing API names of the %s block", actionType, spec.Block)
+		}
+	}
+	for _, key := range []string{"action_type", "reason", "triggers"} {
+		if !r.Schema[key].ForceNew {
+			t.Errorf("expected %s to run the action again", key)
+		}
+	}
+}
+
+func TestValidateDeviceActionBlocks(t *testing.T) {
+	lock := []interface{}{map[string]interface{}{"pin": "123456", "message": "Lost", "phone_number": ""}}
+	emptyLostMode := []interface{}{map[string]interface{}{"message": "", "phone_number": "", "footnote": "x"}}
+
+	cases := []struct {
+		name       string
+		actionType string
+		blocks     map[string]interface{}
+		attribute  string
+	}{
+		{"lock with parameters", "lock", map[string]interface{}{"lock": lock}, ""},
+		{"lock without parameters", "lock", map[string]interface{}{}, ""},
+		{"parameters of another action", "restart", map[string]interface{}{"lock": lock}, "lock"},
+		{"lost mode without block", "enable_lost_mode", map[string]interface{}{}, "lost_mode"},
+		{"lost mode without message", "enable_lost_mode", map[string]interface{}{"lost_mode": emptyLostMode}, "lost_mode"},
+		{"unknown action", "self_destruct", map[string]interface{}{}, "action_type"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateDeviceActionBlocks(tc.actionType, tc.blocks)
+			if tc.attribute == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			pathErr, ok := err.(cty.PathError)
+			if !ok || !pathErr.Path.Equals(cty.GetAttrPath(tc.attribute)) {
+				t.Errorf("expected an error on %s, got %v", tc.attribute, err)
+			}
+		})
+	}
+}
+
+func TestExpandDeviceActionParameters(t *testing.T) {
+	blocks := map[string]interface{}{
+		"wipe": []interface{}{map[string]interface{}{
+			"pin":                      "",
+			"preserve_data_plan":       true,
+			"disallow_proximity_setup": false,
+			"obliteration_behavior":    "DoNotObliterate",
+		}},
+		"schedule_os_update": []interface{}{map[string]interface{}{
+			"install_action":     "InstallLater",
+			"product_version":    "14.5",
+			"max_user_deferrals": 3,
+			"priority":           "High",
+		}},
+	}
+
+	want := map[string]interface{}{"preserveDataPlan": true, "obliterationBehavior": "DoNotObliterate"}
+	if got := expandDeviceActionParameters("wipe", blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	want = map[string]interface{}{"installAction": "InstallLater", "productVersion": "14.5", "maxUserDeferrals": 3, "priority": "High"}
+	if got := expandDeviceActionParameters("schedule_os_update", blocks); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := expandDeviceActionParameters("shutdown", blocks); got != nil {
+		t.Errorf("expected no parameters for shutdown, got %v", got)
+	}
+}
+
+func TestWaitForDeviceAction(t *testing.T) {
+	withFastDeviceActionPolls(t)
+
+	client := &deviceActionTestClient{statuses: []string{"pending", "pending", "completed"}}
+	action, err := waitForDeviceAction(context.Background(), client, "device", "action", time.Second)
+	if err != nil || action.Status != "completed" || client.polls != 3 {
+		t.Errorf("expected completion after 3 polls, got %v after %d: %v", action, client.polls, err)
+	}
+
+	client = &deviceActionTestClient{statuses: []string{"pending", "failed"}}
+	if _, err := waitForDeviceAction(context.Background(), client, "device", "action", time.Second); err == nil || errors.Is(err, errDeviceActionTimeout) {
+		t.Errorf("expected a failure, got %v", err)
+	}
+
+	client = &deviceActionTestClient{statuses: []string{"pending"}}
+	action, err = waitForDeviceAction(context.Background(), client, "device", "action", 30*time.Millisecond)
+	if !errors.Is(err, errDeviceActionTimeout) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if action == nil || action.Status != "pending" {
+		t.Errorf("expected the last known status, got %v", action)
+	}
+}
@@ -0,0 +1,213 @@
+package mdm
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// mobileconfigPlatforms lists the platforms that install Apple configuration profiles
+var mobileconfigPlatforms = map[string]bool{"ios": true, "macos": true}
+
+// stableUUIDNamespace is the namespace of the name-based UUIDs injected by stable_uuids
+var stableUUIDNamespace = []byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+var payloadUUIDRegexp = regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`)
+
+// stableUUID returns the name-based (version 5) UUID of a payload identifier, so the same profile gets
+// the same UUIDs in every organization
+func stableUUID(identifier string) string {
+	h := sha1.New()
+	h.Write(stableUUIDNamespace)
+	h.Write([]byte(identifier))
+	u := h.Sum(nil)[:16]
+	u[6] = (u[6] & 0x0f) | 0x50
+	u[8] = (u[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%X-%X-%X-%X-%X", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// mobileconfigPayloads returns the top-level dictionary of the profile followed by the dictionaries of
+// PayloadContent, with the path used in error messages
+func mobileconfigPayloads(root map[string]interface{}) ([]map[string]interface{}, []string, error) {
+	payloads := []map[string]interface{}{root}
+	paths := []string{"profile"}
+
+	content, ok := root["PayloadContent"]
+	if !ok || root["PayloadType"] != "Configuration" {
+		return payloads, paths, nil
+	}
+	items, ok := content.([]interface{})
+	if !ok {
+		return nil, nil, errors.New("PayloadContent must be an array")
+	}
+	for i, item := range items {
+		payload, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("PayloadContent[%d] must be a dictionary", i)
+		}
+		payloads = append(payloads, payload)
+		paths = append(paths, fmt.Sprintf("PayloadContent[%d]", i))
+	}
+	return payloads, paths, nil
+}
+
+// validateMobileconfig checks the keys every Apple configuration profile and each of its payloads must
+// carry
+func validateMobileconfig(root map[string]interface{}) error {
+	if root["PayloadType"] != "Configuration" {
+		return fmt.Errorf("profile PayloadType must be Configuration, got %v", root["PayloadType"])
+	}
+
+	payloads, paths, err := mobileconfigPayloads(root)
+	if err != nil {
+		return err
+	}
+
+	identifiers := make(map[string]string, len(payloads))
+	for i, payload := range payloads {
+		for _, key := range []string{"PayloadType", "PayloadIdentifier", "PayloadUUID"} {
+			if v, ok := payload[key].(string); !ok || v == "" {
+				return fmt.Errorf("%s is missing %s", paths[i], key)
+			}
+		}
+		if uuid := payload["PayloadUUID"].(string); !payloadUUIDRegexp.MatchString(uuid) {
+			return fmt.Errorf("%s PayloadUUID %q is not a UUID", paths[i], uuid)
+		}
+		identifier := payload["PayloadIdentifier"].(string)
+		if previous, ok := identifiers[identifier]; ok {
+			return fmt.Errorf("%s reuses the PayloadIdentifier %q of %s", paths[i], identifier, previous)
+		}
+		identifiers[identifier] = paths[i]
+	}
+	return nil
+}
+
+// injectStableUUIDs replaces the PayloadUUID of the profile and of its payloads with the stable UUID
+// of their PayloadIdentifier
+func injectStableUUIDs(root map[string]interface{}) error {
+	payloads, _, err := mobileconfigPayloads(root)
+	if err != nil {
+		return err
+	}
+	for _, payload := range payloads {
+		if identifier, ok := payload["PayloadIdentifier"].(string); ok && identifier != "" {
+			payload["PayloadUUID"] = stableUUID(identifier)
+		}
+	}
+	return nil
+}
+
+// normalizeProfilePayload returns the canonical form of a profile payload. Property lists (XML or
+// binary) are validated as configuration profiles and re-encoded as canonical XML, other payloads
+// must be a JSON object and are normalized as JSON
+func normalizeProfilePayload(payload string, stableUUIDs bool) (string, error) {
+	if !isPlist([]byte(payload)) {
+		var payloadMap map[string]interface{}
+		if err := json.Unmarshal([]byte(payload), &payloadMap); err != nil {
+			return "", fmt.Errorf("payload is neither a property list nor a JSON object: %v", err)
+		}
+		return normalizeJSONString(payload)
+	}
+
+	value, err := parsePlist([]byte(payload))
+	if err != nil {
+		return "", err
+	}
+	root, ok := value.(map[string]interface{})
+	if !ok {
+		return "", errors.New("the property list must be a dictionary")
+	}
+	if stableUUIDs {
+		if err := injectStableUUIDs(root); err != nil {
+			return "", err
+		}
+	}
+	if err := validateMobileconfig(root); err != nil {
+		return "", err
+	}
+	return encodePlistXML(root)
+}
+
+// expandProfilePayload returns the API representation of a normalized payload: profiles are sent as a
+// JSON string holding the XML, JSON payloads as is
+func expandProfilePayload(normalized string) (json.RawMessage, error) {
+	if isPlist([]byte(normalized)) {
+		return json.Marshal(normalized)
+	}
+	return json.RawMessage(normalized), nil
+}
+
+// flattenProfilePayload converts the payload returned by the API to its canonical form
+func flattenProfilePayload(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if isPlist([]byte(s)) {
+			return normalizeProfilePayload(s, false)
+		}
+		return s, nil
+	}
+	return normalizeJSONString(string(raw))
+}
+
+// suppressEquivalentProfilePayloadDiffs ignores formatting, key order and, with stable_uuids, the
+// PayloadUUID values of the configured payload
+func suppressEquivalentProfilePayloadDiffs(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || new == "" {
+		return false
+	}
+	oldNormalized, err := normalizeProfilePayload(old, false)
+	if err != nil {
+		return false
+	}
+	newNormalized, err := normalizeProfilePayload(new, d.Get("stable_uuids").(bool))
+	if err != nil {
+		return false
+	}
+	return oldNormalized == newNormalized
+}
+
+// customizeProfilePayloadDiff validates the payload at plan time. A payload_file is read and its
+// normalized content planned as the new payload, so editing the file shows up in the plan
+func customizeProfilePayloadDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"payload", "payload_file", "stable_uuids", "platform"} {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+	stableUUIDs := d.Get("stable_uuids").(bool)
+
+	attribute := "payload"
+	payload := d.Get("payload").(string)
+	path := d.Get("payload_file").(string)
+	if path != "" {
+		attribute = "payload_file"
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return cty.GetAttrPath(attribute).NewErrorf("error reading %s: %v", path, err)
+		}
+		payload = string(data)
+	}
+
+	normalized, err := normalizeProfilePayload(payload, stableUUIDs)
+	if err != nil {
+		return cty.GetAttrPath(attribute).NewErrorf("invalid profile payload: %v", err)
+	}
+	if platform := d.Get("platform").(string); isPlist([]byte(normalized)) && !mobileconfigPlatforms[platform] {
+		return cty.GetAttrPath(attribute).NewErrorf("configuration profiles only apply to ios and macos, not %s", platform)
+	}
+
+	if path != "" {
+		// payload is computed when it is not configured, the state holds the last applied profile
+		if old, _ := d.GetChange("payload"); old.(string) != normalized {
+			return d.SetNew("payload", normalized)
+		}
+	}
+	return nil
+}
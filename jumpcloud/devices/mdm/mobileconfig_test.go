@@ -0,0 +1,263 @@
+package mdm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const testMobileconfig = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>PayloadDisplayName</key>
+	<string>Wi-Fi &amp; Restrictions</string>
+	<key>PayloadContent</key>
+	<array>
+		<dict>
+			<key>PayloadType</key>
+			<string>com.apple.wifi.managed</string>
+			<key>PayloadIdentifier</key>
+			<string>com.example.wifi</string>
+			<key>PayloadUUID</key>
+			<string>0D1E2F30-4152-6374-8596-A7B8C9DAEBFC</string>
+			<key>AutoJoin</key>
+			<true/>
+			<key>SSID_STR</key>
+			<string>Corp</string>
+		</dict>
+	</array>
+	<key>PayloadIdentifier</key>
+	<string>com.example.profile</string>
+	<key>PayloadType</key>
+	<string>Configuration</string>
+	<key>PayloadUUID</key>
+	<string>9A8B7C6D-5E4F-4A3B-8C2D-1E0F9A8B7C6D</string>
+	<key>PayloadVersion</key>
+	<integer>1</integer>
+</dict>
+</plist>
+`
+
+// encodeTestBinaryPlist writes a bplist00 file with two byte offsets and one byte references, enough
+// for the small profiles of the tests
+func encodeTestBinaryPlist(t *testing.T, v interface{}) []byte {
+	var objects [][]byte
+	var add func(v interface{}) int
+	add = func(v interface{}) int {
+		index := len(objects)
+		objects = append(objects, nil)
+		marker := func(kind byte, n int) []byte {
+			if n < 15 {
+				return []byte{kind | byte(n)}
+			}
+			return []byte{kind | 0x0F, 0x10, byte(n)}
+		}
+		switch value := v.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(value))
+			for k := range value {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			refs := marker(0xD0, len(keys))
+			var values []byte
+			for _, k := range keys {
+				refs = append(refs, byte(add(k)))
+				values = append(values, byte(add(value[k])))
+			}
+			objects[index] = append(refs, values...)
+		case []interface{}:
+			b := marker(0xA0, len(value))
+			for _, item := range value {
+				b = append(b, byte(add(item)))
+			}
+			objects[index] = b
+		case string:
+			objects[index] = append(marker(0x50, len(value)), value...)
+		case int64:
+			b := make([]byte, 8)
+			binary.BigEndian.PutUint64(b, uint64(value))
+			objects[index] = append([]byte{0x13}, b...)
+		case bool:
+			objects[index] = []byte{0x08}
+			if value {
+				objects[index] = []byte{0x09}
+			}
+		default:
+			t.Fatalf("unsupported test value %T", v)
+		}
+		return index
+	}
+	add(v)
+	if len(objects) > 255 {
+		t.Fatal("too many objects for one byte references")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(binaryPlistMagic)
+	offsets := make([]byte, 2*len(objects))
+	for i, object := range objects {
+		binary.BigEndian.PutUint16(offsets[2*i:], uint16(buf.Len()))
+		buf.Write(object)
+	}
+	offsetTable := buf.Len()
+	buf.Write(offsets)
+
+	trailer := make([]byte, 32)
+	trailer[6], trailer[7] = 2, 1
+	binary.BigEndian.PutUint64(trailer[8:], uint64(len(objects)))
+	binary.BigEndian.PutUint64(trailer[24:], uint64(offsetTable))
+	buf.Write(trailer)
+	return buf.Bytes()
+}
+
+func TestNormalizeProfilePayloadXML(t *testing.T) {
+	normalized, err := normalizeProfilePayload(testMobileconfig, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(normalized, "<string>Wi-Fi &amp; Restrictions</string>") {
+		t.Errorf("expected the escaped display name, got:\n%s", normalized)
+	}
+
+	// Key order and indentation do not change the canonical form
+	compact := strings.NewReplacer("\n", "", "\t", "").Replace(testMobileconfig)
+	compact = strings.Replace(compact, "<key>PayloadVersion</key><integer>1</integer>", "", 1)
+	compact = strings.Replace(compact, "<dict>", "<dict><key>PayloadVersion</key><integer>1</integer>", 1)
+	again, err := normalizeProfilePayload(compact, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again != normalized {
+		t.Errorf("expected the same canonical form, got:\n%s\nand:\n%s", normalized, again)
+	}
+
+	if twice, _ := normalizeProfilePayload(normalized, false); twice != normalized {
+		t.Errorf("expected normalization to be idempotent, got:\n%s", twice)
+	}
+}
+
+func TestNormalizeProfilePayloadBinary(t *testing.T) {
+	value, err := parsePlist([]byte(testMobileconfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	binaryPayload := encodeTestBinaryPlist(t, value)
+
+	fromBinary, err := normalizeProfilePayload(string(binaryPayload), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fromXML, _ := normalizeProfilePayload(testMobileconfig, false)
+	if fromBinary != fromXML {
+		t.Errorf("expected the binary and XML profiles to normalize alike, got:\n%s\nand:\n%s", fromBinary, fromXML)
+	}
+
+	if _, err := parsePlist(binaryPayload[:len(binaryPayload)-40]); err == nil {
+		t.Error("expected an error on a truncated binary plist")
+	}
+
+	// A dictionary of 2^63 entries overflows its reference count once doubled
+	var huge bytes.Buffer
+	huge.WriteString(binaryPlistMagic)
+	huge.Write([]byte{0xDF, 0x13, 0x80, 0, 0, 0, 0, 0, 0, 0})
+	offsetTable := huge.Len()
+	huge.Write([]byte{0, byte(len(binaryPlistMagic))})
+	trailer := make([]byte, 32)
+	trailer[6], trailer[7] = 2, 1
+	binary.BigEndian.PutUint64(trailer[8:], 1)
+	binary.BigEndian.PutUint64(trailer[24:], uint64(offsetTable))
+	huge.Write(trailer)
+	if _, err := parsePlist(huge.Bytes()); err == nil || !strings.Contains(err.Error(), "out of bounds") {
+		t.Errorf("expected an out of bounds error on a huge dictionary, got %v", err)
+	}
+}
+
+func TestValidateMobileconfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		replace [2]string
+		message string
+	}{
+		{"valid", [2]string{"", ""}, ""},
+		{"not a configuration", [2]string{"<string>Configuration</string>", "<string>com.apple.wifi.managed</string>"}, "must be Configuration"},
+		{"payload without identifier", [2]string{"<string>com.example.wifi</string>", "<string></string>"}, "PayloadContent[0] is missing PayloadIdentifier"},
+		{"invalid UUID", [2]string{"9A8B7C6D-5E4F-4A3B-8C2D-1E0F9A8B7C6D", "not-a-uuid"}, "is not a UUID"},
+		{"duplicate identifier", [2]string{"<string>com.example.wifi</string>", "<string>com.example.profile</string>"}, "reuses the PayloadIdentifier"},
+		{"malformed XML", [2]string{"</array>", ""}, "invalid plist"},
+		{"not JSON either", [2]string{testMobileconfig, "payload"}, "neither a property list nor a JSON object"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := testMobileconfig
+			if tc.replace[0] != "" {
+				payload = strings.Replace(payload, tc.replace[0], tc.replace[1], 1)
+			}
+			_, err := normalizeProfilePayload(payload, false)
+			if tc.message == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("expected an error containing %q, got %v", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestStableUUIDs(t *testing.T) {
+	// Missing UUIDs are filled in and existing ones replaced, whatever the organization
+	withoutUUIDs := strings.Replace(testMobileconfig, "<key>PayloadUUID</key>\n\t\t\t<string>0D1E2F30-4152-6374-8596-A7B8C9DAEBFC</string>", "", 1)
+	first, err := normalizeProfilePayload(withoutUUIDs, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := normalizeProfilePayload(testMobileconfig, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second {
+		t.Errorf("expected the same profile, got:\n%s\nand:\n%s", first, second)
+	}
+	if !strings.Contains(first, stableUUID("com.example.wifi")) || !strings.Contains(first, stableUUID("com.example.profile")) {
+		t.Errorf("expected the stable UUIDs in the profile, got:\n%s", first)
+	}
+	if uuid := stableUUID("com.example.wifi"); !payloadUUIDRegexp.MatchString(uuid) || uuid[14] != '5' {
+		t.Errorf("expected a version 5 UUID, got %s", uuid)
+	}
+
+	if _, err := normalizeProfilePayload(withoutUUIDs, false); err == nil {
+		t.Error("expected an error on a missing PayloadUUID without stable_uuids")
+	}
+}
+
+func TestProfilePayloadRoundTrip(t *testing.T) {
+	normalized, _ := normalizeProfilePayload(testMobileconfig, false)
+	raw, err := expandProfilePayload(normalized)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		t.Fatalf("expected the profile to be sent as a JSON string, got %s", raw)
+	}
+	if flattened, err := flattenProfilePayload(raw); err != nil || flattened != normalized {
+		t.Errorf("expected the profile back, got %v:\n%s", err, flattened)
+	}
+
+	jsonPayload := `{ "b": 1, "a": true }`
+	normalized, err = normalizeProfilePayload(jsonPayload, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ = expandProfilePayload(normalized)
+	if flattened, err := flattenProfilePayload(raw); err != nil || flattened != `{"a":true,"b":1}` {
+		t.Errorf("expected the normalized JSON back, got %v: %s", err, flattened)
+	}
+}
@@ -0,0 +1,486 @@
+package mdm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Property list values are decoded to map[string]interface{}, []interface{}, string, int64, float64,
+// bool, time.Time and []byte
+
+const binaryPlistMagic = "bplist00"
+
+// plistDateFormat is the format of <date> values
+const plistDateFormat = "2006-01-02T15:04:05Z"
+
+// isPlist reports whether the data looks like an XML or binary property list
+func isPlist(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return bytes.HasPrefix(trimmed, []byte(binaryPlistMagic)) ||
+		bytes.HasPrefix(trimmed, []byte("<?xml")) ||
+		bytes.HasPrefix(trimmed, []byte("<!DOCTYPE plist")) ||
+		bytes.HasPrefix(trimmed, []byte("<plist"))
+}
+
+// parsePlist decodes an XML or binary property list
+func parsePlist(data []byte) (interface{}, error) {
+	if bytes.HasPrefix(data, []byte(binaryPlistMagic)) {
+		return parseBinaryPlist(data)
+	}
+	return parseXMLPlist(data)
+}
+
+// parseXMLPlist decodes an XML property list
+func parseXMLPlist(data []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("invalid plist: no <plist> element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid plist: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "plist" {
+			return nil, fmt.Errorf("invalid plist: unexpected <%s> root element", start.Name.Local)
+		}
+
+		value, end, err := decodeXMLPlistValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+		if end {
+			return nil, errors.New("invalid plist: empty <plist> element")
+		}
+		return value, nil
+	}
+}
+
+// nextXMLPlistElement returns the next start element, or end=true on the end of the enclosing element
+func nextXMLPlistElement(decoder *xml.Decoder) (xml.StartElement, bool, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.StartElement{}, false, fmt.Errorf("invalid plist: %v", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			return t, false, nil
+		case xml.EndElement:
+			return xml.StartElement{}, true, nil
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return xml.StartElement{}, false, fmt.Errorf("invalid plist: unexpected text %q", strings.TrimSpace(string(t)))
+			}
+		}
+	}
+}
+
+// decodeXMLPlistValue decodes the next value, end is true when the enclosing element ends instead
+func decodeXMLPlistValue(decoder *xml.Decoder) (interface{}, bool, error) {
+	start, end, err := nextXMLPlistElement(decoder)
+	if err != nil || end {
+		return nil, end, err
+	}
+
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		for {
+			keyStart, end, err := nextXMLPlistElement(decoder)
+			if err != nil {
+				return nil, false, err
+			}
+			if end {
+				return dict, false, nil
+			}
+			if keyStart.Name.Local != "key" {
+				return nil, false, fmt.Errorf("invalid plist: expected <key> in <dict>, got <%s>", keyStart.Name.Local)
+			}
+			var key string
+			if err := decoder.DecodeElement(&key, &keyStart); err != nil {
+				return nil, false, fmt.Errorf("invalid plist: %v", err)
+			}
+			value, end, err := decodeXMLPlistValue(decoder)
+			if err != nil {
+				return nil, false, err
+			}
+			if end {
+				return nil, false, fmt.Errorf("invalid plist: no value for key %q", key)
+			}
+			dict[key] = value
+		}
+	case "array":
+		array := make([]interface{}, 0)
+		for {
+			value, end, err := decodeXMLPlistValue(decoder)
+			if err != nil {
+				return nil, false, err
+			}
+			if end {
+				return array, false, nil
+			}
+			array = append(array, value)
+		}
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, false, fmt.Errorf("invalid plist: %v", err)
+		}
+		return start.Name.Local == "true", false, nil
+	}
+
+	var text string
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return nil, false, fmt.Errorf("invalid plist: %v", err)
+	}
+	text = strings.TrimSpace(text)
+
+	switch start.Name.Local {
+	case "string":
+		return text, false, nil
+	case "integer":
+		v, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid plist: invalid integer %q", text)
+		}
+		return v, false, nil
+	case "real":
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid plist: invalid real %q", text)
+		}
+		return v, false, nil
+	case "date":
+		v, err := time.Parse(plistDateFormat, text)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid plist: invalid date %q", text)
+		}
+		return v, false, nil
+	case "data":
+		v, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid plist: invalid data: %v", err)
+		}
+		return v, false, nil
+	}
+	return nil, false, fmt.Errorf("invalid plist: unsupported element <%s>", start.Name.Local)
+}
+
+// binaryPlist holds the state of a binary property list decoding
+type binaryPlist struct {
+	data          []byte
+	offsets       []uint64
+	objectRefSize int
+	depth         int
+}
+
+// parseBinaryPlist decodes a bplist00 property list
+func parseBinaryPlist(data []byte) (interface{}, error) {
+	if len(data) < len(binaryPlistMagic)+32 {
+		return nil, errors.New("invalid binary plist: too short")
+	}
+
+	trailer := data[len(data)-32:]
+	offsetSize := int(trailer[6])
+	objectRefSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:16])
+	topObject := binary.BigEndian.Uint64(trailer[16:24])
+	offsetTable := binary.BigEndian.Uint64(trailer[24:32])
+
+	if offsetSize < 1 || offsetSize > 8 || objectRefSize < 1 || objectRefSize > 8 {
+		return nil, errors.New("invalid binary plist: invalid trailer")
+	}
+	if numObjects == 0 || topObject >= numObjects || offsetTable > uint64(len(data)-32) ||
+		numObjects > (uint64(len(data)-32)-offsetTable)/uint64(offsetSize) {
+		return nil, errors.New("invalid binary plist: invalid offset table")
+	}
+
+	p := &binaryPlist{data: data, objectRefSize: objectRefSize, offsets: make([]uint64, numObjects)}
+	for i := range p.offsets {
+		start := offsetTable + uint64(i*offsetSize)
+		p.offsets[i] = readBigEndian(data[start : start+uint64(offsetSize)])
+	}
+	return p.object(topObject)
+}
+
+// readBigEndian reads an unsigned integer of 1 to 8 bytes
+func readBigEndian(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
+
+// bytesAt returns n bytes at the offset, checking the bounds
+func (p *binaryPlist) bytesAt(offset, n uint64) ([]byte, error) {
+	if offset > uint64(len(p.data)) || n > uint64(len(p.data))-offset {
+		return nil, errors.New("invalid binary plist: object out of bounds")
+	}
+	return p.data[offset : offset+n], nil
+}
+
+// length reads the length encoded in the marker, or in the integer that follows it
+func (p *binaryPlist) length(offset uint64, marker byte) (uint64, uint64, error) {
+	if marker&0x0F != 0x0F {
+		return uint64(marker & 0x0F), offset + 1, nil
+	}
+	header, err := p.bytesAt(offset+1, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if header[0]&0xF0 != 0x10 {
+		return 0, 0, errors.New("invalid binary plist: invalid length")
+	}
+	size := uint64(1) << (header[0] & 0x0F)
+	b, err := p.bytesAt(offset+2, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	return readBigEndian(b), offset + 2 + size, nil
+}
+
+// refs reads n object references
+func (p *binaryPlist) refs(offset, n uint64) ([]uint64, error) {
+	size := uint64(p.objectRefSize)
+	if n > uint64(len(p.data))/size {
+		return nil, errors.New("invalid binary plist: object out of bounds")
+	}
+	b, err := p.bytesAt(offset, n*size)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]uint64, n)
+	for i := range refs {
+		refs[i] = readBigEndian(b[uint64(i)*size : uint64(i+1)*size])
+	}
+	return refs, nil
+}
+
+// object decodes the object with the given index
+func (p *binaryPlist) object(index uint64) (interface{}, error) {
+	if index >= uint64(len(p.offsets)) {
+		return nil, errors.New("invalid binary plist: invalid object reference")
+	}
+	// Containers referencing themselves would recurse forever
+	if p.depth > 512 {
+		return nil, errors.New("invalid binary plist: nesting too deep")
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+
+	offset := p.offsets[index]
+	header, err := p.bytesAt(offset, 1)
+	if err != nil {
+		return nil, err
+	}
+	marker := header[0]
+
+	switch marker & 0xF0 {
+	case 0x00:
+		switch marker {
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+	case 0x10:
+		size := uint64(1) << (marker & 0x0F)
+		if size > 8 {
+			return nil, errors.New("invalid binary plist: integer too large")
+		}
+		b, err := p.bytesAt(offset+1, size)
+		if err != nil {
+			return nil, err
+		}
+		return int64(readBigEndian(b)), nil
+	case 0x20:
+		size := uint64(1) << (marker & 0x0F)
+		b, err := p.bytesAt(offset+1, size)
+		if err != nil {
+			return nil, err
+		}
+		switch size {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+		}
+	case 0x30:
+		if marker == 0x33 {
+			b, err := p.bytesAt(offset+1, 8)
+			if err != nil {
+				return nil, err
+			}
+			// Binary plist dates are seconds since 2001-01-01
+			seconds := math.Float64frombits(binary.BigEndian.Uint64(b))
+			return time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(seconds * float64(time.Second))), nil
+		}
+	case 0x40, 0x50, 0x60, 0xA0, 0xD0:
+		n, start, err := p.length(offset, marker)
+		if err != nil {
+			return nil, err
+		}
+		switch marker & 0xF0 {
+		case 0x40:
+			b, err := p.bytesAt(start, n)
+			if err != nil {
+				return nil, err
+			}
+			return append([]byte{}, b...), nil
+		case 0x50:
+			b, err := p.bytesAt(start, n)
+			if err != nil {
+				return nil, err
+			}
+			return string(b), nil
+		case 0x60:
+			if n > uint64(len(p.data))/2 {
+				return nil, errors.New("invalid binary plist: object out of bounds")
+			}
+			b, err := p.bytesAt(start, n*2)
+			if err != nil {
+				return nil, err
+			}
+			units := make([]uint16, n)
+			for i := range units {
+				units[i] = binary.BigEndian.Uint16(b[i*2:])
+			}
+			return string(utf16.Decode(units)), nil
+		case 0xA0:
+			refs, err := p.refs(start, n)
+			if err != nil {
+				return nil, err
+			}
+			array := make([]interface{}, 0, n)
+			for _, ref := range refs {
+				v, err := p.object(ref)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, v)
+			}
+			return array, nil
+		case 0xD0:
+			// Checked before doubling so that a huge count cannot overflow
+			if n > uint64(len(p.data))/(2*uint64(p.objectRefSize)) {
+				return nil, errors.New("invalid binary plist: object out of bounds")
+			}
+			refs, err := p.refs(start, n*2)
+			if err != nil {
+				return nil, err
+			}
+			dict := make(map[string]interface{}, n)
+			for i := uint64(0); i < n; i++ {
+				key, err := p.object(refs[i])
+				if err != nil {
+					return nil, err
+				}
+				k, ok := key.(string)
+				if !ok {
+					return nil, errors.New("invalid binary plist: dictionary key is not a string")
+				}
+				v, err := p.object(refs[n+i])
+				if err != nil {
+					return nil, err
+				}
+				dict[k] = v
+			}
+			return dict, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid binary plist: unsupported object marker 0x%02x", marker)
+}
+
+// encodePlistXML encodes a value as a canonical XML property list: dictionary keys are sorted and
+// the indentation is fixed, so equivalent profiles encode to the same text
+func encodePlistXML(v interface{}) (string, error) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n")
+	if err := writePlistXMLValue(&b, v, 0); err != nil {
+		return "", err
+	}
+	b.WriteString("</plist>\n")
+	return b.String(), nil
+}
+
+// writePlistXMLValue writes a value at the given indentation level
+func writePlistXMLValue(b *strings.Builder, v interface{}, level int) error {
+	indent := strings.Repeat("\t", level)
+	escape := func(s string) string {
+		var e bytes.Buffer
+		_ = xml.EscapeText(&e, []byte(s))
+		return e.String()
+	}
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			b.WriteString(indent + "<dict/>\n")
+			return nil
+		}
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString(indent + "<dict>\n")
+		for _, k := range keys {
+			b.WriteString(indent + "\t<key>" + escape(k) + "</key>\n")
+			if err := writePlistXMLValue(b, value[k], level+1); err != nil {
+				return err
+			}
+		}
+		b.WriteString(indent + "</dict>\n")
+	case []interface{}:
+		if len(value) == 0 {
+			b.WriteString(indent + "<array/>\n")
+			return nil
+		}
+		b.WriteString(indent + "<array>\n")
+		for _, item := range value {
+			if err := writePlistXMLValue(b, item, level+1); err != nil {
+				return err
+			}
+		}
+		b.WriteString(indent + "</array>\n")
+	case string:
+		b.WriteString(indent + "<string>" + escape(value) + "</string>\n")
+	case int64:
+		b.WriteString(indent + "<integer>" + strconv.FormatInt(value, 10) + "</integer>\n")
+	case float64:
+		b.WriteString(indent + "<real>" + strconv.FormatFloat(value, 'g', -1, 64) + "</real>\n")
+	case bool:
+		if value {
+			b.WriteString(indent + "<true/>\n")
+		} else {
+			b.WriteString(indent + "<false/>\n")
+		}
+	case time.Time:
+		b.WriteString(indent + "<date>" + value.UTC().Format(plistDateFormat) + "</date>\n")
+	case []byte:
+		b.WriteString(indent + "<data>" + base64.StdEncoding.EncodeToString(value) + "</data>\n")
+	default:
+		return fmt.Errorf("unsupported plist value of type %T", v)
+	}
+	return nil
+}
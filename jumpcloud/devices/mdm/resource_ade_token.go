@@ -0,0 +1,159 @@
+package mdm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// ResourceADEToken returns the schema resource for the Automated Device Enrollment (DEP) server token
+// of the Apple MDM server. The first apply exposes the public key to upload in Apple Business Manager,
+// the token downloaded from Apple Business Manager is then set in server_token
+func ResourceADEToken() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMDMADETokenCreate,
+		ReadContext:   resourceMDMADETokenRead,
+		UpdateContext: resourceMDMADETokenUpdate,
+		DeleteContext: resourceMDMADETokenDelete,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"apple_mdm_id": appleMDMIDSchema(),
+			"server_token": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Content of the server token (.p7m) downloaded from Apple Business Manager",
+			},
+			"expiry_warning_days": expiryWarningDaysSchema(),
+			"public_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "PEM encoded public key to upload in Apple Business Manager",
+			},
+			"expires_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Expiry date of the server token (RFC3339)",
+			},
+			"token_state": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "State of the server token (unknown, missing, valid, expired)",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+// uploadADEToken uploads the server token downloaded from Apple Business Manager
+func uploadADEToken(ctx context.Context, d *schema.ResourceData, c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}) error {
+	token := d.Get("server_token").(string)
+	if token == "" {
+		return nil
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Uploading ADE server token to Apple MDM server %s", d.Id()))
+	return updateAppleMDM(c, d.Id(), AppleMDMUpdate{EncryptedDEPServerToken: token})
+}
+
+func resourceMDMADETokenCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(interface {
+		DoRequest(method, path string, body []byte) ([]byte, error)
+	})
+
+	id, err := resolveAppleMDMID(c, d.Get("apple_mdm_id").(string))
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error finding Apple MDM server: %v", err))
+	}
+	d.SetId(id)
+
+	if err := uploadADEToken(ctx, d, c); err != nil {
+		d.SetId("")
+		return diag.FromErr(fmt.Errorf("error uploading ADE server token: %v", err))
+	}
+
+	return resourceMDMADETokenRead(ctx, d, meta)
+}
+
+func resourceMDMADETokenRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c := meta.(interface {
+		DoRequest(method, path string, body []byte) ([]byte, error)
+	})
+
+	id := d.Id()
+	tflog.Debug(ctx, fmt.Sprintf("Reading ADE server token of Apple MDM server %s", id))
+	server, err := getAppleMDM(c, id)
+	if err != nil {
+		if errors.Is(err, errAppleMDMNotFound) {
+			tflog.Warn(ctx, fmt.Sprintf("Apple MDM server %s not found, removing from state", id))
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(fmt.Errorf("error reading Apple MDM server: %v", err))
+	}
+
+	if err := d.Set("apple_mdm_id", server.ID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting apple_mdm_id: %v", err))
+	}
+
+	if err := d.Set("expires_at", server.DEPAccessTokenExpiry); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting expires_at: %v", err))
+	}
+
+	if err := d.Set("token_state", server.DEPServerTokenState); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting token_state: %v", err))
+	}
+
+	if d.Get("public_key").(string) == "" {
+		publicKey, err := getAppleMDMFile(c, id, "depkey")
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error downloading ADE public key: %v", err))
+		}
+		if err := d.Set("public_key", publicKey); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting public_key: %v", err))
+		}
+	}
+
+	return append(diags, expiryWarning("ADE server token", server.DEPAccessTokenExpiry, d.Get("expiry_warning_days").(int), time.Now())...)
+}
+
+func resourceMDMADETokenUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(interface {
+		DoRequest(method, path string, body []byte) ([]byte, error)
+	})
+
+	if d.HasChange("server_token") {
+		if err := uploadADEToken(ctx, d, c); err != nil {
+			if common.IsNotFoundError(err) {
+				return diag.FromErr(fmt.Errorf("Apple MDM server %s not found", d.Id()))
+			}
+			return diag.FromErr(fmt.Errorf("error uploading ADE server token: %v", err))
+		}
+	}
+
+	return resourceMDMADETokenRead(ctx, d, meta)
+}
+
+func resourceMDMADETokenDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Removing the token would stop the enrollment of new devices, so we just remove from state
+	tflog.Warn(ctx, fmt.Sprintf("The ADE server token of Apple MDM server %s is kept in JumpCloud, removing from state only", d.Id()))
+	d.SetId("")
+	return nil
+}
@@ -3,6 +3,7 @@ package mdm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -11,23 +12,25 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
 )
 
 // MDMDeviceAction represents an action to be taken on an MDM device
 type MDMDeviceAction struct {
-	ID         string `json:"_id,omitempty"`
-	OrgID      string `json:"orgId,omitempty"`
-	DeviceID   string `json:"deviceId"`
-	ActionType string `json:"actionType"`
-	Reason     string `json:"reason,omitempty"`
-	Status     string `json:"status,omitempty"`
-	Created    string `json:"created,omitempty"`
-	Updated    string `json:"updated,omitempty"`
+	ID         string                 `json:"_id,omitempty"`
+	OrgID      string                 `json:"orgId,omitempty"`
+	DeviceID   string                 `json:"deviceId"`
+	ActionType string                 `json:"actionType"`
+	Reason     string                 `json:"reason,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Status     string                 `json:"status,omitempty"`
+	Created    string                 `json:"created,omitempty"`
+	Updated    string                 `json:"updated,omitempty"`
 }
 
 // ResourceDeviceAction returns a schema resource for MDM device actions
 func ResourceDeviceAction() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceMDMDeviceActionCreate,
 		ReadContext:   resourceMDMDeviceActionRead,
 		UpdateContext: resourceMDMDeviceActionUpdate,
@@ -52,16 +55,23 @@ func ResourceDeviceAction() *schema.Resource {
 			"action_type": {
 				Type:         schema.TypeString,
 				Required:     true,
-				ForceNew:     false,
-				Description:  "Type of action to perform (lock, wipe, restart, shutdown, clear_passcode)",
-				ValidateFunc: validation.StringInSlice([]string{"lock", "wipe", "restart", "shutdown", "clear_passcode"}, false),
+				ForceNew:     true,
+				Description:  "Type of action to perform (lock, wipe, restart, shutdown, clear_passcode, erase_and_reinstall, renew_enrollment, schedule_os_update, enable_lost_mode, disable_lost_mode, play_lost_mode_sound)",
+				ValidateFunc: validation.StringInSlice(deviceActionTypes(), false),
 			},
 			"reason": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				ForceNew:    false,
+				ForceNew:    true,
 				Description: "Reason for performing the action",
 			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values that run the action again when changed",
+			},
 			"status": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -82,15 +92,21 @@ func ResourceDeviceAction() *schema.Resource {
 				Optional:    true,
 				Default:     300,
 				ForceNew:    false,
-				Description: "Time in seconds to wait for the action to complete",
+				Description: "Time in seconds to wait for the action to complete, 0 to not wait. An action still pending at the end of the wait is kept and not run again",
 			},
 		},
+		CustomizeDiff: customizeDeviceActionDiff,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(10 * time.Minute),
 			Read:   schema.DefaultTimeout(5 * time.Minute),
 			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 	}
+
+	for name, block := range deviceActionBlockSchemas() {
+		r.Schema[name] = block
+	}
+	return r
 }
 
 func resourceMDMDeviceActionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -113,6 +129,12 @@ func resourceMDMDeviceActionCreate(ctx context.Context, d *schema.ResourceData,
 		action.Reason = v.(string)
 	}
 
+	blocks := make(map[string]interface{}, len(deviceActionParameters))
+	for name := range deviceActionParameters {
+		blocks[name] = d.Get(name)
+	}
+	action.Parameters = expandDeviceActionParameters(action.ActionType, blocks)
+
 	// Serialize to JSON
 	actionJSON, err := json.Marshal(action)
 	if err != nil {
@@ -138,43 +160,25 @@ func resourceMDMDeviceActionCreate(ctx context.Context, d *schema.ResourceData,
 
 	d.SetId(createdAction.ID)
 
-	// Wait for the action to complete if a timeout is specified
+	// Wait for the action to complete if a timeout is specified. The ID is already set, so an action
+	// still pending at the end of the wait stays in the state and is not sent again on the next apply
+	var diags diag.Diagnostics
 	timeout := d.Get("timeout").(int)
 	if timeout > 0 {
-		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
-		defer cancel()
-
 		tflog.Debug(ctx, fmt.Sprintf("Waiting up to %d seconds for MDM device action to complete", timeout))
-
-		for {
-			select {
-			case <-timeoutCtx.Done():
-				return diag.FromErr(fmt.Errorf("timeout waiting for MDM device action to complete"))
-			case <-time.After(5 * time.Second):
-				// Check action status
-				resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/mdm/devices/%s/actions/%s", action.DeviceID, createdAction.ID), nil)
-				if err != nil {
-					return diag.FromErr(fmt.Errorf("error checking MDM device action status: %v", err))
-				}
-
-				var currentAction MDMDeviceAction
-				if err := json.Unmarshal(resp, &currentAction); err != nil {
-					return diag.FromErr(fmt.Errorf("error deserializing action status response: %v", err))
-				}
-
-				if currentAction.Status == "completed" || currentAction.Status == "failed" {
-					tflog.Debug(ctx, fmt.Sprintf("MDM device action completed with status: %s", currentAction.Status))
-					break
-				}
-
-				tflog.Debug(ctx, fmt.Sprintf("MDM device action status: %s, continuing to wait", currentAction.Status))
-				continue
-			}
-			break
+		_, err := waitForDeviceAction(ctx, c, action.DeviceID, createdAction.ID, time.Duration(timeout)*time.Second)
+		if errors.Is(err, errDeviceActionTimeout) {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "MDM device action still pending",
+				Detail:   fmt.Sprintf("The %s action on device %s did not complete within %d seconds. It stays queued on the device and its status is refreshed on the next plan.", action.ActionType, action.DeviceID, timeout),
+			})
+		} else if err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
-	return resourceMDMDeviceActionRead(ctx, d, meta)
+	return append(diags, resourceMDMDeviceActionRead(ctx, d, meta)...)
 }
 
 func resourceMDMDeviceActionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -195,7 +199,7 @@ func resourceMDMDeviceActionRead(ctx context.Context, d *schema.ResourceData, me
 	tflog.Debug(ctx, fmt.Sprintf("Reading MDM device action with ID: %s for device: %s", id, deviceID))
 	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/mdm/devices/%s/actions/%s", deviceID, id), nil)
 	if err != nil {
-		if err.Error() == "404 Not Found" {
+		if common.IsNotFoundError(err) {
 			tflog.Warn(ctx, fmt.Sprintf("MDM device action %s not found, removing from state", id))
 			d.SetId("")
 			return diags
@@ -245,10 +249,9 @@ func resourceMDMDeviceActionRead(ctx context.Context, d *schema.ResourceData, me
 	return diags
 }
 
-// resourceMDMDeviceActionUpdate is a no-op as all fields are ForceNew,
-// but it's required by Terraform's validation
+// resourceMDMDeviceActionUpdate is a no-op: every field that affects the action is ForceNew, only
+// the wait timeout can change in place
 func resourceMDMDeviceActionUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	// All fields are ForceNew, so Update is a no-op
 	return resourceMDMDeviceActionRead(ctx, d, meta)
 }
 
@@ -35,6 +35,7 @@ func ResourceProfile() *schema.Resource {
 		ReadContext:   resourceMDMProfileRead,
 		UpdateContext: resourceMDMProfileUpdate,
 		DeleteContext: resourceMDMProfileDelete,
+		CustomizeDiff: customizeProfilePayloadDiff,
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:     schema.TypeString,
@@ -69,9 +70,22 @@ func ResourceProfile() *schema.Resource {
 			},
 			"payload": {
 				Type:             schema.TypeString,
-				Required:         true,
-				Description:      "JSON string of profile payload",
-				DiffSuppressFunc: suppressEquivalentJSONDiffs,
+				Optional:         true,
+				Computed:         true,
+				ExactlyOneOf:     []string{"payload", "payload_file"},
+				Description:      "Profile payload: an Apple configuration profile (.mobileconfig XML) or a JSON string",
+				DiffSuppressFunc: suppressEquivalentProfilePayloadDiffs,
+			},
+			"payload_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a .mobileconfig file (XML or binary plist) used as payload",
+			},
+			"stable_uuids": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Replace the PayloadUUID values of the profile with UUIDs derived from their PayloadIdentifier",
 			},
 			"scope_type": {
 				Type:         schema.TypeString,
@@ -135,15 +149,15 @@ func resourceMDMProfileCreate(ctx context.Context, d *schema.ResourceData, meta
 		profile.Description = v.(string)
 	}
 
-	// Process payload (JSON string)
+	// Process payload (configuration profile or JSON string)
 	if v, ok := d.GetOk("payload"); ok {
-		payloadJSON := []byte(v.(string))
-		// Validate JSON
-		var payloadMap map[string]interface{}
-		if err := json.Unmarshal(payloadJSON, &payloadMap); err != nil {
-			return diag.FromErr(fmt.Errorf("invalid JSON in payload: %v", err))
+		normalized, err := normalizeProfilePayload(v.(string), d.Get("stable_uuids").(bool))
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("invalid payload: %v", err))
+		}
+		if profile.Payload, err = expandProfilePayload(normalized); err != nil {
+			return diag.FromErr(fmt.Errorf("error serializing payload: %v", err))
 		}
-		profile.Payload = payloadJSON
 	}
 
 	// Process scope IDs
@@ -236,11 +250,11 @@ func resourceMDMProfileRead(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.FromErr(fmt.Errorf("error setting payload_type: %v", err))
 	}
 
-	// Format payload as JSON string
+	// Format payload as canonical XML or JSON string
 	if profile.Payload != nil {
-		payloadStr, err := normalizeJSONString(string(profile.Payload))
+		payloadStr, err := flattenProfilePayload(profile.Payload)
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("error normalizing payload JSON: %v", err))
+			return diag.FromErr(fmt.Errorf("error normalizing payload: %v", err))
 		}
 		if err := d.Set("payload", payloadStr); err != nil {
 			return diag.FromErr(fmt.Errorf("error setting payload: %v", err))
@@ -308,15 +322,15 @@ func resourceMDMProfileUpdate(ctx context.Context, d *schema.ResourceData, meta
 		profile.Description = v.(string)
 	}
 
-	// Process payload (JSON string)
+	// Process payload (configuration profile or JSON string)
 	if v, ok := d.GetOk("payload"); ok {
-		payloadJSON := []byte(v.(string))
-		// Validate JSON
-		var payloadMap map[string]interface{}
-		if err := json.Unmarshal(payloadJSON, &payloadMap); err != nil {
-			return diag.FromErr(fmt.Errorf("invalid JSON in payload: %v", err))
+		normalized, err := normalizeProfilePayload(v.(string), d.Get("stable_uuids").(bool))
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("invalid payload: %v", err))
+		}
+		if profile.Payload, err = expandProfilePayload(normalized); err != nil {
+			return diag.FromErr(fmt.Errorf("error serializing payload: %v", err))
 		}
-		profile.Payload = payloadJSON
 	}
 
 	// Process scope IDs
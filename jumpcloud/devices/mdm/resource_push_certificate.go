@@ -0,0 +1,206 @@
+package mdm
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// ResourcePushCertificate returns the schema resource for the APNs push certificate of the Apple MDM
+// server. The first apply exposes the CSR to upload on the Apple Push Certificates Portal, the signed
+// certificate is then set in certificate and uploaded on the next apply
+func ResourcePushCertificate() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMDMPushCertificateCreate,
+		ReadContext:   resourceMDMPushCertificateRead,
+		UpdateContext: resourceMDMPushCertificateUpdate,
+		DeleteContext: resourceMDMPushCertificateDelete,
+		CustomizeDiff: customizePushCertificateDiff,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"apple_mdm_id": appleMDMIDSchema(),
+			"apple_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				RequiredWith: []string{"certificate"},
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Apple ID used on the Apple Push Certificates Portal, renewals must use the same Apple ID",
+			},
+			"certificate": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validatePushCertificate,
+				Description:      "PEM encoded push certificate signed by Apple",
+			},
+			"expiry_warning_days": expiryWarningDaysSchema(),
+			"csr": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Certificate signing request to upload on the Apple Push Certificates Portal",
+			},
+			"expires_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Expiry date of the push certificate (RFC3339)",
+			},
+			"push_topic": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "APNs topic of the push certificate, renewed certificates must keep it",
+			},
+			"serial_number": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Serial number of the push certificate",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+// uploadPushCertificate uploads the signed certificate with the Apple ID that created it. topic is
+// the current push topic of the Apple MDM server, empty before its first certificate
+func uploadPushCertificate(ctx context.Context, d *schema.ResourceData, c interface {
+	DoRequest(method, path string, body []byte) ([]byte, error)
+}, topic string) error {
+	certificate := d.Get("certificate").(string)
+	if certificate == "" {
+		return nil
+	}
+
+	// A renewal must keep the topic, otherwise every enrolled device stops answering
+	if topic != "" {
+		cert, err := parsePushCertificate(certificate)
+		if err != nil {
+			return err
+		}
+		if uid := pushCertificateTopic(cert); uid != "" && uid != topic {
+			return fmt.Errorf("the certificate topic %s does not match the current topic %s, renew the existing certificate instead of creating a new one", uid, topic)
+		}
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Uploading push certificate to Apple MDM server %s", d.Id()))
+	return updateAppleMDM(c, d.Id(), AppleMDMUpdate{
+		AppleSignedCert:         base64.StdEncoding.EncodeToString([]byte(certificate)),
+		AppleCertCreatorAppleID: d.Get("apple_id").(string),
+	})
+}
+
+func resourceMDMPushCertificateCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(interface {
+		DoRequest(method, path string, body []byte) ([]byte, error)
+	})
+
+	id, err := resolveAppleMDMID(c, d.Get("apple_mdm_id").(string))
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error finding Apple MDM server: %v", err))
+	}
+	d.SetId(id)
+
+	// The server usually has a certificate already, so the upload is checked against its topic
+	server, err := getAppleMDM(c, id)
+	if err != nil {
+		d.SetId("")
+		return diag.FromErr(fmt.Errorf("error reading Apple MDM server: %v", err))
+	}
+
+	if err := uploadPushCertificate(ctx, d, c, server.APNSPushTopic); err != nil {
+		d.SetId("")
+		return diag.FromErr(fmt.Errorf("error uploading push certificate: %v", err))
+	}
+
+	return resourceMDMPushCertificateRead(ctx, d, meta)
+}
+
+func resourceMDMPushCertificateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c := meta.(interface {
+		DoRequest(method, path string, body []byte) ([]byte, error)
+	})
+
+	id := d.Id()
+	tflog.Debug(ctx, fmt.Sprintf("Reading push certificate of Apple MDM server %s", id))
+	server, err := getAppleMDM(c, id)
+	if err != nil {
+		if errors.Is(err, errAppleMDMNotFound) {
+			tflog.Warn(ctx, fmt.Sprintf("Apple MDM server %s not found, removing from state", id))
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(fmt.Errorf("error reading Apple MDM server: %v", err))
+	}
+
+	if err := d.Set("apple_mdm_id", server.ID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting apple_mdm_id: %v", err))
+	}
+
+	if server.AppleCertCreatorAppleID != "" {
+		if err := d.Set("apple_id", server.AppleCertCreatorAppleID); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting apple_id: %v", err))
+		}
+	}
+
+	if err := d.Set("expires_at", server.APNSCertExpiry); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting expires_at: %v", err))
+	}
+
+	if err := d.Set("push_topic", server.APNSPushTopic); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting push_topic: %v", err))
+	}
+
+	if err := d.Set("serial_number", server.AppleCertSerialNumber); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting serial_number: %v", err))
+	}
+
+	// The CSR is only downloaded once, every download signs a new one
+	if d.Get("csr").(string) == "" {
+		csr, err := getAppleMDMFile(c, id, "csr")
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error downloading push certificate CSR: %v", err))
+		}
+		if err := d.Set("csr", csr); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting csr: %v", err))
+		}
+	}
+
+	return append(diags, expiryWarning("APNs push certificate", server.APNSCertExpiry, d.Get("expiry_warning_days").(int), time.Now())...)
+}
+
+func resourceMDMPushCertificateUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(interface {
+		DoRequest(method, path string, body []byte) ([]byte, error)
+	})
+
+	if d.HasChanges("certificate", "apple_id") {
+		if err := uploadPushCertificate(ctx, d, c, d.Get("push_topic").(string)); err != nil {
+			if common.IsNotFoundError(err) {
+				return diag.FromErr(fmt.Errorf("Apple MDM server %s not found", d.Id()))
+			}
+			return diag.FromErr(fmt.Errorf("error uploading push certificate: %v", err))
+		}
+	}
+
+	return resourceMDMPushCertificateRead(ctx, d, meta)
+}
+
+func resourceMDMPushCertificateDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Removing the push certificate would disconnect every enrolled device, so we just remove from state
+	tflog.Warn(ctx, fmt.Sprintf("The push certificate of Apple MDM server %s is kept in JumpCloud, removing from state only", d.Id()))
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,212 @@
+package software_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// SoftwareAppStatus represents the install status of a software app on a device
+type SoftwareAppStatus struct {
+	ID       string `json:"id"`
+	SystemID string `json:"systemId"`
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Version  string `json:"version"`
+	Code     int    `json:"code"`
+	Details  string `json:"details"`
+}
+
+// Install states of a software app, compared case-insensitively
+var (
+	softwareAppInstalledStates = map[string]bool{"installed": true, "success": true, "succeeded": true, "completed": true, "uninstalled": true}
+	softwareAppFailedStates    = map[string]bool{"failed": true, "error": true}
+)
+
+// DataSourceSoftwareAppStatuses returns a data source reporting the install status of a software app
+// on each device
+func DataSourceSoftwareAppStatuses() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSoftwareAppStatusesRead,
+		Schema: map[string]*schema.Schema{
+			"software_app_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the software app",
+			},
+			"system_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only report the status on this device",
+			},
+			"statuses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Install status of the app on each device",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"system_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the device",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the app as reported by the device",
+						},
+						"state": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Install state reported by the device",
+						},
+						"version": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Installed version",
+						},
+						"code": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Exit code of the last install attempt",
+						},
+						"details": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Details of the last install attempt",
+						},
+					},
+				},
+			},
+			"installed_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of devices where the app reached its desired state",
+			},
+			"failed_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of devices where the install failed",
+			},
+			"pending_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of devices where the install has not finished",
+			},
+			"failed_system_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the devices where the install failed",
+			},
+		},
+	}
+}
+
+// listSoftwareAppStatuses returns every install status of the app
+func listSoftwareAppStatuses(client common.ClientInterface, id string) ([]SoftwareAppStatus, error) {
+	var statuses []SoftwareAppStatus
+	for skip := 0; ; skip += 100 {
+		resp, err := client.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/softwareapps/%s/statuses?limit=100&skip=%d", id, skip), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []SoftwareAppStatus
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing software app statuses response: %v", err)
+		}
+		statuses = append(statuses, page...)
+		if len(page) < 100 {
+			return statuses, nil
+		}
+	}
+}
+
+// countSoftwareAppStatuses counts the devices by outcome and returns the sorted failed devices
+func countSoftwareAppStatuses(statuses []SoftwareAppStatus) (installed, failed, pending int, failedSystemIDs []string) {
+	failedSystemIDs = make([]string, 0)
+	for _, status := range statuses {
+		state := strings.ToLower(status.State)
+		switch {
+		case softwareAppInstalledStates[state]:
+			installed++
+		case softwareAppFailedStates[state]:
+			failed++
+			failedSystemIDs = append(failedSystemIDs, status.SystemID)
+		default:
+			pending++
+		}
+	}
+	sort.Strings(failedSystemIDs)
+	return installed, failed, pending, failedSystemIDs
+}
+
+func dataSourceSoftwareAppStatusesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := common.GetClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	id := d.Get("software_app_id").(string)
+
+	tflog.Debug(ctx, fmt.Sprintf("Getting install statuses of software app %s", id))
+	statuses, err := listSoftwareAppStatuses(client, id)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error getting software app statuses: %v", err))
+	}
+
+	if systemID := d.Get("system_id").(string); systemID != "" {
+		filtered := make([]SoftwareAppStatus, 0, 1)
+		for _, status := range statuses {
+			if status.SystemID == systemID {
+				filtered = append(filtered, status)
+			}
+		}
+		statuses = filtered
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].SystemID < statuses[j].SystemID })
+	items := make([]interface{}, 0, len(statuses))
+	for _, status := range statuses {
+		items = append(items, map[string]interface{}{
+			"system_id": status.SystemID,
+			"name":      status.Name,
+			"state":     status.State,
+			"version":   status.Version,
+			"code":      status.Code,
+			"details":   status.Details,
+		})
+	}
+	if err := d.Set("statuses", items); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting statuses: %v", err))
+	}
+
+	installed, failed, pending, failedSystemIDs := countSoftwareAppStatuses(statuses)
+	if err := d.Set("installed_count", installed); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting installed_count: %v", err))
+	}
+	if err := d.Set("failed_count", failed); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting failed_count: %v", err))
+	}
+	if err := d.Set("pending_count", pending); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting pending_count: %v", err))
+	}
+	if err := d.Set("failed_system_ids", failedSystemIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting failed_system_ids: %v", err))
+	}
+
+	d.SetId(fmt.Sprintf("software-app-statuses-%s-%d", id, time.Now().Unix()))
+
+	return diags
+}
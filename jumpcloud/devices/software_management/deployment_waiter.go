@@ -0,0 +1,177 @@
+package software_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// Statuses of a deployment target
+var (
+	deploymentTargetSucceededStatuses = map[string]bool{"completed": true, "succeeded": true, "success": true, "installed": true}
+	deploymentTargetFailedStatuses    = map[string]bool{"failed": true, "error": true, "cancelled": true, "canceled": true}
+)
+
+// deploymentFinishedStatuses lists the statuses of a deployment that will not change anymore
+var deploymentFinishedStatuses = map[string]bool{"completed": true, "failed": true, "cancelled": true, "canceled": true}
+
+// maxDeploymentFailuresReported bounds the per-device failure summary
+const maxDeploymentFailuresReported = 10
+
+// errDeploymentWaitTimeout is returned when the deployment is still running at the end of the wait
+var errDeploymentWaitTimeout = errors.New("timeout waiting for software deployment to complete")
+
+// deploymentWaitOptions holds the rollout thresholds of the waiter
+type deploymentWaitOptions struct {
+	Interval          time.Duration
+	MinSuccessPercent float64
+	MaxFailurePercent float64
+}
+
+// deploymentRollout counts the targets of a deployment by outcome
+type deploymentRollout struct {
+	Total     int
+	Succeeded int
+	Failed    []DeploymentStatusItem
+}
+
+// SuccessPercent returns the share of targets where the software installed
+func (r deploymentRollout) SuccessPercent() float64 {
+	if r.Total == 0 {
+		return 100
+	}
+	return float64(r.Succeeded) * 100 / float64(r.Total)
+}
+
+// FailurePercent returns the share of targets where the installation failed
+func (r deploymentRollout) FailurePercent() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(len(r.Failed)) * 100 / float64(r.Total)
+}
+
+// Pending returns the number of targets without outcome yet
+func (r deploymentRollout) Pending() int {
+	return r.Total - r.Succeeded - len(r.Failed)
+}
+
+// deploymentThresholdError reports a rollout that breached a threshold, with the failed devices
+type deploymentThresholdError struct {
+	Reason  string
+	Rollout deploymentRollout
+}
+
+func (e *deploymentThresholdError) Error() string {
+	r := e.Rollout
+	msg := fmt.Sprintf("%s: %d succeeded, %d failed and %d pending of %d targets", e.Reason, r.Succeeded, len(r.Failed), r.Pending(), r.Total)
+	if summary := summarizeDeploymentFailures(r.Failed); summary != "" {
+		msg += "\nFailed targets:\n" + summary
+	}
+	return msg
+}
+
+// summarizeDeploymentFailures lists the failed targets, one per line
+func summarizeDeploymentFailures(failed []DeploymentStatusItem) string {
+	sorted := append([]DeploymentStatusItem{}, failed...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TargetID < sorted[j].TargetID })
+
+	var lines []string
+	for i, target := range sorted {
+		if i == maxDeploymentFailuresReported {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(sorted)-i))
+			break
+		}
+		name := target.TargetID
+		if target.TargetName != "" {
+			name = fmt.Sprintf("%s (%s)", target.TargetName, target.TargetID)
+		}
+		lines = append(lines, fmt.Sprintf("  - %s: %s", name, target.Status))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// summarizeDeploymentRollout counts the outcome of each target
+func summarizeDeploymentRollout(status *DeploymentStatusResponse) deploymentRollout {
+	rollout := deploymentRollout{Total: len(status.Targets)}
+	for _, target := range status.Targets {
+		switch {
+		case deploymentTargetSucceededStatuses[target.Status]:
+			rollout.Succeeded++
+		case deploymentTargetFailedStatuses[target.Status]:
+			rollout.Failed = append(rollout.Failed, target)
+		}
+	}
+	return rollout
+}
+
+// checkDeploymentRollout returns a deploymentThresholdError when the failures exceed the maximum, or
+// when the finished deployment did not reach the minimum success rate
+func checkDeploymentRollout(rollout deploymentRollout, finished bool, opts deploymentWaitOptions) error {
+	if rollout.FailurePercent() > opts.MaxFailurePercent {
+		return &deploymentThresholdError{
+			Reason:  fmt.Sprintf("failure rate of %.1f%% above the maximum of %g%%", rollout.FailurePercent(), opts.MaxFailurePercent),
+			Rollout: rollout,
+		}
+	}
+	if finished && rollout.SuccessPercent() < opts.MinSuccessPercent {
+		return &deploymentThresholdError{
+			Reason:  fmt.Sprintf("success rate of %.1f%% below the minimum of %g%%", rollout.SuccessPercent(), opts.MinSuccessPercent),
+			Rollout: rollout,
+		}
+	}
+	return nil
+}
+
+// getDeploymentStatus reads the per-target status of a deployment
+func getDeploymentStatus(client common.ClientInterface, id string) (*DeploymentStatusResponse, error) {
+	resp, err := client.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/software/deployments/%s/status", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var status DeploymentStatusResponse
+	if err := json.Unmarshal(resp, &status); err != nil {
+		return nil, fmt.Errorf("error parsing deployment status response: %v", err)
+	}
+	return &status, nil
+}
+
+// waitForSoftwareDeployment polls the deployment status until every target reports or the deployment
+// finishes. It stops early with a deploymentThresholdError as soon as the failures exceed the maximum,
+// and returns the last rollout with errDeploymentWaitTimeout when the context expires
+func waitForSoftwareDeployment(ctx context.Context, client common.ClientInterface, id string, opts deploymentWaitOptions) (deploymentRollout, error) {
+	var rollout deploymentRollout
+	for {
+		status, err := getDeploymentStatus(client, id)
+		if err != nil {
+			return rollout, fmt.Errorf("error getting deployment status: %v", err)
+		}
+		rollout = summarizeDeploymentRollout(status)
+		finished := deploymentFinishedStatuses[status.Status] || (rollout.Total > 0 && rollout.Pending() == 0)
+
+		if err := checkDeploymentRollout(rollout, finished, opts); err != nil {
+			return rollout, err
+		}
+		if finished {
+			return rollout, nil
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("Software deployment %s is %s: %d succeeded, %d failed, %d pending",
+			id, status.Status, rollout.Succeeded, len(rollout.Failed), rollout.Pending()))
+		select {
+		case <-ctx.Done():
+			return rollout, errDeploymentWaitTimeout
+		case <-time.After(opts.Interval):
+		}
+	}
+}
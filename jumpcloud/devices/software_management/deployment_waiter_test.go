@@ -0,0 +1,112 @@
+package software_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// deploymentTestClient returns the configured status responses in sequence, repeating the last one
+type deploymentTestClient struct {
+	statuses []DeploymentStatusResponse
+	polls    int
+}
+
+func (c *deploymentTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	if method != "GET" || path != "/api/v2/software/deployments/deployment/status" {
+		return nil, fmt.Errorf("unexpected request %s %s", method, path)
+	}
+	status := c.statuses[len(c.statuses)-1]
+	if c.polls < len(c.statuses) {
+		status = c.statuses[c.polls]
+	}
+	c.polls++
+	return json.Marshal(status)
+}
+
+func (c *deploymentTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *deploymentTestClient) GetApiKey() string { return "test" }
+
+func (c *deploymentTestClient) GetOrgID() string { return "" }
+
+// testDeploymentStatus builds a status response with one target per status
+func testDeploymentStatus(status string, targets ...string) DeploymentStatusResponse {
+	resp := DeploymentStatusResponse{DeploymentID: "deployment", Status: status}
+	for i, s := range targets {
+		resp.Targets = append(resp.Targets, DeploymentStatusItem{TargetID: fmt.Sprintf("system%02d", i), TargetName: fmt.Sprintf("host-%02d", i), Status: s})
+	}
+	return resp
+}
+
+func TestWaitForSoftwareDeployment(t *testing.T) {
+	opts := deploymentWaitOptions{Interval: time.Millisecond, MinSuccessPercent: 75, MaxFailurePercent: 100}
+
+	client := &deploymentTestClient{statuses: []DeploymentStatusResponse{
+		testDeploymentStatus("in_progress", "pending", "pending", "pending", "pending"),
+		testDeploymentStatus("in_progress", "completed", "installing", "failed", "completed"),
+		testDeploymentStatus("in_progress", "completed", "completed", "failed", "completed"),
+	}}
+	rollout, err := waitForSoftwareDeployment(context.Background(), client, "deployment", opts)
+	if err != nil || rollout.Succeeded != 3 || client.polls != 3 {
+		t.Errorf("expected 3 successes after 3 polls, got %+v after %d: %v", rollout, client.polls, err)
+	}
+
+	// Below the minimum success rate once every target reported
+	opts.MinSuccessPercent = 100
+	client = &deploymentTestClient{statuses: []DeploymentStatusResponse{
+		testDeploymentStatus("completed", "completed", "completed", "failed", "completed"),
+	}}
+	_, err = waitForSoftwareDeployment(context.Background(), client, "deployment", opts)
+	var thresholdErr *deploymentThresholdError
+	if !errors.As(err, &thresholdErr) || !strings.Contains(err.Error(), "below the minimum of 100%") {
+		t.Errorf("expected a success threshold error, got %v", err)
+	}
+
+	// Above the maximum failure rate while other targets are still pending
+	opts.MaxFailurePercent = 25
+	client = &deploymentTestClient{statuses: []DeploymentStatusResponse{
+		testDeploymentStatus("in_progress", "failed", "error", "pending", "pending"),
+	}}
+	rollout, err = waitForSoftwareDeployment(context.Background(), client, "deployment", opts)
+	if !errors.As(err, &thresholdErr) || client.polls != 1 || rollout.Pending() != 2 {
+		t.Errorf("expected an early failure threshold error, got %v after %d polls", err, client.polls)
+	}
+
+	client = &deploymentTestClient{statuses: []DeploymentStatusResponse{testDeploymentStatus("in_progress", "pending")}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := waitForSoftwareDeployment(ctx, client, "deployment", opts); !errors.Is(err, errDeploymentWaitTimeout) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+}
+
+func TestDeploymentThresholdErrorSummary(t *testing.T) {
+	statuses := make([]string, 0, 15)
+	for i := 0; i < 12; i++ {
+		statuses = append(statuses, "failed")
+	}
+	statuses = append(statuses, "completed", "completed", "pending")
+
+	rollout := summarizeDeploymentRollout(&DeploymentStatusResponse{Targets: testDeploymentStatus("in_progress", statuses...).Targets})
+	err := checkDeploymentRollout(rollout, false, deploymentWaitOptions{MinSuccessPercent: 100, MaxFailurePercent: 50})
+	if err == nil {
+		t.Fatal("expected a threshold error")
+	}
+
+	msg := err.Error()
+	for _, want := range []string{"failure rate of 80.0% above the maximum of 50%", "2 succeeded, 12 failed and 1 pending of 15 targets", "host-00 (system00): failed", "... and 2 more"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected %q in the error, got:\n%s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "system11") {
+		t.Errorf("expected the summary to stop after %d targets, got:\n%s", maxDeploymentFailuresReported, msg)
+	}
+}
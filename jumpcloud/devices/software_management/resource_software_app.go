@@ -0,0 +1,302 @@
+package software_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// ResourceSoftwareApp returns a schema resource for managing software apps of JumpCloud Software
+// Management and their device group associations
+func ResourceSoftwareApp() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"display_name": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringLenBetween(1, 255),
+			Description:  "Name of the software app",
+		},
+		"description": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Description of the software app",
+		},
+		"desired_state": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "install",
+			ValidateFunc: validation.StringInSlice([]string{"install", "uninstall"}, false),
+			Description:  "Whether the app is installed or uninstalled on the devices (install, uninstall)",
+		},
+		"auto_update": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Keep the app updated to the latest version",
+		},
+		"group_ids": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "IDs of the device groups the app is installed on",
+		},
+	}
+	for name, block := range softwareAppSourceSchemas() {
+		s[name] = block
+	}
+
+	return &schema.Resource{
+		CreateContext: resourceSoftwareAppCreate,
+		ReadContext:   resourceSoftwareAppRead,
+		UpdateContext: resourceSoftwareAppUpdate,
+		DeleteContext: resourceSoftwareAppDelete,
+		CustomizeDiff: customizeSoftwareAppDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: s,
+	}
+}
+
+// expandSoftwareApp builds the software app from the resource data
+func expandSoftwareApp(d *schema.ResourceData) SoftwareApp {
+	blocks := make(map[string]interface{}, len(softwareAppSources))
+	for _, name := range softwareAppSourceNames() {
+		blocks[name] = d.Get(name)
+	}
+
+	return SoftwareApp{
+		DisplayName: d.Get("display_name").(string),
+		Settings: []SoftwareAppSettings{
+			expandSoftwareAppSettings(blocks, d.Get("desired_state").(string), d.Get("auto_update").(bool), d.Get("description").(string)),
+		},
+	}
+}
+
+// listSoftwareAppGroups returns the sorted IDs of the device groups associated with the app
+func listSoftwareAppGroups(client common.ClientInterface, id string) ([]string, error) {
+	var groupIDs []string
+	for skip := 0; ; skip += 100 {
+		resp, err := client.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/softwareapps/%s/associations?targets=system_group&limit=100&skip=%d", id, skip), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var associations []struct {
+			To struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			} `json:"to"`
+		}
+		if err := json.Unmarshal(resp, &associations); err != nil {
+			return nil, fmt.Errorf("error parsing software app associations response: %v", err)
+		}
+		for _, association := range associations {
+			if association.To.Type == "system_group" {
+				groupIDs = append(groupIDs, association.To.ID)
+			}
+		}
+		if len(associations) < 100 {
+			break
+		}
+	}
+
+	sort.Strings(groupIDs)
+	return groupIDs, nil
+}
+
+// setSoftwareAppGroup adds or removes the association of the app with a device group
+func setSoftwareAppGroup(client common.ClientInterface, id, op, groupID string) error {
+	reqBody, err := json.Marshal(map[string]string{"op": op, "type": "system_group", "id": groupID})
+	if err != nil {
+		return fmt.Errorf("error serializing association request: %v", err)
+	}
+	_, err = client.DoRequest(http.MethodPost, fmt.Sprintf("/api/v2/softwareapps/%s/associations", id), reqBody)
+	return err
+}
+
+// syncSoftwareAppGroups associates the app with the new groups and removes the old ones
+func syncSoftwareAppGroups(client common.ClientInterface, id string, oldGroups, newGroups *schema.Set) error {
+	for _, groupID := range oldGroups.Difference(newGroups).List() {
+		if err := setSoftwareAppGroup(client, id, "remove", groupID.(string)); err != nil {
+			return fmt.Errorf("error removing device group %s: %v", groupID, err)
+		}
+	}
+	for _, groupID := range newGroups.Difference(oldGroups).List() {
+		if err := setSoftwareAppGroup(client, id, "add", groupID.(string)); err != nil {
+			return fmt.Errorf("error adding device group %s: %v", groupID, err)
+		}
+	}
+	return nil
+}
+
+func resourceSoftwareAppCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := common.GetClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	reqBody, err := json.Marshal(expandSoftwareApp(d))
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing software app: %v", err))
+	}
+
+	resp, err := client.DoRequest(http.MethodPost, "/api/v2/softwareapps", reqBody)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error creating software app: %v", err))
+	}
+
+	var created SoftwareApp
+	if err := json.Unmarshal(resp, &created); err != nil {
+		return diag.FromErr(fmt.Errorf("error deserializing software app response: %v", err))
+	}
+	if created.ID == "" {
+		return diag.FromErr(fmt.Errorf("software app created without ID"))
+	}
+
+	d.SetId(created.ID)
+	tflog.Trace(ctx, "Created software app", map[string]interface{}{
+		"id": d.Id(),
+	})
+
+	if v, ok := d.GetOk("group_ids"); ok {
+		if err := syncSoftwareAppGroups(client, d.Id(), schema.NewSet(schema.HashString, nil), v.(*schema.Set)); err != nil {
+			return append(diag.FromErr(fmt.Errorf("error associating software app %s: %v", d.Id(), err)), resourceSoftwareAppRead(ctx, d, meta)...)
+		}
+	}
+
+	return resourceSoftwareAppRead(ctx, d, meta)
+}
+
+func resourceSoftwareAppRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := common.GetClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	id := d.Id()
+
+	resp, err := client.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/softwareapps/%s", id), nil)
+	if err != nil {
+		if common.IsNotFoundError(err) {
+			tflog.Warn(ctx, fmt.Sprintf("Software app %s not found, removing from state", id))
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(fmt.Errorf("error reading software app %s: %v", id, err))
+	}
+
+	var app SoftwareApp
+	if err := json.Unmarshal(resp, &app); err != nil {
+		return diag.FromErr(fmt.Errorf("error deserializing software app response: %v", err))
+	}
+
+	if err := d.Set("display_name", app.DisplayName); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting display_name: %v", err))
+	}
+
+	if len(app.Settings) > 0 {
+		settings := app.Settings[0]
+
+		if err := d.Set("description", settings.Description); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting description: %v", err))
+		}
+
+		if settings.DesiredState != "" {
+			if err := d.Set("desired_state", settings.DesiredState); err != nil {
+				return diag.FromErr(fmt.Errorf("error setting desired_state: %v", err))
+			}
+		}
+
+		if err := d.Set("auto_update", settings.AutoUpdate); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting auto_update: %v", err))
+		}
+
+		name, block := flattenSoftwareAppSettings(settings)
+		if name == "" {
+			tflog.Warn(ctx, fmt.Sprintf("Software app %s uses the unsupported package manager %s", id, settings.PackageManager))
+		}
+		for _, source := range softwareAppSourceNames() {
+			var value []interface{}
+			if source == name {
+				value = block
+			}
+			if err := d.Set(source, value); err != nil {
+				return diag.FromErr(fmt.Errorf("error setting %s: %v", source, err))
+			}
+		}
+	}
+
+	groupIDs, err := listSoftwareAppGroups(client, id)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error reading device groups of software app %s: %v", id, err))
+	}
+	if err := d.Set("group_ids", groupIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting group_ids: %v", err))
+	}
+
+	return diags
+}
+
+func resourceSoftwareAppUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := common.GetClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	id := d.Id()
+
+	if d.HasChangesExcept("group_ids") {
+		reqBody, err := json.Marshal(expandSoftwareApp(d))
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error serializing software app: %v", err))
+		}
+
+		if _, err := client.DoRequest(http.MethodPut, fmt.Sprintf("/api/v2/softwareapps/%s", id), reqBody); err != nil {
+			return diag.FromErr(fmt.Errorf("error updating software app %s: %v", id, err))
+		}
+
+		tflog.Trace(ctx, "Updated software app", map[string]interface{}{
+			"id": id,
+		})
+	}
+
+	if d.HasChange("group_ids") {
+		oldGroups, newGroups := d.GetChange("group_ids")
+		if err := syncSoftwareAppGroups(client, id, oldGroups.(*schema.Set), newGroups.(*schema.Set)); err != nil {
+			return diag.FromErr(fmt.Errorf("error updating device groups of software app %s: %v", id, err))
+		}
+	}
+
+	return resourceSoftwareAppRead(ctx, d, meta)
+}
+
+func resourceSoftwareAppDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	client, diags := common.GetClientFromMeta(meta)
+	if diags.HasError() {
+		return diags
+	}
+
+	id := d.Id()
+
+	_, err := client.DoRequest(http.MethodDelete, fmt.Sprintf("/api/v2/softwareapps/%s", id), nil)
+	if err != nil && !common.IsNotFoundError(err) {
+		return diag.FromErr(fmt.Errorf("error deleting software app %s: %v", id, err))
+	}
+
+	d.SetId("")
+	tflog.Trace(ctx, "Deleted software app", map[string]interface{}{
+		"id": id,
+	})
+
+	return diags
+}
@@ -3,6 +3,7 @@ package software_management
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -121,6 +122,42 @@ func ResourceSoftwareDeployment() *schema.Resource {
 				Computed:    true,
 				Description: "Last update timestamp",
 			},
+			"wait_for_completion": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Wait until every target reports the outcome of the deployment before finishing the apply",
+			},
+			"min_success_percent": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      100.0,
+				ValidateFunc: validation.FloatBetween(0, 100),
+				Description:  "Minimum percentage of targets where the software must install for the apply to succeed",
+			},
+			"max_failure_percent": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      100.0,
+				ValidateFunc: validation.FloatBetween(0, 100),
+				Description:  "Percentage of failed targets above which the apply fails without waiting for the other targets",
+			},
+			"poll_interval": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      30,
+				ValidateFunc: validation.IntBetween(5, 3600),
+				Description:  "Seconds between two checks of the deployment status",
+			},
+			"cancel_on_failure": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Cancel the deployment when a threshold is breached",
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
 		},
 	}
 }
@@ -198,9 +235,52 @@ func resourceSoftwareDeploymentCreate(ctx context.Context, d *schema.ResourceDat
 	// Wait for the deployment to initialize
 	time.Sleep(2 * time.Second)
 
+	if d.Get("wait_for_completion").(bool) {
+		// The ID is already set, so a failed rollout is tainted and deployed again on the next apply
+		if diags := waitForSoftwareDeploymentRollout(ctx, d, client); diags.HasError() {
+			return append(diags, resourceSoftwareDeploymentRead(ctx, d, meta)...)
+		}
+	}
+
 	return resourceSoftwareDeploymentRead(ctx, d, meta)
 }
 
+// waitForSoftwareDeploymentRollout waits for the deployment within the create timeout and enforces
+// the success and failure thresholds, cancelling the deployment on failure when requested
+func waitForSoftwareDeploymentRollout(ctx context.Context, d *schema.ResourceData, client common.ClientInterface) diag.Diagnostics {
+	id := d.Id()
+	opts := deploymentWaitOptions{
+		Interval:          time.Duration(d.Get("poll_interval").(int)) * time.Second,
+		MinSuccessPercent: d.Get("min_success_percent").(float64),
+		MaxFailurePercent: d.Get("max_failure_percent").(float64),
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, d.Timeout(schema.TimeoutCreate))
+	defer cancel()
+
+	rollout, err := waitForSoftwareDeployment(waitCtx, client, id, opts)
+	if err == nil {
+		tflog.Info(ctx, fmt.Sprintf("Software deployment %s completed: %d of %d targets succeeded", id, rollout.Succeeded, rollout.Total))
+		return nil
+	}
+
+	var thresholdErr *deploymentThresholdError
+	if !errors.As(err, &thresholdErr) {
+		if errors.Is(err, errDeploymentWaitTimeout) {
+			return diag.FromErr(fmt.Errorf("%v: %d succeeded, %d failed and %d pending of %d targets",
+				err, rollout.Succeeded, len(rollout.Failed), rollout.Pending(), rollout.Total))
+		}
+		return diag.FromErr(err)
+	}
+
+	diags := diag.FromErr(fmt.Errorf("software deployment %s failed: %v", id, err))
+	if d.Get("cancel_on_failure").(bool) {
+		tflog.Warn(ctx, fmt.Sprintf("Cancelling software deployment %s after a failed rollout", id))
+		diags = append(diags, cancelDeployment(ctx, client, id)...)
+	}
+	return diags
+}
+
 func resourceSoftwareDeploymentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	client, diags := common.GetClientFromMeta(meta)
 	if diags.HasError() {
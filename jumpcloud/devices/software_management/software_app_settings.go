@@ -0,0 +1,262 @@
+package software_management
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// SoftwareApp represents a software app of JumpCloud Software Management
+type SoftwareApp struct {
+	ID          string                `json:"id,omitempty"`
+	DisplayName string                `json:"displayName"`
+	Settings    []SoftwareAppSettings `json:"settings"`
+}
+
+// SoftwareAppSettings holds the package source and the install settings of a software app
+type SoftwareAppSettings struct {
+	PackageID      string               `json:"packageId,omitempty"`
+	PackageManager string               `json:"packageManager"`
+	PackageKind    string               `json:"packageKind,omitempty"`
+	PackageVersion string               `json:"packageVersion,omitempty"`
+	Location       string               `json:"location,omitempty"`
+	DesiredState   string               `json:"desiredState,omitempty"`
+	AutoUpdate     bool                 `json:"autoUpdate"`
+	Description    string               `json:"description,omitempty"`
+	AppleVPP       *SoftwareAppAppleVPP `json:"appleVpp,omitempty"`
+}
+
+// SoftwareAppAppleVPP holds the Apple Volume Purchase Program details of an App Store app
+type SoftwareAppAppleVPP struct {
+	AppConfiguration        string   `json:"appConfiguration,omitempty"`
+	IsConfigEnabled         bool     `json:"isConfigEnabled,omitempty"`
+	SupportedDeviceFamilies []string `json:"supportedDeviceFamilies,omitempty"`
+	TotalLicenses           int      `json:"totalLicenses,omitempty"`
+	AvailableLicenses       int      `json:"availableLicenses,omitempty"`
+	AssignedLicenses        int      `json:"assignedLicenses,omitempty"`
+}
+
+// softwareAppSource describes a package manager block of the software app resource
+type softwareAppSource struct {
+	// PackageManager is the API name of the package manager, empty when it comes from the block
+	PackageManager string
+	// PackageKind is the kind of package the source installs
+	PackageKind string
+}
+
+// softwareAppSources lists the package sources, keyed by block name
+var softwareAppSources = map[string]softwareAppSource{
+	"chocolatey": {PackageManager: "CHOCOLATEY"},
+	"apple_vpp":  {PackageManager: "APPLE_VPP"},
+	"custom_pkg": {PackageManager: "APPLE_CUSTOM", PackageKind: "PKG"},
+	"linux":      {},
+}
+
+// softwareAppAdamIDRegexp matches the numeric App Store ID of an app
+var softwareAppAdamIDRegexp = regexp.MustCompile(`^[0-9]+$`)
+
+// softwareAppLinuxPackageManagers lists the package managers of Linux apps
+var softwareAppLinuxPackageManagers = []string{"apt", "dnf", "yum", "snap"}
+
+// softwareAppSourceNames returns the block names of the package sources
+func softwareAppSourceNames() []string {
+	return []string{"apple_vpp", "chocolatey", "custom_pkg", "linux"}
+}
+
+// softwareAppSourceSchemas returns the package manager blocks, exactly one of them is set
+func softwareAppSourceSchemas() map[string]*schema.Schema {
+	block := func(description string, fields map[string]*schema.Schema) *schema.Schema {
+		return &schema.Schema{
+			Type:         schema.TypeList,
+			Optional:     true,
+			MaxItems:     1,
+			ExactlyOneOf: softwareAppSourceNames(),
+			Description:  description,
+			Elem:         &schema.Resource{Schema: fields},
+		}
+	}
+	version := func() *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Version to install, the latest one when empty",
+		}
+	}
+
+	return map[string]*schema.Schema{
+		"chocolatey": block("Windows package installed with Chocolatey", map[string]*schema.Schema{
+			"package_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "ID of the Chocolatey package",
+			},
+			"version": version(),
+		}),
+		"apple_vpp": block("macOS or iOS app from the App Store, licensed through Apple VPP", map[string]*schema.Schema{
+			"app_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(softwareAppAdamIDRegexp, "must be the numeric App Store ID of the app"),
+				Description:  "App Store ID (adamId) of the app",
+			},
+			"app_configuration": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Managed app configuration (plist XML) sent to the app",
+			},
+			"supported_device_families": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Device families the app supports",
+			},
+			"total_licenses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of VPP licenses purchased",
+			},
+			"available_licenses": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of VPP licenses not assigned yet",
+			},
+		}),
+		"custom_pkg": block("Custom macOS installer package", map[string]*schema.Schema{
+			"url": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsURLWithHTTPS,
+				Description:  "HTTPS URL of the .pkg file",
+			},
+			"version": version(),
+		}),
+		"linux": block("Linux package installed with the package manager of the distribution", map[string]*schema.Schema{
+			"package_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "Name of the package",
+			},
+			"package_manager": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(softwareAppLinuxPackageManagers, false),
+				Description:  "Package manager installing the package: " + strings.Join(softwareAppLinuxPackageManagers, ", "),
+			},
+			"version": version(),
+		}),
+	}
+}
+
+// softwareAppBlock returns the configuration of a block, nil when it is not set
+func softwareAppBlock(raw interface{}) map[string]interface{} {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 || list[0] == nil {
+		return nil
+	}
+	block, _ := list[0].(map[string]interface{})
+	return block
+}
+
+// expandSoftwareAppSettings builds the API settings from the package manager block and the install
+// settings. blocks maps each block name to its configuration
+func expandSoftwareAppSettings(blocks map[string]interface{}, desiredState string, autoUpdate bool, description string) SoftwareAppSettings {
+	settings := SoftwareAppSettings{DesiredState: desiredState, AutoUpdate: autoUpdate, Description: description}
+
+	for _, name := range softwareAppSourceNames() {
+		block := softwareAppBlock(blocks[name])
+		if block == nil {
+			continue
+		}
+		source := softwareAppSources[name]
+		settings.PackageManager = source.PackageManager
+		settings.PackageKind = source.PackageKind
+
+		switch name {
+		case "chocolatey":
+			settings.PackageID = block["package_id"].(string)
+			settings.PackageVersion = block["version"].(string)
+		case "apple_vpp":
+			settings.PackageID = block["app_id"].(string)
+			configuration := block["app_configuration"].(string)
+			settings.AppleVPP = &SoftwareAppAppleVPP{AppConfiguration: configuration, IsConfigEnabled: configuration != ""}
+		case "custom_pkg":
+			settings.Location = block["url"].(string)
+			settings.PackageVersion = block["version"].(string)
+		case "linux":
+			settings.PackageID = block["package_id"].(string)
+			settings.PackageManager = strings.ToUpper(block["package_manager"].(string))
+			settings.PackageVersion = block["version"].(string)
+		}
+	}
+	return settings
+}
+
+// flattenSoftwareAppSettings returns the name and the configuration of the block matching the API
+// settings, an empty name when the package manager is not supported
+func flattenSoftwareAppSettings(settings SoftwareAppSettings) (string, []interface{}) {
+	switch settings.PackageManager {
+	case "CHOCOLATEY":
+		return "chocolatey", []interface{}{map[string]interface{}{
+			"package_id": settings.PackageID,
+			"version":    settings.PackageVersion,
+		}}
+	case "APPLE_VPP":
+		block := map[string]interface{}{"app_id": settings.PackageID}
+		if vpp := settings.AppleVPP; vpp != nil {
+			block["app_configuration"] = vpp.AppConfiguration
+			block["supported_device_families"] = vpp.SupportedDeviceFamilies
+			block["total_licenses"] = vpp.TotalLicenses
+			block["available_licenses"] = vpp.AvailableLicenses
+		}
+		return "apple_vpp", []interface{}{block}
+	case "APPLE_CUSTOM":
+		return "custom_pkg", []interface{}{map[string]interface{}{
+			"url":     settings.Location,
+			"version": settings.PackageVersion,
+		}}
+	}
+
+	if packageManager := strings.ToLower(settings.PackageManager); containsString(softwareAppLinuxPackageManagers, packageManager) {
+		return "linux", []interface{}{map[string]interface{}{
+			"package_id":      settings.PackageID,
+			"package_manager": packageManager,
+			"version":         settings.PackageVersion,
+		}}
+	}
+	return "", nil
+}
+
+// customizeSoftwareAppDiff rejects settings the package source does not support at plan time
+func customizeSoftwareAppDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Get("auto_update").(bool) && d.Get("desired_state").(string) == "uninstall" {
+		return cty.GetAttrPath("auto_update").NewErrorf("auto_update does not apply to an app that is uninstalled")
+	}
+
+	for _, name := range []string{"chocolatey", "custom_pkg", "linux"} {
+		block := softwareAppBlock(d.Get(name))
+		if block != nil && d.Get("auto_update").(bool) && block["version"].(string) != "" {
+			return cty.GetAttrPath(name).IndexInt(0).GetAttr("version").NewErrorf("a pinned version cannot be updated automatically, remove version or auto_update")
+		}
+	}
+	return nil
+}
+
+// containsString reports whether the slice contains the value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
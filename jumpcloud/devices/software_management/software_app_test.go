@@ -0,0 +1,144 @@
+package software_management
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// softwareAppTestClient records the association requests and serves the statuses in pages
+type softwareAppTestClient struct {
+	statuses []SoftwareAppStatus
+	requests []string
+}
+
+func (c *softwareAppTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	switch {
+	case method == "POST" && path == "/api/v2/softwareapps/app/associations":
+		var req map[string]string
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		c.requests = append(c.requests, req["op"]+" "+req["type"]+" "+req["id"])
+		return nil, nil
+	case method == "GET" && strings.HasPrefix(path, "/api/v2/softwareapps/app/statuses?"):
+		var skip int
+		fmt.Sscanf(path[strings.Index(path, "skip=")+5:], "%d", &skip)
+		end := skip + 100
+		if end > len(c.statuses) {
+			end = len(c.statuses)
+		}
+		return json.Marshal(c.statuses[skip:end])
+	}
+	return nil, fmt.Errorf("unexpected request %s %s", method, path)
+}
+
+func (c *softwareAppTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *softwareAppTestClient) GetApiKey() string { return "test" }
+
+func (c *softwareAppTestClient) GetOrgID() string { return "" }
+
+func TestResourceSoftwareAppSchema(t *testing.T) {
+	if err := ResourceSoftwareApp().InternalValidate(nil, true); err != nil {
+		t.Errorf("invalid schema: %v", err)
+	}
+	if err := DataSourceSoftwareAppStatuses().InternalValidate(nil, false); err != nil {
+		t.Errorf("invalid data source schema: %v", err)
+	}
+}
+
+func TestSoftwareAppSettingsRoundTrip(t *testing.T) {
+	cases := []struct {
+		name     string
+		block    map[string]interface{}
+		settings SoftwareAppSettings
+	}{
+		{
+			"chocolatey",
+			map[string]interface{}{"package_id": "googlechrome", "version": ""},
+			SoftwareAppSettings{PackageManager: "CHOCOLATEY", PackageID: "googlechrome", DesiredState: "install", AutoUpdate: true},
+		},
+		{
+			"apple_vpp",
+			map[string]interface{}{"app_id": "409183694", "app_configuration": "<dict/>"},
+			SoftwareAppSettings{PackageManager: "APPLE_VPP", PackageID: "409183694", DesiredState: "install", AutoUpdate: true,
+				AppleVPP: &SoftwareAppAppleVPP{AppConfiguration: "<dict/>", IsConfigEnabled: true}},
+		},
+		{
+			"custom_pkg",
+			map[string]interface{}{"url": "https://example.com/agent.pkg", "version": ""},
+			SoftwareAppSettings{PackageManager: "APPLE_CUSTOM", PackageKind: "PKG", Location: "https://example.com/agent.pkg", DesiredState: "install", AutoUpdate: true},
+		},
+		{
+			"linux",
+			map[string]interface{}{"package_id": "htop", "package_manager": "apt", "version": ""},
+			SoftwareAppSettings{PackageManager: "APT", PackageID: "htop", DesiredState: "install", AutoUpdate: true},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			blocks := map[string]interface{}{tc.name: []interface{}{tc.block}}
+			settings := expandSoftwareAppSettings(blocks, "install", true, "")
+			if !reflect.DeepEqual(settings, tc.settings) {
+				t.Fatalf("expected %+v, got %+v", tc.settings, settings)
+			}
+
+			name, flattened := flattenSoftwareAppSettings(settings)
+			if name != tc.name {
+				t.Fatalf("expected the %s block, got %q", tc.name, name)
+			}
+			for key, want := range tc.block {
+				if got := flattened[0].(map[string]interface{})[key]; got != want {
+					t.Errorf("%s: expected %v, got %v", key, want, got)
+				}
+			}
+		})
+	}
+
+	if name, _ := flattenSoftwareAppSettings(SoftwareAppSettings{PackageManager: "MICROSOFT_STORE"}); name != "" {
+		t.Errorf("expected no block for an unsupported package manager, got %s", name)
+	}
+}
+
+func TestSyncSoftwareAppGroups(t *testing.T) {
+	client := &softwareAppTestClient{}
+	oldGroups := schema.NewSet(schema.HashString, []interface{}{"kept", "removed"})
+	newGroups := schema.NewSet(schema.HashString, []interface{}{"kept", "added"})
+
+	if err := syncSoftwareAppGroups(client, "app", oldGroups, newGroups); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"remove system_group removed", "add system_group added"}
+	if !reflect.DeepEqual(client.requests, want) {
+		t.Errorf("expected %v, got %v", want, client.requests)
+	}
+}
+
+func TestSoftwareAppStatuses(t *testing.T) {
+	client := &softwareAppTestClient{}
+	states := []string{"INSTALLED", "FAILED", "PENDING", "installed", "error"}
+	for i := 0; i < 205; i++ {
+		client.statuses = append(client.statuses, SoftwareAppStatus{SystemID: fmt.Sprintf("system%03d", i), State: states[i%len(states)]})
+	}
+
+	statuses, err := listSoftwareAppStatuses(client, "app")
+	if err != nil || len(statuses) != 205 {
+		t.Fatalf("expected 205 statuses over 3 pages, got %d: %v", len(statuses), err)
+	}
+
+	installed, failed, pending, failedSystemIDs := countSoftwareAppStatuses(statuses)
+	if installed != 82 || failed != 82 || pending != 41 {
+		t.Errorf("expected 82 installed, 82 failed and 41 pending, got %d, %d and %d", installed, failed, pending)
+	}
+	if len(failedSystemIDs) != 82 || failedSystemIDs[0] != "system001" {
+		t.Errorf("expected the sorted failed devices, got %v", failedSystemIDs[:2])
+	}
+}
@@ -0,0 +1,463 @@
+package devices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// deviceListFields maps the filterable fields to their names in the systems search API
+var deviceListFields = map[string]string{
+	"os":            "os",
+	"os_family":     "osFamily",
+	"version":       "version",
+	"hostname":      "hostname",
+	"display_name":  "displayName",
+	"serial_number": "serialNumber",
+	"last_contact":  "lastContact",
+	"active":        "active",
+	"mdm_managed":   "mdm",
+}
+
+// deviceListSearchFields are the system fields requested from the search API
+const deviceListSearchFields = "_id displayName hostname os osFamily version serialNumber lastContact active agentVersion mdm"
+
+var relativeAgeRegex = regexp.MustCompile(`^(\d+)([dh])$`)
+
+// deviceListEntry is the compact view of a system returned by the systems search API
+type deviceListEntry struct {
+	ID           string                 `json:"_id"`
+	DisplayName  string                 `json:"displayName"`
+	Hostname     string                 `json:"hostname"`
+	OS           string                 `json:"os"`
+	OSFamily     string                 `json:"osFamily"`
+	Version      string                 `json:"version"`
+	SerialNumber string                 `json:"serialNumber"`
+	LastContact  string                 `json:"lastContact"`
+	Active       bool                   `json:"active"`
+	AgentVersion string                 `json:"agentVersion"`
+	MDM          map[string]interface{} `json:"mdm"`
+}
+
+// mdmManaged reports whether the system is enrolled in an MDM
+func (e deviceListEntry) mdmManaged() bool {
+	vendor, _ := e.MDM["vendor"].(string)
+	return vendor != ""
+}
+
+// deviceListFilter is a single filter block of the devices list data source
+type deviceListFilter struct {
+	Field    string
+	Operator string
+	Values   []string
+}
+
+// DataSourceDevicesList returns the schema for the plural JumpCloud devices data source
+func DataSourceDevicesList() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDevicesListRead,
+		Schema: map[string]*schema.Schema{
+			"filter": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Filters applied to the devices. A device must match every filter",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "Field to filter on (os, os_family, version, hostname, display_name, serial_number, last_contact, active, mdm_managed)",
+							ValidateFunc: validation.StringInSlice(sortedDeviceListFields(), false),
+						},
+						"operator": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "eq",
+							Description:  "Comparison operator (eq, ne, lt, lte, gt, gte, regex). eq matches any of the values and ne none of them",
+							ValidateFunc: validation.StringInSlice([]string{"eq", "ne", "lt", "lte", "gt", "gte", "regex"}, false),
+						},
+						"values": {
+							Type:        schema.TypeList,
+							Required:    true,
+							MinItems:    1,
+							Description: "Values to compare against. last_contact accepts RFC3339 timestamps or ages such as '30d' or '12h'",
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "IDs of the matching devices",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"total": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of matching devices",
+			},
+			"devices": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching devices, sorted by display name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id":            {Type: schema.TypeString, Computed: true},
+						"display_name":  {Type: schema.TypeString, Computed: true},
+						"hostname":      {Type: schema.TypeString, Computed: true},
+						"os":            {Type: schema.TypeString, Computed: true},
+						"os_family":     {Type: schema.TypeString, Computed: true},
+						"version":       {Type: schema.TypeString, Computed: true},
+						"serial_number": {Type: schema.TypeString, Computed: true},
+						"last_contact":  {Type: schema.TypeString, Computed: true},
+						"active":        {Type: schema.TypeBool, Computed: true},
+						"mdm_managed":   {Type: schema.TypeBool, Computed: true},
+						"agent_version": {Type: schema.TypeString, Computed: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDevicesListRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	filters, err := expandDeviceListFilters(d.Get("filter").([]interface{}), time.Now())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	devices, err := searchDevices(ctx, c, filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids := make([]string, 0, len(devices))
+	items := make([]map[string]interface{}, 0, len(devices))
+	for _, device := range devices {
+		ids = append(ids, device.ID)
+		items = append(items, map[string]interface{}{
+			"id":            device.ID,
+			"display_name":  device.DisplayName,
+			"hostname":      device.Hostname,
+			"os":            device.OS,
+			"os_family":     device.OSFamily,
+			"version":       device.Version,
+			"serial_number": device.SerialNumber,
+			"last_contact":  device.LastContact,
+			"active":        device.Active,
+			"mdm_managed":   device.mdmManaged(),
+			"agent_version": device.AgentVersion,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("devices-list-%d", time.Now().Unix()))
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting ids: %v", err))
+	}
+	if err := d.Set("total", len(devices)); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting total: %v", err))
+	}
+	if err := d.Set("devices", items); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting devices: %v", err))
+	}
+
+	return nil
+}
+
+// searchDevices pages through the systems search API and returns the devices matching every filter
+func searchDevices(ctx context.Context, c common.ClientInterface, filters []deviceListFilter) ([]deviceListEntry, error) {
+	body := map[string]interface{}{"fields": deviceListSearchFields}
+	if and := deviceListSearchFilter(filters); len(and) > 0 {
+		body["filter"] = map[string]interface{}{"and": and}
+	}
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing systems search: %v", err)
+	}
+
+	var matches []deviceListEntry
+	skip := 0
+	for {
+		path := fmt.Sprintf("/api/search/systems?limit=100&skip=%d", skip)
+		tflog.Debug(ctx, "Searching JumpCloud systems", map[string]interface{}{"path": path})
+
+		resp, err := c.DoRequest(http.MethodPost, path, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("error searching systems: %v", err)
+		}
+
+		var page struct {
+			TotalCount int               `json:"totalCount"`
+			Results    []deviceListEntry `json:"results"`
+		}
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing systems search response: %v", err)
+		}
+
+		for _, device := range page.Results {
+			if deviceMatchesFilters(device, filters) {
+				matches = append(matches, device)
+			}
+		}
+
+		if len(page.Results) < 100 {
+			break
+		}
+		skip += len(page.Results)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].DisplayName != matches[j].DisplayName {
+			return matches[i].DisplayName < matches[j].DisplayName
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	return matches, nil
+}
+
+// expandDeviceListFilters converts the filter blocks, resolving relative last_contact ages against now
+func expandDeviceListFilters(raw []interface{}, now time.Time) ([]deviceListFilter, error) {
+	filters := make([]deviceListFilter, 0, len(raw))
+	for _, item := range raw {
+		m := item.(map[string]interface{})
+		filter := deviceListFilter{
+			Field:    m["field"].(string),
+			Operator: m["operator"].(string),
+		}
+		for _, v := range m["values"].([]interface{}) {
+			value, _ := v.(string)
+			filter.Values = append(filter.Values, value)
+		}
+
+		switch filter.Field {
+		case "active", "mdm_managed":
+			if filter.Operator != "eq" && filter.Operator != "ne" {
+				return nil, fmt.Errorf("filter on %s only supports the eq and ne operators", filter.Field)
+			}
+			for i, value := range filter.Values {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, fmt.Errorf("filter on %s expects true or false, got %q", filter.Field, value)
+				}
+				filter.Values[i] = strconv.FormatBool(b)
+			}
+		case "last_contact":
+			for i, value := range filter.Values {
+				resolved, err := resolveLastContactValue(value, now)
+				if err != nil {
+					return nil, err
+				}
+				filter.Values[i] = resolved
+			}
+		}
+
+		if filter.Operator == "regex" {
+			for _, value := range filter.Values {
+				if _, err := regexp.Compile(value); err != nil {
+					return nil, fmt.Errorf("invalid regex %q for filter on %s: %v", value, filter.Field, err)
+				}
+			}
+		}
+
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// resolveLastContactValue turns an age such as '30d' into the RFC3339 timestamp of now minus that age
+func resolveLastContactValue(value string, now time.Time) (string, error) {
+	if match := relativeAgeRegex.FindStringSubmatch(value); match != nil {
+		amount, _ := strconv.Atoi(match[1])
+		unit := time.Hour
+		if match[2] == "d" {
+			unit = 24 * time.Hour
+		}
+		return now.Add(-time.Duration(amount) * unit).UTC().Format(time.RFC3339), nil
+	}
+	if _, err := time.Parse(time.RFC3339, value); err != nil {
+		return "", fmt.Errorf("last_contact filter expects an RFC3339 timestamp or an age like '30d', got %q", value)
+	}
+	return value, nil
+}
+
+// deviceListSearchFilter pushes the equality filters on plain fields down to the search API so
+// fewer pages are fetched. Every filter is still evaluated locally
+func deviceListSearchFilter(filters []deviceListFilter) []interface{} {
+	var and []interface{}
+	for _, filter := range filters {
+		if filter.Operator != "eq" {
+			continue
+		}
+		switch filter.Field {
+		case "last_contact", "mdm_managed", "version":
+			continue
+		case "active":
+			if len(filter.Values) == 1 {
+				active, _ := strconv.ParseBool(filter.Values[0])
+				and = append(and, map[string]interface{}{"active": active})
+			}
+		default:
+			apiField := deviceListFields[filter.Field]
+			if len(filter.Values) == 1 {
+				and = append(and, map[string]interface{}{apiField: filter.Values[0]})
+			} else {
+				and = append(and, map[string]interface{}{apiField: map[string]interface{}{"$in": filter.Values}})
+			}
+		}
+	}
+	return and
+}
+
+// deviceMatchesFilters reports whether the device satisfies every filter
+func deviceMatchesFilters(device deviceListEntry, filters []deviceListFilter) bool {
+	for _, filter := range filters {
+		if !deviceMatchesFilter(device, filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func deviceMatchesFilter(device deviceListEntry, filter deviceListFilter) bool {
+	var actual string
+	switch filter.Field {
+	case "os":
+		actual = device.OS
+	case "os_family":
+		actual = device.OSFamily
+	case "version":
+		actual = device.Version
+	case "hostname":
+		actual = device.Hostname
+	case "display_name":
+		actual = device.DisplayName
+	case "serial_number":
+		actual = device.SerialNumber
+	case "last_contact":
+		actual = device.LastContact
+	case "active":
+		actual = strconv.FormatBool(device.Active)
+	case "mdm_managed":
+		actual = strconv.FormatBool(device.mdmManaged())
+	}
+
+	switch filter.Operator {
+	case "eq", "ne":
+		found := false
+		for _, value := range filter.Values {
+			if actual == value {
+				found = true
+				break
+			}
+		}
+		return found == (filter.Operator == "eq")
+	case "regex":
+		for _, value := range filter.Values {
+			if re, err := regexp.Compile(value); err == nil && re.MatchString(actual) {
+				return true
+			}
+		}
+		return false
+	}
+
+	cmp := compareDeviceValues(filter.Field, actual, filter.Values[0])
+	switch filter.Operator {
+	case "lt":
+		return cmp < 0
+	case "lte":
+		return cmp <= 0
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	}
+	return false
+}
+
+// compareDeviceValues orders two values of a field: versions segment by segment, timestamps
+// chronologically (a device that never contacted JumpCloud is the oldest) and anything else as text
+func compareDeviceValues(field, a, b string) int {
+	switch field {
+	case "version":
+		return compareVersions(a, b)
+	case "last_contact":
+		ta, errA := time.Parse(time.RFC3339, a)
+		tb, errB := time.Parse(time.RFC3339, b)
+		if errA != nil {
+			ta = time.Time{}
+		}
+		if errB != nil {
+			tb = time.Time{}
+		}
+		return ta.Compare(tb)
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareVersions compares dotted versions numerically, so that 9.1 < 14 and 14.2.1 > 14.2
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' || r == ' ' })
+	}
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var sa, sb string
+		if i < len(pa) {
+			sa = pa[i]
+		}
+		if i < len(pb) {
+			sb = pb[i]
+		}
+		na, errA := strconv.Atoi(sa)
+		nb, errB := strconv.Atoi(sb)
+		if sa == "" {
+			na, errA = 0, nil
+		}
+		if sb == "" {
+			nb, errB = 0, nil
+		}
+		if errA == nil && errB == nil {
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(sa, sb); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// sortedDeviceListFields returns the filterable field names in a stable order
+func sortedDeviceListFields() []string {
+	fields := make([]string, 0, len(deviceListFields))
+	for field := range deviceListFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
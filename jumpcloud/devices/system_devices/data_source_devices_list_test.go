@@ -0,0 +1,162 @@
+package devices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// devicesListTestClient serves the search results in pages of 100
+type devicesListTestClient struct {
+	devices  []deviceListEntry
+	requests []string
+}
+
+func (c *devicesListTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	c.requests = append(c.requests, path)
+	var skip int
+	fmt.Sscanf(path[strings.Index(path, "skip=")+5:], "%d", &skip)
+	end := skip + 100
+	if end > len(c.devices) {
+		end = len(c.devices)
+	}
+	return json.Marshal(map[string]interface{}{"totalCount": len(c.devices), "results": c.devices[skip:end]})
+}
+
+func (c *devicesListTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *devicesListTestClient) GetApiKey() string { return "test" }
+
+func (c *devicesListTestClient) GetOrgID() string { return "" }
+
+func TestDataSourceDevicesListSchema(t *testing.T) {
+	s := DataSourceDevicesList().Schema
+	for _, key := range []string{"filter", "ids", "total", "devices"} {
+		if s[key] == nil {
+			t.Errorf("expected %s in the schema", key)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"9.1", "14", -1},
+		{"14.2.1", "14.2", 1},
+		{"14.0", "14", 0},
+		{"10.0.19045", "10.0.22631", -1},
+	}
+	for _, tc := range cases {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestExpandDeviceListFilters(t *testing.T) {
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	filters, err := expandDeviceListFilters([]interface{}{
+		map[string]interface{}{"field": "last_contact", "operator": "lt", "values": []interface{}{"30d"}},
+		map[string]interface{}{"field": "active", "operator": "eq", "values": []interface{}{"False"}},
+	}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filters[0].Values[0] != "2024-05-31T00:00:00Z" {
+		t.Errorf("expected relative age to resolve to 2024-05-31T00:00:00Z, got %s", filters[0].Values[0])
+	}
+	if filters[1].Values[0] != "false" {
+		t.Errorf("expected boolean to be normalized, got %s", filters[1].Values[0])
+	}
+
+	invalid := [][]interface{}{
+		{map[string]interface{}{"field": "active", "operator": "lt", "values": []interface{}{"true"}}},
+		{map[string]interface{}{"field": "last_contact", "operator": "lt", "values": []interface{}{"last month"}}},
+		{map[string]interface{}{"field": "hostname", "operator": "regex", "values": []interface{}{"("}}},
+	}
+	for _, raw := range invalid {
+		if _, err := expandDeviceListFilters(raw, now); err == nil {
+			t.Errorf("expected an error for %v", raw)
+		}
+	}
+}
+
+func TestDeviceMatchesFilters(t *testing.T) {
+	mac := deviceListEntry{ID: "1", OS: "Mac OS X", Version: "13.6.1", Hostname: "mac-01", Active: true,
+		LastContact: "2024-05-01T10:00:00.000Z", MDM: map[string]interface{}{"vendor": "internal"}}
+
+	cases := []struct {
+		name    string
+		filters []deviceListFilter
+		want    bool
+	}{
+		{"os and version", []deviceListFilter{
+			{Field: "os", Operator: "eq", Values: []string{"Mac OS X"}},
+			{Field: "version", Operator: "lt", Values: []string{"14"}},
+		}, true},
+		{"version too low", []deviceListFilter{{Field: "version", Operator: "gte", Values: []string{"14"}}}, false},
+		{"stale", []deviceListFilter{{Field: "last_contact", Operator: "lt", Values: []string{"2024-05-31T00:00:00Z"}}}, true},
+		{"hostname regex", []deviceListFilter{{Field: "hostname", Operator: "regex", Values: []string{"^mac-"}}}, true},
+		{"inactive only", []deviceListFilter{{Field: "active", Operator: "eq", Values: []string{"false"}}}, false},
+		{"mdm managed", []deviceListFilter{{Field: "mdm_managed", Operator: "eq", Values: []string{"true"}}}, true},
+		{"serial not in list", []deviceListFilter{{Field: "serial_number", Operator: "eq", Values: []string{"C02X", "C02Y"}}}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := deviceMatchesFilters(mac, tc.filters); got != tc.want {
+				t.Errorf("deviceMatchesFilters() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSearchDevicesPaginates(t *testing.T) {
+	client := &devicesListTestClient{}
+	for i := 0; i < 150; i++ {
+		client.devices = append(client.devices, deviceListEntry{
+			ID:          fmt.Sprintf("%03d", i),
+			DisplayName: fmt.Sprintf("device-%03d", i),
+			Active:      i%2 == 0,
+		})
+	}
+
+	devices, err := searchDevices(context.Background(), client, []deviceListFilter{
+		{Field: "active", Operator: "eq", Values: []string{"false"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.requests) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(client.requests))
+	}
+	if len(devices) != 75 {
+		t.Errorf("expected 75 inactive devices, got %d", len(devices))
+	}
+	if devices[0].ID != "001" {
+		t.Errorf("expected devices sorted by display name, got %s first", devices[0].ID)
+	}
+}
+
+func TestDeviceListSearchFilter(t *testing.T) {
+	and := deviceListSearchFilter([]deviceListFilter{
+		{Field: "serial_number", Operator: "eq", Values: []string{"C02X", "C02Y"}},
+		{Field: "os", Operator: "eq", Values: []string{"Windows"}},
+		{Field: "version", Operator: "eq", Values: []string{"10"}},
+		{Field: "hostname", Operator: "regex", Values: []string{"^web"}},
+	})
+	if len(and) != 2 {
+		t.Fatalf("expected 2 pushed down filters, got %d: %v", len(and), and)
+	}
+	serial := and[0].(map[string]interface{})["serialNumber"].(map[string]interface{})
+	if len(serial["$in"].([]string)) != 2 {
+		t.Errorf("expected serial numbers to use $in, got %v", serial)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -12,12 +13,17 @@ import (
 	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
 )
 
+// ResourceSystem returns a schema resource adopting an enrolled device and managing its editable
+// settings. Devices are only enrolled by the agent, so create looks the device up instead of creating it
 func ResourceSystem() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceSystemCreate,
 		ReadContext:   resourceSystemRead,
 		UpdateContext: resourceSystemUpdate,
 		DeleteContext: resourceSystemDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSystemImport,
+		},
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:     schema.TypeString,
@@ -110,25 +116,57 @@ func ResourceSystem() *schema.Resource {
 				Computed: true,
 			},
 			"hostname": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				AtLeastOneOf: []string{"serial_number", "hostname"},
+				Description:  "Hostname of the device to adopt, kept as configured when the device reports another one",
 			},
 			"serial_number": {
-				Type:     schema.TypeString,
-				Computed: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				AtLeastOneOf: []string{"serial_number", "hostname"},
+				Description:  "Serial number of the device to adopt",
+			},
+			"reported_hostname": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hostname currently reported by the device",
+			},
+			"reported_serial_number": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Serial number currently reported by the device",
+			},
+			"delete_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete the device from JumpCloud on destroy, removing the agent from the machine. By default destroy only releases the device from Terraform",
 			},
 		},
 	}
 }
 
-func resourceSystemCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	c, err := common.ConvertToClientInterface(meta)
-	if err != nil {
-		return diag.FromErr(err)
-	}
+// systemUpdate holds the editable settings of a device. The SSH and MFA settings are sent even when
+// false, so that turning them off on an adopted device is not dropped like the omitempty fields of
+// common.System
+type systemUpdate struct {
+	DisplayName                    string                 `json:"displayName"`
+	AllowSshRootLogin              bool                   `json:"allowSshRootLogin"`
+	AllowSshPasswordAuthentication bool                   `json:"allowSshPasswordAuthentication"`
+	AllowMultiFactorAuthentication bool                   `json:"allowMultiFactorAuthentication"`
+	Tags                           []string               `json:"tags,omitempty"`
+	Attributes                     map[string]interface{} `json:"attributes,omitempty"`
+	Description                    string                 `json:"description,omitempty"`
+}
 
-	// Build system object from resource data
-	system := &common.System{
+// expandSystem builds the editable settings of the device from the resource data
+func expandSystem(d *schema.ResourceData) *systemUpdate {
+	system := &systemUpdate{
 		DisplayName:                    d.Get("display_name").(string),
 		AllowSshRootLogin:              d.Get("allow_ssh_root_login").(bool),
 		AllowSshPasswordAuthentication: d.Get("allow_ssh_password_authentication").(bool),
@@ -150,26 +188,104 @@ func resourceSystemCreate(ctx context.Context, d *schema.ResourceData, meta inte
 		system.Attributes = attributes
 	}
 
-	// Convert system to JSON
-	systemJSON, err := json.Marshal(system)
+	return system
+}
+
+// findSystemToAdopt returns the only enrolled device matching the serial number and hostname
+func findSystemToAdopt(c common.APIClientInterface, serialNumber, hostname string) (deviceListEntry, error) {
+	var and []interface{}
+	var criteria []string
+	if serialNumber != "" {
+		and = append(and, map[string]interface{}{"serialNumber": serialNumber})
+		criteria = append(criteria, fmt.Sprintf("serial number %q", serialNumber))
+	}
+	if hostname != "" {
+		and = append(and, map[string]interface{}{"hostname": hostname})
+		criteria = append(criteria, fmt.Sprintf("hostname %q", hostname))
+	}
+	if len(and) == 0 {
+		return deviceListEntry{}, fmt.Errorf("one of serial_number or hostname must be provided")
+	}
+
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"fields": deviceListSearchFields,
+		"filter": map[string]interface{}{"and": and},
+	})
+	if err != nil {
+		return deviceListEntry{}, fmt.Errorf("error serializing systems search: %v", err)
+	}
+
+	resp, err := c.DoRequest(http.MethodPost, "/api/search/systems?limit=2", reqBody)
+	if err != nil {
+		return deviceListEntry{}, fmt.Errorf("error searching systems: %v", err)
+	}
+
+	var page struct {
+		TotalCount int               `json:"totalCount"`
+		Results    []deviceListEntry `json:"results"`
+	}
+	if err := json.Unmarshal(resp, &page); err != nil {
+		return deviceListEntry{}, fmt.Errorf("error parsing systems search response: %v", err)
+	}
+
+	switch {
+	case len(page.Results) == 0:
+		return deviceListEntry{}, fmt.Errorf("no enrolled device found with %s", strings.Join(criteria, " and "))
+	case len(page.Results) > 1 || page.TotalCount > 1:
+		return deviceListEntry{}, fmt.Errorf("several devices found with %s, set both serial_number and hostname to pick one", strings.Join(criteria, " and "))
+	}
+	return page.Results[0], nil
+}
+
+// setSystemLookupKeys fills the lookup keys that are not configured with the values of the device, so
+// that a configuration setting only one of them does not plan a replacement
+func setSystemLookupKeys(d *schema.ResourceData, serialNumber, hostname string) error {
+	if d.Get("serial_number").(string) == "" {
+		if err := d.Set("serial_number", serialNumber); err != nil {
+			return fmt.Errorf("error setting serial_number: %v", err)
+		}
+	}
+	if d.Get("hostname").(string) == "" {
+		if err := d.Set("hostname", hostname); err != nil {
+			return fmt.Errorf("error setting hostname: %v", err)
+		}
+	}
+	return nil
+}
+
+// updateSystem sends the editable settings of the device to the API
+func updateSystem(c common.APIClientInterface, d *schema.ResourceData) error {
+	systemJSON, err := json.Marshal(expandSystem(d))
+	if err != nil {
+		return fmt.Errorf("error serializing system: %v", err)
+	}
+
+	_, err = c.DoRequest(http.MethodPut, fmt.Sprintf("/api/v2/systems/%s", d.Id()), systemJSON)
+	return err
+}
+
+func resourceSystemCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, err := common.ConvertToClientInterface(meta)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error serializing system: %v", err))
+		return diag.FromErr(err)
 	}
 
-	// Create system via API
-	resp, err := c.DoRequest(http.MethodPost, "/api/v2/systems", systemJSON)
+	// Devices are enrolled by the agent, so look up the device to adopt
+	device, err := findSystemToAdopt(c, d.Get("serial_number").(string), d.Get("hostname").(string))
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("error creating system: %v", err))
+		return diag.FromErr(fmt.Errorf("error adopting system: %v", err))
 	}
 
-	// Decode response
-	var newSystem common.System
-	if err := json.Unmarshal(resp, &newSystem); err != nil {
-		return diag.FromErr(fmt.Errorf("error deserializing system response: %v", err))
+	id := device.ID
+	d.SetId(id)
+	if err := setSystemLookupKeys(d, device.SerialNumber, device.Hostname); err != nil {
+		return diag.FromErr(err)
 	}
+	tflog.Debug(ctx, fmt.Sprintf("Adopted system %s", id))
 
-	// Set ID in resource data
-	d.SetId(newSystem.ID)
+	if err := updateSystem(c, d); err != nil {
+		return append(diag.FromErr(fmt.Errorf("error updating system %s: %v", id, err)), resourceSystemRead(ctx, d, meta)...)
+	}
 
 	// Read the system to set all the computed fields
 	return resourceSystemRead(ctx, d, meta)
@@ -250,6 +366,15 @@ func resourceSystemRead(ctx context.Context, d *schema.ResourceData, meta interf
 		return diag.FromErr(fmt.Errorf("erro ao definir description: %v", err))
 	}
 
+	// hostname and serial_number keep the lookup keys, the agent may report new values over time
+	if err := d.Set("reported_hostname", system.HostName); err != nil {
+		return diag.FromErr(fmt.Errorf("erro ao definir reported_hostname: %v", err))
+	}
+
+	if err := d.Set("reported_serial_number", system.SerialNumber); err != nil {
+		return diag.FromErr(fmt.Errorf("erro ao definir reported_serial_number: %v", err))
+	}
+
 	// Handle attributes
 	if system.Attributes != nil {
 		attributes := make(map[string]interface{})
@@ -272,55 +397,34 @@ func resourceSystemUpdate(ctx context.Context, d *schema.ResourceData, meta inte
 
 	systemID := d.Id()
 
-	// Build system object from resource data
-	system := &common.System{
-		DisplayName:                    d.Get("display_name").(string),
-		AllowSshRootLogin:              d.Get("allow_ssh_root_login").(bool),
-		AllowSshPasswordAuthentication: d.Get("allow_ssh_password_authentication").(bool),
-		AllowMultiFactorAuthentication: d.Get("allow_multi_factor_authentication").(bool),
-		Description:                    d.Get("description").(string),
-	}
-
-	// Handle tags if changed
-	if tagsRaw, ok := d.GetOk("tags"); ok {
-		system.Tags = common.ExpandStringList(tagsRaw.([]interface{}))
-	}
-
-	// Process attributes
-	if attrRaw, ok := d.GetOk("attributes"); ok {
-		attributes := make(map[string]interface{})
-		for k, v := range attrRaw.(map[string]interface{}) {
-			attributes[k] = v
+	// delete_on_destroy only changes the behavior of destroy
+	if d.HasChangesExcept("delete_on_destroy") {
+		if err := updateSystem(c, d); err != nil {
+			return diag.FromErr(fmt.Errorf("error updating system %s: %v", systemID, err))
 		}
-		system.Attributes = attributes
-	}
-
-	// Convert system to JSON
-	systemJSON, err := json.Marshal(system)
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("error serializing system: %v", err))
-	}
-
-	// Update system via API
-	_, err = c.DoRequest(http.MethodPut, fmt.Sprintf("/api/v2/systems/%s", systemID), systemJSON)
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("error updating system %s: %v", systemID, err))
 	}
 
 	return resourceSystemRead(ctx, d, meta)
 }
 
 func resourceSystemDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	systemID := d.Id()
+
+	// Deleting the system removes the agent from the machine, so by default we only release it
+	if !d.Get("delete_on_destroy").(bool) {
+		tflog.Warn(ctx, fmt.Sprintf("System %s is kept in JumpCloud, removing from state only", systemID))
+		d.SetId("")
+		return nil
+	}
+
 	c, err := common.ConvertToClientInterface(meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	systemID := d.Id()
-
 	// Delete system via API
 	_, err = c.DoRequest(http.MethodDelete, fmt.Sprintf("/api/v2/systems/%s", systemID), nil)
-	if err != nil {
+	if err != nil && !common.IsNotFoundError(err) {
 		return diag.FromErr(fmt.Errorf("error deleting system %s: %v", systemID, err))
 	}
 
@@ -329,3 +433,31 @@ func resourceSystemDelete(ctx context.Context, d *schema.ResourceData, meta inte
 
 	return nil
 }
+
+func resourceSystemImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	c, err := common.ConvertToClientInterface(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/systems/%s", d.Id()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading system %s: %v", d.Id(), err)
+	}
+
+	var system common.System
+	if err := json.Unmarshal(resp, &system); err != nil {
+		return nil, fmt.Errorf("error deserializing system response: %v", err)
+	}
+
+	// The lookup keys are taken from the device, the configuration only needs one of them
+	if err := setSystemLookupKeys(d, system.SerialNumber, system.HostName); err != nil {
+		return nil, err
+	}
+
+	// Imported devices are released on destroy unless the configuration says otherwise
+	if err := d.Set("delete_on_destroy", false); err != nil {
+		return nil, fmt.Errorf("error setting delete_on_destroy: %v", err)
+	}
+	return []*schema.ResourceData{d}, nil
+}
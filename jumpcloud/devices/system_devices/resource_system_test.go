@@ -1,7 +1,11 @@
 package devices
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -119,6 +123,10 @@ func TestResourceSystemSchema(t *testing.T) {
 	if !s.Schema["system_type"].Computed {
 		t.Error("Expected system_type to be computed")
 	}
+
+	if err := s.InternalValidate(nil, true); err != nil {
+		t.Errorf("invalid schema: %v", err)
+	}
 }
 
 // Test helper functions
@@ -280,8 +288,9 @@ func testAccCheckJumpCloudSystemExists(n string) resource.TestCheckFunc {
 func testAccJumpCloudSystemConfig_basic() string {
 	return `
 resource "jumpcloud_system" "test" {
-  display_name = "test-system"
-  description  = "Test system"
+  serial_number = "C02TESTSYSTEM"
+  display_name  = "test-system"
+  description   = "Test system"
 }
 `
 }
@@ -291,8 +300,9 @@ resource "jumpcloud_system" "test" {
 func testAccJumpCloudSystemConfig_update() string {
 	return `
 resource "jumpcloud_system" "test" {
-  display_name        = "updated-test-system"
-  description         = "Updated test system"
+  serial_number        = "C02TESTSYSTEM"
+  display_name         = "updated-test-system"
+  description          = "Updated test system"
   allow_ssh_root_login = true
 }
 `
@@ -303,8 +313,9 @@ resource "jumpcloud_system" "test" {
 func testAccJumpCloudSystemConfig_tags() string {
 	return `
 resource "jumpcloud_system" "test_tags" {
-  display_name = "test-system-tags"
-  description  = "Test system with tags"
+  serial_number = "C02TESTSYSTEM"
+  display_name  = "test-system-tags"
+  description   = "Test system with tags"
 
   tags = [
     "dev",
@@ -314,3 +325,150 @@ resource "jumpcloud_system" "test_tags" {
 }
 `
 }
+
+// systemTestClient serves the systems search results and records the requests
+type systemTestClient struct {
+	results  []deviceListEntry
+	system   common.System
+	requests []string
+	updates  [][]byte
+}
+
+func (c *systemTestClient) DoRequest(method, path string, body interface{}) ([]byte, error) {
+	c.requests = append(c.requests, method+" "+path)
+	if method == http.MethodPut {
+		c.updates = append(c.updates, body.([]byte))
+	}
+	if method == http.MethodPost && strings.HasPrefix(path, "/api/search/systems") {
+		return json.Marshal(map[string]interface{}{"totalCount": len(c.results), "results": c.results})
+	}
+	if method == http.MethodGet && strings.HasPrefix(path, "/api/v2/systems/") {
+		return json.Marshal(c.system)
+	}
+	return []byte("{}"), nil
+}
+
+func (c *systemTestClient) GetAPIKey() string { return "test" }
+
+func (c *systemTestClient) GetOrgID() string { return "" }
+
+func TestFindSystemToAdopt(t *testing.T) {
+	client := &systemTestClient{results: []deviceListEntry{{ID: "system1", SerialNumber: "C02XYZ"}}}
+	device, err := findSystemToAdopt(client, "C02XYZ", "")
+	if err != nil || device.ID != "system1" {
+		t.Fatalf("expected system1, got %q: %v", device.ID, err)
+	}
+
+	client.results = nil
+	if _, err := findSystemToAdopt(client, "C02XYZ", ""); err == nil || !strings.Contains(err.Error(), "no enrolled device") {
+		t.Errorf("expected an error when no device matches, got %v", err)
+	}
+
+	client.results = []deviceListEntry{{ID: "system1"}, {ID: "system2"}}
+	if _, err := findSystemToAdopt(client, "", "build-agent"); err == nil || !strings.Contains(err.Error(), "several devices") {
+		t.Errorf("expected an error when several devices match, got %v", err)
+	}
+
+	if _, err := findSystemToAdopt(client, "", ""); err == nil {
+		t.Error("expected an error without serial number and hostname")
+	}
+}
+
+func TestResourceSystemDelete(t *testing.T) {
+	for _, deleteOnDestroy := range []bool{false, true} {
+		client := &systemTestClient{}
+		d := schema.TestResourceDataRaw(t, ResourceSystem().Schema, map[string]interface{}{
+			"display_name":      "laptop",
+			"serial_number":     "C02XYZ",
+			"delete_on_destroy": deleteOnDestroy,
+		})
+		d.SetId("system1")
+
+		if diags := resourceSystemDelete(context.Background(), d, client); diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if d.Id() != "" {
+			t.Errorf("expected the system to be removed from state")
+		}
+		if deleted := len(client.requests) == 1 && client.requests[0] == "DELETE /api/v2/systems/system1"; deleted != deleteOnDestroy {
+			t.Errorf("delete_on_destroy = %v: unexpected requests %v", deleteOnDestroy, client.requests)
+		}
+	}
+}
+
+func TestResourceSystemKeepsLookupKeys(t *testing.T) {
+	r := ResourceSystem()
+	client := &systemTestClient{system: common.System{ID: "system1", DisplayName: "laptop", HostName: "laptop.local", SerialNumber: "C02XYZ"}}
+	state := &terraform.InstanceState{ID: "system1", Attributes: map[string]string{
+		"id":                                "system1",
+		"display_name":                      "laptop",
+		"hostname":                          "laptop",
+		"serial_number":                     "C02XYZ",
+		"allow_ssh_root_login":              "false",
+		"allow_ssh_password_authentication": "true",
+		"allow_multi_factor_authentication": "false",
+		"delete_on_destroy":                 "false",
+	}}
+
+	// The agent now reports laptop.local, the lookup key stays as configured
+	d := r.Data(state)
+	if diags := resourceSystemRead(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if d.Get("hostname") != "laptop" || d.Get("reported_hostname") != "laptop.local" {
+		t.Errorf("expected the configured hostname and the reported one, got %v and %v", d.Get("hostname"), d.Get("reported_hostname"))
+	}
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{"display_name": "laptop", "hostname": "laptop"})
+	diff, err := r.Diff(context.Background(), d.State(), config, client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff != nil && diff.RequiresNew() {
+		t.Errorf("expected no replacement when the device reports a new hostname, got %v", diff)
+	}
+}
+
+func TestResourceSystemImport(t *testing.T) {
+	client := &systemTestClient{system: common.System{ID: "system1", HostName: "laptop.local", SerialNumber: "C02XYZ"}}
+	d := ResourceSystem().Data(&terraform.InstanceState{ID: "system1"})
+
+	if _, err := resourceSystemImport(context.Background(), d, client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Get("serial_number") != "C02XYZ" || d.Get("hostname") != "laptop.local" {
+		t.Errorf("expected the lookup keys of the device, got %v and %v", d.Get("serial_number"), d.Get("hostname"))
+	}
+	if d.Get("delete_on_destroy") != false {
+		t.Error("expected imported devices to be released on destroy")
+	}
+}
+
+func TestResourceSystemAdoptSendsDisabledSettings(t *testing.T) {
+	client := &systemTestClient{
+		results: []deviceListEntry{{ID: "system1", SerialNumber: "C02XYZ", Hostname: "laptop"}},
+		system:  common.System{ID: "system1", DisplayName: "laptop", AllowMultiFactorAuthentication: true, AllowSshPasswordAuthentication: true},
+	}
+	d := schema.TestResourceDataRaw(t, ResourceSystem().Schema, map[string]interface{}{
+		"display_name":                      "laptop",
+		"serial_number":                     "C02XYZ",
+		"allow_ssh_password_authentication": false,
+		"allow_multi_factor_authentication": false,
+	})
+
+	if diags := resourceSystemCreate(context.Background(), d, client); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(client.updates) != 1 {
+		t.Fatalf("expected one update, got %d", len(client.updates))
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(client.updates[0], &body); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"allowSshRootLogin", "allowSshPasswordAuthentication", "allowMultiFactorAuthentication"} {
+		if value, ok := body[key]; !ok || value != false {
+			t.Errorf("expected %s to be sent as false, got %v", key, body)
+		}
+	}
+}
@@ -5,21 +5,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
 )
 
 // SystemGroup represents a system group in JumpCloud
 type SystemGroup struct {
-	ID          string                 `json:"_id,omitempty"`
-	Name        string                 `json:"name"`
-	Description string                 `json:"description,omitempty"`
-	Type        string                 `json:"type,omitempty"`
-	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	ID                      string                      `json:"id,omitempty"`
+	Name                    string                      `json:"name"`
+	Description             string                      `json:"description,omitempty"`
+	Type                    string                      `json:"type,omitempty"`
+	Attributes              map[string]interface{}      `json:"attributes,omitempty"`
+	MembershipMethod        string                      `json:"membershipMethod,omitempty"`
+	MemberQuery             interface{}                 `json:"memberQuery,omitempty"`
+	MemberQueryExemptions   []common.UserGroupExemption `json:"memberQueryExemptions,omitempty"`
+	MemberSuggestionsNotify bool                        `json:"memberSuggestionsNotify,omitempty"`
+}
+
+// deviceGroupQueryFields lists the device fields dynamic group filters can match
+var deviceGroupQueryFields = []string{
+	"agentVersion",
+	"arch",
+	"description",
+	"displayName",
+	"hostname",
+	"os",
+	"osFamily",
+	"serialNumber",
+	"version",
+}
+
+// searchUnsupportedOperators are the comparison operators Search queries cannot express
+var searchUnsupportedOperators = map[string]bool{"gt": true, "ge": true, "lt": true, "le": true}
+
+// convertDeviceQueryField maps a device filter field to its API name, device fields are used as-is
+func convertDeviceQueryField(field string) string {
+	return field
+}
+
+// systemGroupImportLookup resolves name:<name> import IDs
+var systemGroupImportLookup = common.ImportLookup{
+	Kind:     "device group",
+	ListPath: "/api/v2/systemgroups",
+	Fields:   map[string]string{"name": "name"},
 }
 
 // ResourceGroup returns the resource for managing system groups
@@ -29,6 +63,9 @@ func ResourceGroup() *schema.Resource {
 		ReadContext:   resourceGroupRead,
 		UpdateContext: resourceGroupUpdate,
 		DeleteContext: resourceGroupDelete,
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			return validateDeviceGroupMembership(diff.Get("membership_method").(string), diff.Get("member_query").([]interface{}))
+		},
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:     schema.TypeString,
@@ -52,6 +89,86 @@ func ResourceGroup() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"membership_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "STATIC",
+				ValidateFunc: validation.StringInSlice([]string{"STATIC", "DYNAMIC_REVIEW_REQUIRED", "DYNAMIC_AUTOMATED"}, false),
+				Description:  "Method for determining group membership. Valid values are STATIC, DYNAMIC_REVIEW_REQUIRED, or DYNAMIC_AUTOMATED",
+			},
+			"member_query": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Query for determining dynamic group membership",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"query_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "FilterQuery",
+							ValidateFunc: validation.StringInSlice([]string{"FilterQuery", "Search"}, false),
+							Description:  "Type of query. Valid values are 'FilterQuery' and 'Search'. The 'regex' operator requires 'Search'",
+						},
+						"filter": {
+							Type:        schema.TypeSet,
+							Required:    true,
+							Description: "Filters for the query. Devices must match every filter",
+							Set: func(v interface{}) int {
+								m := v.(map[string]interface{})
+								return schema.HashString(fmt.Sprintf("%s:%s:%s", m["field"].(string), m["operator"].(string), m["value"].(string)))
+							},
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"field": {
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice(deviceGroupQueryFields, false),
+										Description:  fmt.Sprintf("Device field to filter on. One of: %s", strings.Join(deviceGroupQueryFields, ", ")),
+									},
+									"operator": {
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice([]string{"eq", "ne", "in", "gt", "ge", "lt", "le", "regex"}, false),
+										Description:  "Operator for the filter. Valid operators are eq, ne, in, gt, ge, lt, le (FilterQuery only) and regex (hostname patterns, Search queries only)",
+									},
+									"value": {
+										Type:        schema.TypeString,
+										Required:    true,
+										Description: "Value for the filter. For 'in' operator, use pipe-delimited values (e.g., 'windows|darwin')",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"member_query_exemptions": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Devices exempted from the dynamic group query",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "ID of the device to exempt",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "SYSTEM",
+							Description: "Type of the exemption. Currently only SYSTEM is supported",
+						},
+					},
+				},
+			},
+			"member_suggestions_notify": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to send email notifications for membership suggestions. Only applicable for DYNAMIC_REVIEW_REQUIRED groups",
+			},
 			"created": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -59,7 +176,7 @@ func ResourceGroup() *schema.Resource {
 			},
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: common.ImportStateResolver(systemGroupImportLookup),
 		},
 		Description: "Manages system groups in JumpCloud. This resource allows creating, updating and deleting system groups, facilitating organization and management of systems.",
 		Timeouts: &schema.ResourceTimeout{
@@ -74,25 +191,17 @@ func ResourceGroup() *schema.Resource {
 func resourceGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	tflog.Info(ctx, "Creating system group in JumpCloud")
 
-	client, ok := meta.(interface {
-		DoRequest(method string, path string, body interface{}) ([]byte, error)
-	})
-	if !ok {
-		return diag.Errorf("error asserting API client")
+	client, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
 	}
 
 	// Create SystemGroup object from resource data
-	group := &SystemGroup{
-		Name:        d.Get("name").(string),
-		Description: d.Get("description").(string),
-		Type:        "system_group",
-	}
-
-	// Handle attributes
-	if v, ok := d.GetOk("attributes"); ok {
-		attributesMap := v.(map[string]interface{})
-		group.Attributes = common.ExpandAttributes(attributesMap)
+	group, err := expandSystemGroup(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
+	group.Type = "system_group"
 
 	// Convert to JSON
 	jsonData, err := json.Marshal(group)
@@ -125,11 +234,9 @@ func resourceGroupRead(ctx context.Context, d *schema.ResourceData, meta interfa
 
 	var diags diag.Diagnostics
 
-	client, ok := meta.(interface {
-		DoRequest(method string, path string, body interface{}) ([]byte, error)
-	})
-	if !ok {
-		return diag.Errorf("error asserting API client")
+	client, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
 	}
 
 	// Get group information by ID
@@ -161,6 +268,28 @@ func resourceGroupRead(ctx context.Context, d *schema.ResourceData, meta interfa
 		diags = append(diags, diag.FromErr(err)...)
 	}
 
+	// Groups created without a method are static
+	membershipMethod := group.MembershipMethod
+	if membershipMethod == "" {
+		membershipMethod = "STATIC"
+	}
+	if err := d.Set("membership_method", membershipMethod); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if err := d.Set("member_suggestions_notify", group.MemberSuggestionsNotify); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if group.MemberQuery != nil {
+		if err := d.Set("member_query", common.FlattenMemberQuery(group.MemberQuery, convertDeviceQueryField)); err != nil {
+			diags = append(diags, diag.FromErr(err)...)
+		}
+	} else if err := d.Set("member_query", []interface{}{}); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+	if err := d.Set("member_query_exemptions", common.FlattenMemberQueryExemptions(group.MemberQueryExemptions)); err != nil {
+		diags = append(diags, diag.FromErr(err)...)
+	}
+
 	// Get additional group metadata
 	metaResp, err := client.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/systemgroups/%s/members", groupID), nil)
 	if err == nil {
@@ -182,28 +311,20 @@ func resourceGroupRead(ctx context.Context, d *schema.ResourceData, meta interfa
 func resourceGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	tflog.Info(ctx, "Updating system group in JumpCloud")
 
-	client, ok := meta.(interface {
-		DoRequest(method string, path string, body interface{}) ([]byte, error)
-	})
-	if !ok {
-		return diag.Errorf("error asserting API client")
+	client, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
 	}
 
 	// Check if there are changes to the fields
-	if !d.HasChanges("name", "description", "attributes") {
+	if !d.HasChanges("name", "description", "attributes", "membership_method", "member_query", "member_query_exemptions", "member_suggestions_notify") {
 		return resourceGroupRead(ctx, d, meta)
 	}
 
 	// Prepare update object
-	group := &SystemGroup{
-		Name:        d.Get("name").(string),
-		Description: d.Get("description").(string),
-	}
-
-	// Handle attributes
-	if v, ok := d.GetOk("attributes"); ok {
-		attributesMap := v.(map[string]interface{})
-		group.Attributes = common.ExpandAttributes(attributesMap)
+	group, err := expandSystemGroup(d)
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	// Convert to JSON
@@ -229,11 +350,9 @@ func resourceGroupDelete(ctx context.Context, d *schema.ResourceData, meta inter
 
 	var diags diag.Diagnostics
 
-	client, ok := meta.(interface {
-		DoRequest(method string, path string, body interface{}) ([]byte, error)
-	})
-	if !ok {
-		return diag.Errorf("error asserting API client")
+	client, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
 	}
 
 	// Send request to delete the group
@@ -252,3 +371,68 @@ func resourceGroupDelete(ctx context.Context, d *schema.ResourceData, meta inter
 
 	return diags
 }
+
+// expandSystemGroup builds the API representation of a system group from the resource data
+func expandSystemGroup(d *schema.ResourceData) (*SystemGroup, error) {
+	group := &SystemGroup{
+		Name:                    d.Get("name").(string),
+		Description:             d.Get("description").(string),
+		MembershipMethod:        d.Get("membership_method").(string),
+		MemberSuggestionsNotify: d.Get("member_suggestions_notify").(bool),
+	}
+
+	// Handle attributes
+	if v, ok := d.GetOk("attributes"); ok {
+		attributesMap := v.(map[string]interface{})
+		group.Attributes = common.ExpandAttributes(attributesMap)
+	}
+
+	// Handle dynamic membership
+	if v, ok := d.GetOk("member_query"); ok {
+		memberQuery, err := common.ExpandMemberQuery(v.([]interface{}), convertDeviceQueryField)
+		if err != nil {
+			return nil, fmt.Errorf("error expanding member query: %v", err)
+		}
+		group.MemberQuery = memberQuery
+	}
+
+	if v, ok := d.GetOk("member_query_exemptions"); ok {
+		exemptions, err := common.ExpandMemberQueryExemptions(v.([]interface{}))
+		if err != nil {
+			return nil, fmt.Errorf("error expanding member query exemptions: %v", err)
+		}
+		group.MemberQueryExemptions = exemptions
+	}
+
+	return group, nil
+}
+
+// validateDeviceGroupMembership checks that dynamic groups have a query and static groups do not
+func validateDeviceGroupMembership(membershipMethod string, memberQuery []interface{}) error {
+	hasQuery := len(memberQuery) > 0 && memberQuery[0] != nil
+
+	if membershipMethod == "STATIC" && hasQuery {
+		return fmt.Errorf("member_query requires membership_method DYNAMIC_REVIEW_REQUIRED or DYNAMIC_AUTOMATED")
+	}
+	if membershipMethod != "STATIC" && membershipMethod != "" && !hasQuery {
+		return fmt.Errorf("membership_method %s requires a member_query", membershipMethod)
+	}
+
+	if hasQuery {
+		query := memberQuery[0].(map[string]interface{})
+		if filters, ok := query["filter"].(*schema.Set); ok {
+			for _, f := range filters.List() {
+				operator := f.(map[string]interface{})["operator"].(string)
+				if query["query_type"] != "Search" && operator == "regex" {
+					return fmt.Errorf("the regex operator requires query_type Search")
+				}
+				// Search queries only support equality and regular expressions
+				if query["query_type"] == "Search" && searchUnsupportedOperators[operator] {
+					return fmt.Errorf("the %s operator requires query_type FilterQuery", operator)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,11 +1,15 @@
 package system_groups
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
 	commonTesting "registry.terraform.io/agilize/jumpcloud/jumpcloud/common/testing"
 )
 
@@ -97,3 +101,98 @@ func testAccCheckJumpCloudSystemGroupDestroy(s *terraform.State) error {
 
 	return nil
 }
+
+func TestExpandSystemGroupDynamicMembership(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, ResourceGroup().Schema, map[string]interface{}{
+		"name":              "linux-build",
+		"membership_method": "DYNAMIC_AUTOMATED",
+		"member_query": []interface{}{map[string]interface{}{
+			"query_type": "Search",
+			"filter": []interface{}{
+				map[string]interface{}{"field": "osFamily", "operator": "eq", "value": "linux"},
+				map[string]interface{}{"field": "hostname", "operator": "regex", "value": "^build-"},
+			},
+		}},
+		"member_query_exemptions": []interface{}{map[string]interface{}{"id": "sys1", "type": "SYSTEM"}},
+	})
+
+	group, err := expandSystemGroup(d)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if group.MembershipMethod != "DYNAMIC_AUTOMATED" {
+		t.Errorf("Expected DYNAMIC_AUTOMATED, got %s", group.MembershipMethod)
+	}
+	if !reflect.DeepEqual(group.MemberQueryExemptions, []common.UserGroupExemption{{ID: "sys1", Type: "SYSTEM"}}) {
+		t.Errorf("Unexpected exemptions: %v", group.MemberQueryExemptions)
+	}
+
+	query, ok := group.MemberQuery.(*common.UserGroupSearchQuery)
+	if !ok {
+		t.Fatalf("Expected a search query, got %T", group.MemberQuery)
+	}
+	var searchFilters struct {
+		Filter []string `json:"filter"`
+	}
+	if err := json.Unmarshal([]byte(query.SearchFilters), &searchFilters); err != nil {
+		t.Fatalf("Unexpected search filters: %v", err)
+	}
+	want := map[string]bool{"osFamily:$eq:linux": true, "hostname:$regex:^build-": true}
+	if len(searchFilters.Filter) != len(want) {
+		t.Fatalf("Expected %d filters, got %v", len(want), searchFilters.Filter)
+	}
+	for _, f := range searchFilters.Filter {
+		if !want[f] {
+			t.Errorf("Unexpected filter %s", f)
+		}
+	}
+
+	// The regex filter survives the round trip through the API format
+	flattened := common.FlattenMemberQuery(query, convertDeviceQueryField)
+	filters := flattened[0].(map[string]interface{})["filter"].([]interface{})
+	foundRegex := false
+	for _, f := range filters {
+		if f.(map[string]interface{})["operator"] == "regex" {
+			foundRegex = true
+		}
+	}
+	if !foundRegex {
+		t.Errorf("Expected the regex filter to be flattened, got %v", filters)
+	}
+}
+
+func TestValidateDeviceGroupMembership(t *testing.T) {
+	filterQuery := func(queryType, operator string) []interface{} {
+		filters := schema.NewSet(func(v interface{}) int { return 0 }, []interface{}{
+			map[string]interface{}{"field": "hostname", "operator": operator, "value": "x"},
+		})
+		return []interface{}{map[string]interface{}{"query_type": queryType, "filter": filters}}
+	}
+
+	cases := []struct {
+		name    string
+		method  string
+		query   []interface{}
+		wantErr bool
+	}{
+		{"static without query", "STATIC", nil, false},
+		{"static with query", "STATIC", filterQuery("FilterQuery", "eq"), true},
+		{"dynamic without query", "DYNAMIC_AUTOMATED", nil, true},
+		{"dynamic with query", "DYNAMIC_REVIEW_REQUIRED", filterQuery("FilterQuery", "eq"), false},
+		{"regex requires search", "DYNAMIC_AUTOMATED", filterQuery("FilterQuery", "regex"), true},
+		{"regex with search", "DYNAMIC_AUTOMATED", filterQuery("Search", "regex"), false},
+		{"comparison with filter query", "DYNAMIC_AUTOMATED", filterQuery("FilterQuery", "gt"), false},
+		{"comparison with search", "DYNAMIC_AUTOMATED", filterQuery("Search", "gt"), true},
+		{"ne with search", "DYNAMIC_AUTOMATED", filterQuery("Search", "ne"), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateDeviceGroupMembership(tc.method, tc.query)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package system_policies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// DataSourceSystemPolicyGroup returns the data source looking up a policy group by name or ID
+func DataSourceSystemPolicyGroup() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSystemPolicyGroupRead,
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"group_id", "name"},
+				Description:  "ID of the policy group",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"group_id", "name"},
+				Description:  "Name of the policy group",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Description of the policy group",
+			},
+			"policy_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the system policies in the group",
+			},
+		},
+	}
+}
+
+func dataSourceSystemPolicyGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	var group PolicyGroup
+	if id, ok := d.GetOk("group_id"); ok {
+		resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/policygroups/%s", id.(string)), nil)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error reading policy group %s: %v", id, err))
+		}
+		if err := json.Unmarshal(resp, &group); err != nil {
+			return diag.FromErr(fmt.Errorf("error parsing policy group response: %v", err))
+		}
+	} else {
+		name := d.Get("name").(string)
+		tflog.Debug(ctx, fmt.Sprintf("Looking up JumpCloud policy group %s", name))
+
+		resp, err := c.DoRequest(http.MethodGet, "/api/v2/policygroups?filter="+url.QueryEscape("name:eq:"+name), nil)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error listing policy groups: %v", err))
+		}
+
+		var groups []PolicyGroup
+		if err := json.Unmarshal(resp, &groups); err != nil {
+			return diag.FromErr(fmt.Errorf("error parsing policy groups response: %v", err))
+		}
+
+		var matches []PolicyGroup
+		for _, g := range groups {
+			if g.Name == name {
+				matches = append(matches, g)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return diag.FromErr(fmt.Errorf("no policy group found with name: %s", name))
+		case 1:
+			group = matches[0]
+		default:
+			return diag.FromErr(fmt.Errorf("found %d policy groups with name %s, use group_id instead", len(matches), name))
+		}
+	}
+
+	members, err := listPolicyGroupMembers(c, group.ID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(group.ID)
+	if err := d.Set("group_id", group.ID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting group_id: %v", err))
+	}
+	if err := d.Set("name", group.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting name: %v", err))
+	}
+	if err := d.Set("description", group.Description); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting description: %v", err))
+	}
+	if err := d.Set("policy_ids", members); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting policy_ids: %v", err))
+	}
+
+	return nil
+}
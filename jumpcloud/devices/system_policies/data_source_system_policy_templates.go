@@ -0,0 +1,147 @@
+package system_policies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// DataSourceSystemPolicyTemplates returns the data source listing JumpCloud policy templates
+func DataSourceSystemPolicyTemplates() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceSystemPolicyTemplatesRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the template with this name, e.g. 'filevault_2_darwin'",
+			},
+			"os_meta_family": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"darwin", "windows", "linux", "ios", "android", "universal"}, false),
+				Description:  "Only return templates for this operating system family",
+			},
+			"templates": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching policy templates, sorted by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id":             {Type: schema.TypeString, Computed: true},
+						"name":           {Type: schema.TypeString, Computed: true},
+						"display_name":   {Type: schema.TypeString, Computed: true},
+						"description":    {Type: schema.TypeString, Computed: true},
+						"os_meta_family": {Type: schema.TypeString, Computed: true},
+						"config_fields": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"id":            {Type: schema.TypeString, Computed: true},
+									"name":          {Type: schema.TypeString, Computed: true},
+									"label":         {Type: schema.TypeString, Computed: true},
+									"type":          {Type: schema.TypeString, Computed: true},
+									"required":      {Type: schema.TypeBool, Computed: true},
+									"sensitive":     {Type: schema.TypeBool, Computed: true},
+									"default_value": {Type: schema.TypeString, Computed: true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSystemPolicyTemplatesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	var filters []string
+	if v, ok := d.GetOk("name"); ok {
+		filters = append(filters, "name:eq:"+v.(string))
+	}
+	if v, ok := d.GetOk("os_meta_family"); ok {
+		filters = append(filters, "osMetaFamily:eq:"+v.(string))
+	}
+
+	var templates []PolicyTemplate
+	skip := 0
+	for {
+		query := url.Values{}
+		query.Set("limit", "100")
+		query.Set("skip", strconv.Itoa(skip))
+		for _, filter := range filters {
+			query.Add("filter", filter)
+		}
+
+		tflog.Debug(ctx, "Listing JumpCloud policy templates", map[string]interface{}{"skip": skip})
+		resp, err := c.DoRequest(http.MethodGet, "/api/v2/policytemplates?"+query.Encode(), nil)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error listing policy templates: %v", err))
+		}
+
+		var page []PolicyTemplate
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return diag.FromErr(fmt.Errorf("error parsing policy templates response: %v", err))
+		}
+		templates = append(templates, page...)
+
+		if len(page) < 100 {
+			break
+		}
+		skip += len(page)
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+
+	d.SetId(fmt.Sprintf("system-policy-templates-%d", time.Now().Unix()))
+	if err := d.Set("templates", flattenPolicyTemplates(templates)); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting templates: %v", err))
+	}
+
+	return nil
+}
+
+// flattenPolicyTemplates converts the templates to the data source attributes
+func flattenPolicyTemplates(templates []PolicyTemplate) []interface{} {
+	result := make([]interface{}, 0, len(templates))
+	for _, template := range templates {
+		fields := make([]interface{}, 0, len(template.ConfigFields))
+		for _, field := range template.ConfigFields {
+			fields = append(fields, map[string]interface{}{
+				"id":            field.ID,
+				"name":          field.Name,
+				"label":         field.Label,
+				"type":          field.Type,
+				"required":      field.Required,
+				"sensitive":     field.Sensitive,
+				"default_value": stringifyPolicyValue(field.DefaultValue),
+			})
+		}
+		result = append(result, map[string]interface{}{
+			"id":             template.ID,
+			"name":           template.Name,
+			"display_name":   template.DisplayName,
+			"description":    template.Description,
+			"os_meta_family": template.OSMetaFamily,
+			"config_fields":  fields,
+		})
+	}
+	return result
+}
@@ -0,0 +1,19 @@
+package system_policies
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// changeGraphEdge adds or removes an edge through a v2 associations or members endpoint
+func changeGraphEdge(c common.ClientInterface, path, op, targetType, targetID string) error {
+	body, err := json.Marshal(common.GraphAssociationOp{Op: op, Type: targetType, ID: targetID})
+	if err != nil {
+		return fmt.Errorf("error serializing association: %v", err)
+	}
+	_, err = c.DoRequest(http.MethodPost, path, body)
+	return err
+}
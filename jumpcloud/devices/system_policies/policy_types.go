@@ -0,0 +1,46 @@
+package system_policies
+
+// PolicyConfigField describes a setting of a JumpCloud policy template
+type PolicyConfigField struct {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Label        string      `json:"label,omitempty"`
+	Type         string      `json:"type,omitempty"`
+	Required     bool        `json:"required,omitempty"`
+	Sensitive    bool        `json:"sensitive,omitempty"`
+	DefaultValue interface{} `json:"defaultValue,omitempty"`
+}
+
+// PolicyTemplate represents a JumpCloud policy template (/api/v2/policytemplates)
+type PolicyTemplate struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name,omitempty"`
+	DisplayName  string              `json:"displayName,omitempty"`
+	Description  string              `json:"description,omitempty"`
+	OSMetaFamily string              `json:"osMetaFamily,omitempty"`
+	ConfigFields []PolicyConfigField `json:"configFields,omitempty"`
+}
+
+// PolicyValue is the value of a template setting in a policy
+type PolicyValue struct {
+	ConfigFieldID string      `json:"configFieldID"`
+	Value         interface{} `json:"value"`
+	Sensitive     bool        `json:"sensitive,omitempty"`
+}
+
+// SystemPolicy represents a JumpCloud agent policy (/api/v2/policies)
+type SystemPolicy struct {
+	ID           string              `json:"id,omitempty"`
+	Name         string              `json:"name"`
+	Notes        string              `json:"notes,omitempty"`
+	Template     *PolicyTemplate     `json:"template,omitempty"`
+	ConfigFields []PolicyConfigField `json:"configFields,omitempty"`
+	Values       []PolicyValue       `json:"values,omitempty"`
+}
+
+// PolicyGroup represents a JumpCloud policy group (/api/v2/policygroups)
+type PolicyGroup struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,398 @@
+package system_policies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// ResourceSystemPolicy returns the resource for JumpCloud agent policies built from a policy template
+func ResourceSystemPolicy() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSystemPolicyCreate,
+		ReadContext:   resourceSystemPolicyRead,
+		UpdateContext: resourceSystemPolicyUpdate,
+		DeleteContext: resourceSystemPolicyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the policy",
+			},
+			"notes": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Notes about the policy",
+			},
+			"template_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the policy template, see the jumpcloud_system_policy_templates data source",
+			},
+			"values": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Settings of the template. Only the configured settings are tracked; the others keep the template defaults",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Name of the template setting",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "Value of the setting. Converted to the setting type: booleans for checkboxes, numbers for numeric settings and JSON lists for list settings",
+						},
+						"sensitive": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether JumpCloud stores the value as a secret. Settings the template marks as sensitive are always stored as secrets",
+						},
+					},
+				},
+			},
+			"template_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the policy template",
+			},
+			"os_meta_family": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Operating system family the policy applies to (darwin, windows, linux, ...)",
+			},
+		},
+	}
+}
+
+// systemPolicyRequest is the body sent to create or update a policy
+type systemPolicyRequest struct {
+	Name     string `json:"name"`
+	Notes    string `json:"notes,omitempty"`
+	Template struct {
+		ID string `json:"id"`
+	} `json:"template"`
+	Values []PolicyValue `json:"values"`
+}
+
+func resourceSystemPolicyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	body, err := buildSystemPolicyRequest(c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	policyJSON, err := json.Marshal(body)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing system policy: %v", err))
+	}
+
+	tflog.Debug(ctx, "Creating JumpCloud system policy", map[string]interface{}{"template_id": body.Template.ID})
+	resp, err := c.DoRequest(http.MethodPost, "/api/v2/policies", policyJSON)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error creating system policy: %v", err))
+	}
+
+	var policy SystemPolicy
+	if err := json.Unmarshal(resp, &policy); err != nil {
+		return diag.FromErr(fmt.Errorf("error parsing system policy response: %v", err))
+	}
+	if policy.ID == "" {
+		return diag.FromErr(fmt.Errorf("system policy created without an ID"))
+	}
+
+	d.SetId(policy.ID)
+	return resourceSystemPolicyRead(ctx, d, meta)
+}
+
+func resourceSystemPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/policies/%s", d.Id()), nil)
+	if err != nil {
+		if common.IsNotFoundError(err) {
+			tflog.Warn(ctx, fmt.Sprintf("System policy %s not found, removing from state", d.Id()))
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("error reading system policy %s: %v", d.Id(), err))
+	}
+
+	var policy SystemPolicy
+	if err := json.Unmarshal(resp, &policy); err != nil {
+		return diag.FromErr(fmt.Errorf("error parsing system policy response: %v", err))
+	}
+
+	fields := policy.ConfigFields
+	if len(fields) == 0 && policy.Template != nil && policy.Template.ID != "" {
+		template, err := getPolicyTemplate(c, policy.Template.ID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		fields = template.ConfigFields
+	}
+
+	if err := d.Set("name", policy.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting name: %v", err))
+	}
+	if err := d.Set("notes", policy.Notes); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting notes: %v", err))
+	}
+	if policy.Template != nil {
+		if err := d.Set("template_id", policy.Template.ID); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting template_id: %v", err))
+		}
+		if err := d.Set("template_name", policy.Template.Name); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting template_name: %v", err))
+		}
+		if err := d.Set("os_meta_family", policy.Template.OSMetaFamily); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting os_meta_family: %v", err))
+		}
+	}
+
+	values := flattenPolicyValues(policy.Values, fields, d.Get("values").(*schema.Set).List())
+	if err := d.Set("values", values); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting values: %v", err))
+	}
+
+	return nil
+}
+
+func resourceSystemPolicyUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	body, err := buildSystemPolicyRequest(c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	policyJSON, err := json.Marshal(body)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing system policy: %v", err))
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Updating JumpCloud system policy %s", d.Id()))
+	if _, err := c.DoRequest(http.MethodPut, fmt.Sprintf("/api/v2/policies/%s", d.Id()), policyJSON); err != nil {
+		return diag.FromErr(fmt.Errorf("error updating system policy %s: %v", d.Id(), err))
+	}
+
+	return resourceSystemPolicyRead(ctx, d, meta)
+}
+
+func resourceSystemPolicyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	if _, err := c.DoRequest(http.MethodDelete, fmt.Sprintf("/api/v2/policies/%s", d.Id()), nil); err != nil {
+		if common.IsNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("error deleting system policy %s: %v", d.Id(), err))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// buildSystemPolicyRequest resolves the configured settings against the template fields
+func buildSystemPolicyRequest(c common.ClientInterface, d *schema.ResourceData) (*systemPolicyRequest, error) {
+	templateID := d.Get("template_id").(string)
+	template, err := getPolicyTemplate(c, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := expandPolicyValues(d.Get("values").(*schema.Set).List(), template.ConfigFields)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &systemPolicyRequest{
+		Name:   d.Get("name").(string),
+		Notes:  d.Get("notes").(string),
+		Values: values,
+	}
+	body.Template.ID = templateID
+	return body, nil
+}
+
+// getPolicyTemplate fetches a policy template with its settings
+func getPolicyTemplate(c common.ClientInterface, id string) (*PolicyTemplate, error) {
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/policytemplates/%s", id), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy template %s: %v", id, err)
+	}
+
+	var template PolicyTemplate
+	if err := json.Unmarshal(resp, &template); err != nil {
+		return nil, fmt.Errorf("error parsing policy template response: %v", err)
+	}
+	return &template, nil
+}
+
+// expandPolicyValues converts the configured settings to API values typed after the template fields
+func expandPolicyValues(raw []interface{}, fields []PolicyConfigField) ([]PolicyValue, error) {
+	byName := make(map[string]PolicyConfigField, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	values := make([]PolicyValue, 0, len(raw))
+	for _, item := range raw {
+		v := item.(map[string]interface{})
+		name := v["field"].(string)
+
+		field, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("policy template has no setting %q, valid settings are: %s", name, strings.Join(policyFieldNames(fields), ", "))
+		}
+
+		value, err := convertPolicyValue(field, v["value"].(string))
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, PolicyValue{
+			ConfigFieldID: field.ID,
+			Value:         value,
+			Sensitive:     v["sensitive"].(bool) || field.Sensitive,
+		})
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].ConfigFieldID < values[j].ConfigFieldID })
+	return values, nil
+}
+
+// convertPolicyValue converts a configured value to the type of the template setting
+func convertPolicyValue(field PolicyConfigField, raw string) (interface{}, error) {
+	switch strings.ToLower(field.Type) {
+	case "checkbox", "boolean", "bool":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("setting %q expects true or false, got %q", field.Name, raw)
+		}
+		return b, nil
+	case "number", "integer", "int":
+		if i, err := strconv.Atoi(raw); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("setting %q expects a number, got %q", field.Name, raw)
+		}
+		return f, nil
+	case "table", "listbox", "multilist", "list":
+		var list interface{}
+		if err := json.Unmarshal([]byte(raw), &list); err != nil {
+			return nil, fmt.Errorf("setting %q expects a JSON encoded list, got %q", field.Name, raw)
+		}
+		return list, nil
+	}
+	return raw, nil
+}
+
+// flattenPolicyValues converts the API values to settings. Only the settings present in the
+// current configuration are kept so template defaults don't show up as drift; on import every
+// setting is returned. Sensitive values are not returned by the API and keep their configured value.
+// The sensitive flag only reflects the configuration, template fields are sensitive on their own
+func flattenPolicyValues(values []PolicyValue, fields []PolicyConfigField, configured []interface{}) []interface{} {
+	names := make(map[string]string, len(fields))
+	templateSensitive := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		names[field.ID] = field.Name
+		templateSensitive[field.ID] = field.Sensitive
+	}
+
+	current := make(map[string]map[string]interface{}, len(configured))
+	for _, item := range configured {
+		v := item.(map[string]interface{})
+		current[v["field"].(string)] = v
+	}
+
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		name, ok := names[value.ConfigFieldID]
+		if !ok {
+			name = value.ConfigFieldID
+		}
+
+		existing, tracked := current[name]
+		if len(current) > 0 && !tracked {
+			continue
+		}
+
+		item := map[string]interface{}{
+			"field":     name,
+			"value":     stringifyPolicyValue(value.Value),
+			"sensitive": value.Sensitive && !templateSensitive[value.ConfigFieldID],
+		}
+		if tracked {
+			item["sensitive"] = existing["sensitive"]
+			if value.Sensitive {
+				item["value"] = existing["value"]
+			}
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// stringifyPolicyValue renders an API value the way it is configured
+func stringifyPolicyValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(raw)
+	}
+}
+
+// policyFieldNames returns the setting names of a template in a stable order
+func policyFieldNames(fields []PolicyConfigField) []string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	sort.Strings(names)
+	return names
+}
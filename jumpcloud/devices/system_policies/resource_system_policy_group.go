@@ -0,0 +1,238 @@
+package system_policies
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// ResourceSystemPolicyGroup returns the resource for JumpCloud policy groups bundling system policies
+func ResourceSystemPolicyGroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSystemPolicyGroupCreate,
+		ReadContext:   resourceSystemPolicyGroupRead,
+		UpdateContext: resourceSystemPolicyGroupUpdate,
+		DeleteContext: resourceSystemPolicyGroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the policy group",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Description of the policy group",
+			},
+			"policy_ids": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the system policies in the group",
+			},
+		},
+	}
+}
+
+func resourceSystemPolicyGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	groupJSON, err := json.Marshal(PolicyGroup{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	})
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing policy group: %v", err))
+	}
+
+	tflog.Debug(ctx, "Creating JumpCloud policy group")
+	resp, err := c.DoRequest(http.MethodPost, "/api/v2/policygroups", groupJSON)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error creating policy group: %v", err))
+	}
+
+	var group PolicyGroup
+	if err := json.Unmarshal(resp, &group); err != nil {
+		return diag.FromErr(fmt.Errorf("error parsing policy group response: %v", err))
+	}
+	if group.ID == "" {
+		return diag.FromErr(fmt.Errorf("policy group created without an ID"))
+	}
+	d.SetId(group.ID)
+
+	if err := syncPolicyGroupMembers(c, group.ID, nil, setToStrings(d.Get("policy_ids").(*schema.Set))); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceSystemPolicyGroupRead(ctx, d, meta)
+}
+
+func resourceSystemPolicyGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/v2/policygroups/%s", d.Id()), nil)
+	if err != nil {
+		if common.IsNotFoundError(err) {
+			tflog.Warn(ctx, fmt.Sprintf("Policy group %s not found, removing from state", d.Id()))
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("error reading policy group %s: %v", d.Id(), err))
+	}
+
+	var group PolicyGroup
+	if err := json.Unmarshal(resp, &group); err != nil {
+		return diag.FromErr(fmt.Errorf("error parsing policy group response: %v", err))
+	}
+
+	members, err := listPolicyGroupMembers(c, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("name", group.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting name: %v", err))
+	}
+	if err := d.Set("description", group.Description); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting description: %v", err))
+	}
+	if err := d.Set("policy_ids", members); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting policy_ids: %v", err))
+	}
+
+	return nil
+}
+
+func resourceSystemPolicyGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	if d.HasChanges("name", "description") {
+		groupJSON, err := json.Marshal(PolicyGroup{
+			Name:        d.Get("name").(string),
+			Description: d.Get("description").(string),
+		})
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error serializing policy group: %v", err))
+		}
+
+		if _, err := c.DoRequest(http.MethodPut, fmt.Sprintf("/api/v2/policygroups/%s", d.Id()), groupJSON); err != nil {
+			return diag.FromErr(fmt.Errorf("error updating policy group %s: %v", d.Id(), err))
+		}
+	}
+
+	if d.HasChange("policy_ids") {
+		old, new := d.GetChange("policy_ids")
+		if err := syncPolicyGroupMembers(c, d.Id(), setToStrings(old.(*schema.Set)), setToStrings(new.(*schema.Set))); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return resourceSystemPolicyGroupRead(ctx, d, meta)
+}
+
+func resourceSystemPolicyGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	if _, err := c.DoRequest(http.MethodDelete, fmt.Sprintf("/api/v2/policygroups/%s", d.Id()), nil); err != nil {
+		if common.IsNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("error deleting policy group %s: %v", d.Id(), err))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// listPolicyGroupMembers returns the IDs of the policies in a policy group
+func listPolicyGroupMembers(c common.ClientInterface, groupID string) ([]string, error) {
+	edges, err := common.ListGraphAssociations(c, fmt.Sprintf("/api/v2/policygroups/%s/members", groupID))
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy group %s members: %v", groupID, err)
+	}
+
+	ids := make([]string, 0, len(edges))
+	for _, edge := range edges {
+		if edge.To.Type == "" || edge.To.Type == "policy" {
+			ids = append(ids, edge.To.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// syncPolicyGroupMembers adds and removes policies so the group holds exactly the wanted ones
+func syncPolicyGroupMembers(c common.ClientInterface, groupID string, current, wanted []string) error {
+	toAdd, toRemove := diffPolicyIDs(current, wanted)
+	path := fmt.Sprintf("/api/v2/policygroups/%s/members", groupID)
+
+	for _, id := range toRemove {
+		if err := changeGraphEdge(c, path, "remove", "policy", id); err != nil && !common.IsNotFoundError(err) {
+			return fmt.Errorf("error removing policy %s from policy group %s: %v", id, groupID, err)
+		}
+	}
+	for _, id := range toAdd {
+		if err := changeGraphEdge(c, path, "add", "policy", id); err != nil {
+			return fmt.Errorf("error adding policy %s to policy group %s: %v", id, groupID, err)
+		}
+	}
+	return nil
+}
+
+// diffPolicyIDs returns the IDs to add and to remove to go from current to wanted
+func diffPolicyIDs(current, wanted []string) ([]string, []string) {
+	inCurrent := make(map[string]bool, len(current))
+	for _, id := range current {
+		inCurrent[id] = true
+	}
+	inWanted := make(map[string]bool, len(wanted))
+	for _, id := range wanted {
+		inWanted[id] = true
+	}
+
+	var toAdd, toRemove []string
+	for _, id := range wanted {
+		if !inCurrent[id] {
+			toAdd = append(toAdd, id)
+		}
+	}
+	for _, id := range current {
+		if !inWanted[id] {
+			toRemove = append(toRemove, id)
+		}
+	}
+	sort.Strings(toAdd)
+	sort.Strings(toRemove)
+	return toAdd, toRemove
+}
+
+func setToStrings(s *schema.Set) []string {
+	result := make([]string, 0, s.Len())
+	for _, v := range s.List() {
+		result = append(result, v.(string))
+	}
+	return result
+}
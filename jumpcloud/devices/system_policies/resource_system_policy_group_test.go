@@ -0,0 +1,85 @@
+package system_policies
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// policyGroupTestClient records the requests and serves a fixed members response
+type policyGroupTestClient struct {
+	members  string
+	requests []string
+}
+
+func (c *policyGroupTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	c.requests = append(c.requests, method+" "+path+" "+string(body))
+	if method == "GET" {
+		return []byte(c.members), nil
+	}
+	return nil, nil
+}
+
+func (c *policyGroupTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *policyGroupTestClient) GetApiKey() string { return "test" }
+
+func (c *policyGroupTestClient) GetOrgID() string { return "" }
+
+func TestResourceSystemPolicyGroupSchema(t *testing.T) {
+	s := ResourceSystemPolicyGroup().Schema
+	for _, key := range []string{"name", "description", "policy_ids"} {
+		if s[key] == nil {
+			t.Errorf("expected %s in the schema", key)
+		}
+	}
+}
+
+func TestDiffPolicyIDs(t *testing.T) {
+	toAdd, toRemove := diffPolicyIDs([]string{"a", "b", "c"}, []string{"c", "d", "b"})
+	if !reflect.DeepEqual(toAdd, []string{"d"}) || !reflect.DeepEqual(toRemove, []string{"a"}) {
+		t.Errorf("unexpected diff: add %v, remove %v", toAdd, toRemove)
+	}
+}
+
+func TestSyncPolicyGroupMembers(t *testing.T) {
+	client := &policyGroupTestClient{}
+	if err := syncPolicyGroupMembers(client, "g1", []string{"p1", "p2"}, []string{"p2", "p3"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.requests) != 2 {
+		t.Fatalf("expected 2 requests, got %v", client.requests)
+	}
+	for i, op := range []string{"remove", "add"} {
+		req := client.requests[i]
+		if !strings.HasPrefix(req, "POST /api/v2/policygroups/g1/members ") {
+			t.Errorf("unexpected request %s", req)
+		}
+		var body map[string]string
+		if err := json.Unmarshal([]byte(req[strings.LastIndex(req, " {")+1:]), &body); err != nil {
+			t.Fatalf("unexpected body in %s: %v", req, err)
+		}
+		if body["op"] != op || body["type"] != "policy" {
+			t.Errorf("expected a %s policy operation, got %v", op, body)
+		}
+	}
+}
+
+func TestListPolicyGroupMembers(t *testing.T) {
+	client := &policyGroupTestClient{members: `[{"to":{"id":"p2","type":"policy"}},{"to":{"id":"p1","type":"policy"}}]`}
+	ids, err := listPolicyGroupMembers(client, "g1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"p1", "p2"}) {
+		t.Errorf("unexpected members %v", ids)
+	}
+	if !strings.HasPrefix(client.requests[0], "GET /api/v2/policygroups/g1/members?limit=100&skip=0") {
+		t.Errorf("unexpected request %s", client.requests[0])
+	}
+}
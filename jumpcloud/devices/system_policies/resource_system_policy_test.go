@@ -0,0 +1,122 @@
+package system_policies
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var testPolicyFields = []PolicyConfigField{
+	{ID: "f1", Name: "enabled", Type: "checkbox"},
+	{ID: "f2", Name: "timeout", Type: "number"},
+	{ID: "f3", Name: "excluded_users", Type: "table"},
+	{ID: "f4", Name: "message", Type: "text"},
+	{ID: "f5", Name: "recovery_key", Type: "text", Sensitive: true},
+}
+
+func TestResourceSystemPolicySchema(t *testing.T) {
+	s := ResourceSystemPolicy().Schema
+	for _, key := range []string{"name", "notes", "template_id", "values", "template_name", "os_meta_family"} {
+		if s[key] == nil {
+			t.Errorf("expected %s in the schema", key)
+		}
+	}
+	if !s["template_id"].ForceNew {
+		t.Error("expected template_id to force a new resource")
+	}
+}
+
+func TestConvertPolicyValue(t *testing.T) {
+	cases := []struct {
+		field int
+		raw   string
+		want  interface{}
+	}{
+		{0, "true", true},
+		{1, "300", 300},
+		{1, "1.5", 1.5},
+		{2, `["admin"]`, []interface{}{"admin"}},
+		{3, "Locked", "Locked"},
+	}
+	for _, tc := range cases {
+		got, err := convertPolicyValue(testPolicyFields[tc.field], tc.raw)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", tc.raw, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("convertPolicyValue(%q) = %#v, want %#v", tc.raw, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		field int
+		raw   string
+	}{{0, "yes"}, {1, "ten"}, {2, "admin"}} {
+		if _, err := convertPolicyValue(testPolicyFields[tc.field], tc.raw); err == nil {
+			t.Errorf("expected an error for %q", tc.raw)
+		}
+	}
+}
+
+func TestExpandPolicyValues(t *testing.T) {
+	values, err := expandPolicyValues([]interface{}{
+		map[string]interface{}{"field": "timeout", "value": "300", "sensitive": false},
+		map[string]interface{}{"field": "recovery_key", "value": "secret", "sensitive": false},
+	}, testPolicyFields)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(values) != 2 || values[0].ConfigFieldID != "f2" || values[0].Value != 300 {
+		t.Errorf("unexpected values: %#v", values)
+	}
+	if !values[1].Sensitive {
+		t.Error("expected sensitive template fields to be sent as sensitive")
+	}
+
+	_, err = expandPolicyValues([]interface{}{
+		map[string]interface{}{"field": "unknown", "value": "1", "sensitive": false},
+	}, testPolicyFields)
+	if err == nil || !strings.Contains(err.Error(), "enabled, excluded_users") {
+		t.Errorf("expected an error listing the valid settings, got %v", err)
+	}
+}
+
+func TestFlattenPolicyValues(t *testing.T) {
+	api := []PolicyValue{
+		{ConfigFieldID: "f1", Value: true},
+		{ConfigFieldID: "f2", Value: float64(300)},
+		{ConfigFieldID: "f3", Value: []interface{}{"admin"}},
+		{ConfigFieldID: "f5", Value: "", Sensitive: true},
+	}
+
+	all := flattenPolicyValues(api, testPolicyFields, nil)
+	if len(all) != 4 {
+		t.Fatalf("expected every setting on import, got %d", len(all))
+	}
+	if all[1].(map[string]interface{})["value"] != "300" || all[2].(map[string]interface{})["value"] != `["admin"]` {
+		t.Errorf("unexpected flattened values: %v", all)
+	}
+	if all[3].(map[string]interface{})["sensitive"] != false {
+		t.Errorf("expected sensitive template settings to keep the default flag, got %v", all[3])
+	}
+
+	tracked := flattenPolicyValues(api, testPolicyFields, []interface{}{
+		map[string]interface{}{"field": "enabled", "value": "true", "sensitive": false},
+		map[string]interface{}{"field": "recovery_key", "value": "secret", "sensitive": false},
+	})
+	if len(tracked) != 2 {
+		t.Fatalf("expected only the configured settings, got %v", tracked)
+	}
+	if tracked[1].(map[string]interface{})["value"] != "secret" {
+		t.Errorf("expected the configured sensitive value to be kept, got %v", tracked[1])
+	}
+	if tracked[1].(map[string]interface{})["sensitive"] != false {
+		t.Errorf("expected the configured sensitive flag to be kept, got %v", tracked[1])
+	}
+
+	if !ResourceSystemPolicy().Schema["values"].Elem.(*schema.Resource).Schema["value"].Sensitive {
+		t.Error("expected setting values to be marked sensitive")
+	}
+}
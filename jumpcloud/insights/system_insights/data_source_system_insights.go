@@ -0,0 +1,365 @@
+package system_insights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+var tableNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// insightsColumn is a column of a System Insights table exposed as a typed attribute
+type insightsColumn struct {
+	Name string
+	Type schema.ValueType
+}
+
+// typedInsightsTables lists the System Insights tables returned as typed rows. Any other table
+// is only available through rows_json
+var typedInsightsTables = map[string][]insightsColumn{
+	"apps": {
+		{"name", schema.TypeString},
+		{"path", schema.TypeString},
+		{"bundle_identifier", schema.TypeString},
+		{"bundle_name", schema.TypeString},
+		{"bundle_short_version", schema.TypeString},
+		{"bundle_version", schema.TypeString},
+	},
+	"programs": {
+		{"name", schema.TypeString},
+		{"version", schema.TypeString},
+		{"publisher", schema.TypeString},
+		{"install_location", schema.TypeString},
+		{"install_date", schema.TypeString},
+	},
+	"disk_encryption": {
+		{"name", schema.TypeString},
+		{"uuid", schema.TypeString},
+		{"encrypted", schema.TypeBool},
+		{"type", schema.TypeString},
+		{"encryption_status", schema.TypeString},
+		{"user_uuid", schema.TypeString},
+	},
+	"bitlocker_info": {
+		{"device_id", schema.TypeString},
+		{"drive_letter", schema.TypeString},
+		{"encryption_method", schema.TypeString},
+		{"protection_status", schema.TypeInt},
+		{"conversion_status", schema.TypeInt},
+		{"percentage_encrypted", schema.TypeInt},
+	},
+	"os_version": {
+		{"name", schema.TypeString},
+		{"version", schema.TypeString},
+		{"major", schema.TypeInt},
+		{"minor", schema.TypeInt},
+		{"patch", schema.TypeInt},
+		{"build", schema.TypeString},
+		{"platform", schema.TypeString},
+		{"platform_like", schema.TypeString},
+		{"codename", schema.TypeString},
+	},
+	"users": {
+		{"username", schema.TypeString},
+		{"uid", schema.TypeString},
+		{"gid", schema.TypeString},
+		{"description", schema.TypeString},
+		{"directory", schema.TypeString},
+		{"shell", schema.TypeString},
+		{"uuid", schema.TypeString},
+		{"type", schema.TypeString},
+	},
+	"browser_plugins": {
+		{"name", schema.TypeString},
+		{"identifier", schema.TypeString},
+		{"version", schema.TypeString},
+		{"description", schema.TypeString},
+		{"path", schema.TypeString},
+		{"uid", schema.TypeString},
+		{"disabled", schema.TypeBool},
+	},
+	"alf": {
+		{"global_state", schema.TypeInt},
+		{"logging_enabled", schema.TypeBool},
+		{"logging_option", schema.TypeInt},
+		{"stealth_enabled", schema.TypeBool},
+		{"allow_signed_enabled", schema.TypeBool},
+		{"firewall_unload", schema.TypeBool},
+		{"version", schema.TypeString},
+	},
+}
+
+// DataSourceSystemInsights returns the schema for the System Insights data source
+func DataSourceSystemInsights() *schema.Resource {
+	s := map[string]*schema.Schema{
+		"table": {
+			Type:         schema.TypeString,
+			Required:     true,
+			ValidateFunc: validation.StringMatch(tableNameRegex, "must be a System Insights table name, e.g. apps or disk_encryption"),
+			Description:  "System Insights table to query, e.g. apps, disk_encryption, os_version, users, browser_plugins or alf",
+		},
+		"system_ids": {
+			Type:        schema.TypeSet,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Restrict the rows to these devices",
+		},
+		"filter": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Column filters applied by the API. A row must match every filter",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"field": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Column to filter on",
+					},
+					"operator": {
+						Type:         schema.TypeString,
+						Optional:     true,
+						Default:      "eq",
+						ValidateFunc: validation.StringInSlice([]string{"eq", "ne", "gt", "ge", "lt", "le", "search", "in"}, false),
+						Description:  "Comparison operator (eq, ne, gt, ge, lt, le, search, in)",
+					},
+					"value": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Value to compare against. Separate the values of the in operator with '|'",
+					},
+				},
+			},
+		},
+		"matched_system_ids": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Distinct IDs of the devices with at least one row",
+		},
+		"rows_json": {
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: "Every row as a JSON object, available for all tables",
+		},
+	}
+
+	for table, columns := range typedInsightsTables {
+		s[table] = typedInsightsTableSchema(table, columns)
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceSystemInsightsRead,
+		Schema:      s,
+	}
+}
+
+// typedInsightsTableSchema builds the computed block holding the typed rows of a table
+func typedInsightsTableSchema(table string, columns []insightsColumn) *schema.Schema {
+	elem := map[string]*schema.Schema{
+		"system_id":       {Type: schema.TypeString, Computed: true},
+		"collection_time": {Type: schema.TypeString, Computed: true},
+	}
+	for _, column := range columns {
+		elem[column.Name] = &schema.Schema{Type: column.Type, Computed: true}
+	}
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: fmt.Sprintf("Typed rows, set when table is %s", table),
+		Elem:        &schema.Resource{Schema: elem},
+	}
+}
+
+func dataSourceSystemInsightsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	table := d.Get("table").(string)
+	filters := systemInsightsFilters(d)
+
+	rows, err := listSystemInsightsRows(ctx, c, table, filters)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rowsJSON := make([]string, 0, len(rows))
+	seen := make(map[string]bool)
+	systemIDs := make([]string, 0)
+	for _, row := range rows {
+		raw, err := json.Marshal(row)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error serializing System Insights row: %v", err))
+		}
+		rowsJSON = append(rowsJSON, string(raw))
+
+		if id := insightsString(row["system_id"]); id != "" && !seen[id] {
+			seen[id] = true
+			systemIDs = append(systemIDs, id)
+		}
+	}
+	sort.Strings(systemIDs)
+
+	d.SetId(fmt.Sprintf("system-insights-%s-%d", table, time.Now().Unix()))
+
+	if err := d.Set("rows_json", rowsJSON); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting rows_json: %v", err))
+	}
+	if err := d.Set("matched_system_ids", systemIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting matched_system_ids: %v", err))
+	}
+
+	if columns, ok := typedInsightsTables[table]; ok {
+		typed := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			typed = append(typed, flattenInsightsRow(row, columns))
+		}
+		if err := d.Set(table, typed); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting %s: %v", table, err))
+		}
+	} else {
+		tflog.Debug(ctx, fmt.Sprintf("System Insights table %s has no typed rows, use rows_json", table))
+	}
+
+	return nil
+}
+
+// systemInsightsFilters builds the API filter expressions from system_ids and the filter blocks
+func systemInsightsFilters(d *schema.ResourceData) []string {
+	var filters []string
+
+	if v, ok := d.GetOk("system_ids"); ok {
+		ids := make([]string, 0)
+		for _, id := range v.(*schema.Set).List() {
+			ids = append(ids, id.(string))
+		}
+		sort.Strings(ids)
+		if len(ids) == 1 {
+			filters = append(filters, "system_id:eq:"+ids[0])
+		} else if len(ids) > 1 {
+			filters = append(filters, "system_id:in:"+strings.Join(ids, "|"))
+		}
+	}
+
+	for _, item := range d.Get("filter").([]interface{}) {
+		f := item.(map[string]interface{})
+		filters = append(filters, fmt.Sprintf("%s:%s:%s", f["field"].(string), f["operator"].(string), f["value"].(string)))
+	}
+
+	return filters
+}
+
+// listSystemInsightsRows pages through a System Insights table
+func listSystemInsightsRows(ctx context.Context, c common.ClientInterface, table string, filters []string) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	skip := 0
+	for {
+		query := url.Values{}
+		query.Set("limit", "100")
+		query.Set("skip", strconv.Itoa(skip))
+		for _, filter := range filters {
+			query.Add("filter", filter)
+		}
+		path := fmt.Sprintf("/api/v2/systeminsights/%s?%s", table, query.Encode())
+
+		tflog.Debug(ctx, "Reading System Insights table", map[string]interface{}{"path": path})
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			if common.IsNotFoundError(err) {
+				return nil, fmt.Errorf("unknown System Insights table %q", table)
+			}
+			return nil, fmt.Errorf("error reading System Insights table %s: %v", table, err)
+		}
+
+		var page []map[string]interface{}
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing System Insights response: %v", err)
+		}
+		rows = append(rows, page...)
+
+		if len(page) < 100 {
+			break
+		}
+		skip += len(page)
+	}
+	return rows, nil
+}
+
+// flattenInsightsRow converts a row to the typed attributes of its table. osquery reports many
+// numbers and booleans as strings, so values are coerced to the column type
+func flattenInsightsRow(row map[string]interface{}, columns []insightsColumn) map[string]interface{} {
+	item := map[string]interface{}{
+		"system_id":       insightsString(row["system_id"]),
+		"collection_time": insightsString(row["collection_time"]),
+	}
+	for _, column := range columns {
+		value := row[column.Name]
+		switch column.Type {
+		case schema.TypeBool:
+			item[column.Name] = insightsBool(value)
+		case schema.TypeInt:
+			item[column.Name] = insightsInt(value)
+		default:
+			item[column.Name] = insightsString(value)
+		}
+	}
+	return item
+}
+
+func insightsString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func insightsInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(strings.TrimSpace(v))
+		return i
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+func insightsBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+		return insightsInt(v) != 0
+	}
+	return false
+}
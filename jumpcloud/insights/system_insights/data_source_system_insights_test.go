@@ -0,0 +1,127 @@
+package system_insights
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// insightsTestClient serves a System Insights table in pages of 100
+type insightsTestClient struct {
+	rows     []map[string]interface{}
+	requests []string
+}
+
+func (c *insightsTestClient) DoRequest(method, path string, body []byte) ([]byte, error) {
+	c.requests = append(c.requests, path)
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	var skip int
+	fmt.Sscanf(u.Query().Get("skip"), "%d", &skip)
+	end := skip + 100
+	if end > len(c.rows) {
+		end = len(c.rows)
+	}
+	return json.Marshal(c.rows[skip:end])
+}
+
+func (c *insightsTestClient) DoRequestWithContext(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	return c.DoRequest(method, path, body)
+}
+
+func (c *insightsTestClient) GetApiKey() string { return "test" }
+
+func (c *insightsTestClient) GetOrgID() string { return "" }
+
+func TestDataSourceSystemInsightsSchema(t *testing.T) {
+	s := DataSourceSystemInsights().Schema
+	for _, key := range []string{"table", "system_ids", "filter", "rows_json", "matched_system_ids"} {
+		if s[key] == nil {
+			t.Errorf("expected %s in the schema", key)
+		}
+	}
+	for table := range typedInsightsTables {
+		block := s[table]
+		if block == nil || !block.Computed {
+			t.Errorf("expected a computed %s block", table)
+			continue
+		}
+		if block.Elem.(*schema.Resource).Schema["system_id"] == nil {
+			t.Errorf("expected system_id in the %s rows", table)
+		}
+	}
+}
+
+func TestFlattenInsightsRow(t *testing.T) {
+	row := map[string]interface{}{
+		"system_id":         "5f1b",
+		"collection_time":   "2024-06-01T00:00:00Z",
+		"name":              "/dev/disk1s1",
+		"encrypted":         float64(1),
+		"encryption_status": "encrypted",
+	}
+	item := flattenInsightsRow(row, typedInsightsTables["disk_encryption"])
+	if item["encrypted"] != true {
+		t.Errorf("expected encrypted to be true, got %v", item["encrypted"])
+	}
+	if item["system_id"] != "5f1b" || item["name"] != "/dev/disk1s1" {
+		t.Errorf("unexpected row: %v", item)
+	}
+	if item["uuid"] != "" {
+		t.Errorf("expected missing columns to be empty, got %v", item["uuid"])
+	}
+
+	version := flattenInsightsRow(map[string]interface{}{"major": "14", "minor": float64(2)}, typedInsightsTables["os_version"])
+	if version["major"] != 14 || version["minor"] != 2 {
+		t.Errorf("expected numeric columns to be coerced, got %v", version)
+	}
+
+	alf := flattenInsightsRow(map[string]interface{}{"stealth_enabled": "1", "logging_enabled": "false"}, typedInsightsTables["alf"])
+	if alf["stealth_enabled"] != true || alf["logging_enabled"] != false {
+		t.Errorf("expected string booleans to be coerced, got %v", alf)
+	}
+}
+
+func TestSystemInsightsFilters(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, DataSourceSystemInsights().Schema, map[string]interface{}{
+		"table":      "apps",
+		"system_ids": []interface{}{"b", "a"},
+		"filter": []interface{}{
+			map[string]interface{}{"field": "name", "operator": "search", "value": "Chrome"},
+		},
+	})
+
+	filters := systemInsightsFilters(d)
+	expected := []string{"system_id:in:a|b", "name:search:Chrome"}
+	if strings.Join(filters, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected filters %v, got %v", expected, filters)
+	}
+}
+
+func TestListSystemInsightsRowsPaginates(t *testing.T) {
+	client := &insightsTestClient{}
+	for i := 0; i < 230; i++ {
+		client.rows = append(client.rows, map[string]interface{}{"system_id": fmt.Sprintf("%d", i%3), "name": "app"})
+	}
+
+	rows, err := listSystemInsightsRows(context.Background(), client, "apps", []string{"name:eq:app"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 230 {
+		t.Errorf("expected 230 rows, got %d", len(rows))
+	}
+	if len(client.requests) != 3 {
+		t.Errorf("expected 3 requests, got %d", len(client.requests))
+	}
+	if !strings.HasPrefix(client.requests[0], "/api/v2/systeminsights/apps?") || !strings.Contains(client.requests[0], "filter=name%3Aeq%3Aapp") {
+		t.Errorf("unexpected request path %s", client.requests[0])
+	}
+}
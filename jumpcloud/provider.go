@@ -21,6 +21,9 @@ import (
 	application_scim "registry.terraform.io/agilize/jumpcloud/jumpcloud/application/scim"
 	application_sso "registry.terraform.io/agilize/jumpcloud/jumpcloud/application/sso"
 
+	// Associations - Resources
+	associations "registry.terraform.io/agilize/jumpcloud/jumpcloud/associations"
+
 	// Authentication - Resources
 	authentication_attempts "registry.terraform.io/agilize/jumpcloud/jumpcloud/authentication/attempts"
 	authentication_conditional_access "registry.terraform.io/agilize/jumpcloud/jumpcloud/authentication/conditional_access"
@@ -35,9 +38,11 @@ import (
 	devices_software_management "registry.terraform.io/agilize/jumpcloud/jumpcloud/devices/software_management"
 	devices "registry.terraform.io/agilize/jumpcloud/jumpcloud/devices/system_devices"
 	device_groups "registry.terraform.io/agilize/jumpcloud/jumpcloud/devices/system_groups"
+	devices_system_policies "registry.terraform.io/agilize/jumpcloud/jumpcloud/devices/system_policies"
 
 	// Insights - Resources
 	insights_directory_insights "registry.terraform.io/agilize/jumpcloud/jumpcloud/insights/directory_insights"
+	insights_system_insights "registry.terraform.io/agilize/jumpcloud/jumpcloud/insights/system_insights"
 
 	// Organization - Resources
 	organization_alerts "registry.terraform.io/agilize/jumpcloud/jumpcloud/organization/alerts"
@@ -139,6 +144,7 @@ func Provider() *schema.Provider {
 			"jumpcloud_devices_command":             devices_commands.ResourceCommand(),
 			"jumpcloud_devices_command_association": devices_commands.ResourceCommandAssociation(),
 			"jumpcloud_devices_command_schedule":    devices_commands.ResourceCommandSchedule(),
+			"jumpcloud_devices_command_run":         devices_commands.ResourceCommandRun(),
 
 			// Device Groups - Resources
 			"jumpcloud_devices_group":            device_groups.ResourceGroup(),
@@ -153,11 +159,18 @@ func Provider() *schema.Provider {
 			"jumpcloud_devices_mdm_policy":             devices_mdm.ResourcePolicy(),
 			"jumpcloud_devices_mdm_profile":            devices_mdm.ResourceProfile(),
 			"jumpcloud_devices_mdm_device_action":      devices_mdm.ResourceDeviceAction(),
+			"jumpcloud_devices_mdm_push_certificate":   devices_mdm.ResourcePushCertificate(),
+			"jumpcloud_devices_mdm_ade_token":          devices_mdm.ResourceADEToken(),
 
 			// Devices Software Management - Resources
 			"jumpcloud_devices_software_package":       devices_software_management.ResourceSoftwarePackage(),
 			"jumpcloud_devices_software_update_policy": devices_software_management.ResourceSoftwareUpdatePolicy(),
 			"jumpcloud_devices_software_deployment":    devices_software_management.ResourceSoftwareDeployment(),
+			"jumpcloud_software_app":                   devices_software_management.ResourceSoftwareApp(),
+
+			// Devices System Policies - Resources
+			"jumpcloud_system_policy":       devices_system_policies.ResourceSystemPolicy(),
+			"jumpcloud_system_policy_group": devices_system_policies.ResourceSystemPolicyGroup(),
 
 			// Organization Alerts - Resources
 			"jumpcloud_organization_alert_configuration": organization_alerts.ResourceAlertConfiguration(),
@@ -190,15 +203,20 @@ func Provider() *schema.Provider {
 			// Password Policies - Resources
 			"jumpcloud_password_policy": password_policies.ResourcePasswordPolicy(),
 
+			// Associations - Resources
+			"jumpcloud_association": associations.ResourceAssociation(),
+
 			// User Association Resources
 			"jumpcloud_user_device_association": user_associations.ResourceSystem(),
 
 			// User Groups Resources
-			"jumpcloud_user_group":            user_groups.ResourceUserGroup(),
-			"jumpcloud_user_group_membership": user_groups.ResourceMembership(),
+			"jumpcloud_user_group":                          user_groups.ResourceUserGroup(),
+			"jumpcloud_user_group_membership":               user_groups.ResourceMembership(),
+			"jumpcloud_user_group_member_suggestion_review": user_groups.ResourceMemberSuggestionReview(),
 
 			// Users - Resources
-			"jumpcloud_user": users_directory.ResourceUser(),
+			"jumpcloud_user":         users_directory.ResourceUser(),
+			"jumpcloud_user_ssh_key": users_directory.ResourceUserSSHKey(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			// Admin Roles - Data Sources
@@ -208,8 +226,12 @@ func Provider() *schema.Provider {
 			"jumpcloud_admin_users": admin_users.DataSourceUsers(),
 
 			// Users - Data Sources
-			"jumpcloud_user":       users_directory.DataSourceUser(),
-			"jumpcloud_user_group": user_groups.DataSourceUserGroup(),
+			"jumpcloud_user":                          users_directory.DataSourceUser(),
+			"jumpcloud_user_reports":                  users_directory.DataSourceUserReports(),
+			"jumpcloud_user_effective_access":         user_associations.DataSourceEffectiveAccess(),
+			"jumpcloud_user_group":                    user_groups.DataSourceUserGroup(),
+			"jumpcloud_user_groups":                   user_groups.DataSourceUserGroups(),
+			"jumpcloud_user_group_member_suggestions": user_groups.DataSourceMemberSuggestions(),
 
 			// Application Catalog - Data Sources
 			"jumpcloud_application_catalog_application":  application_catalog.DataSourceApplication(),
@@ -245,11 +267,13 @@ func Provider() *schema.Provider {
 			"jumpcloud_authentication_radius_server": authentication_radius.DataSourceServer(),
 
 			// Devices Commands - Data Sources
-			"jumpcloud_devices_command": devices_commands.DataSourceCommand(),
+			"jumpcloud_devices_command":         devices_commands.DataSourceCommand(),
+			"jumpcloud_devices_command_results": devices_commands.DataSourceCommandResults(),
 
 			// Devices System - Data Sources
 			"jumpcloud_devices_group": device_groups.DataSourceGroup(),
 			"jumpcloud_devices":       devices.DataSourceSystem(),
+			"jumpcloud_devices_list":  devices.DataSourceDevicesList(),
 
 			// Devices MDM - Data Sources
 			"jumpcloud_devices_mdm_stats":    devices_mdm.DataSourceStats(),
@@ -260,10 +284,18 @@ func Provider() *schema.Provider {
 			"jumpcloud_devices_software_packages":          devices_software_management.DataSourceSoftwarePackages(),
 			"jumpcloud_devices_software_update_policies":   devices_software_management.DataSourceSoftwareUpdatePolicies(),
 			"jumpcloud_devices_software_deployment_status": devices_software_management.DataSourceSoftwareDeploymentStatus(),
+			"jumpcloud_software_app_statuses":              devices_software_management.DataSourceSoftwareAppStatuses(),
+
+			// Devices System Policies - Data Sources
+			"jumpcloud_system_policy_templates": devices_system_policies.DataSourceSystemPolicyTemplates(),
+			"jumpcloud_system_policy_group":     devices_system_policies.DataSourceSystemPolicyGroup(),
 
 			// Directory Insights - Data Sources
 			"jumpcloud_directory_insights_events": insights_directory_insights.DataSourceEvents(),
 
+			// System Insights - Data Sources
+			"jumpcloud_system_insights": insights_system_insights.DataSourceSystemInsights(),
+
 			// Organization Alerts - Data Sources
 			"jumpcloud_organization_alerts":          organization_alerts.DataSourceAlerts(),
 			"jumpcloud_organization_alert_templates": organization_alerts.DataSourceAlertTemplates(),
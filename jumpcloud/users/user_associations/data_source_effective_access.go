@@ -0,0 +1,264 @@
+package user_associations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// graphPageSize is the number of entries requested per page from the graph traversal endpoints
+const graphPageSize = 100
+
+// effectiveAccessEndpoints maps each reported resource type to its v2 traversal endpoint
+// and the attribute listing its IDs
+var effectiveAccessEndpoints = map[string]struct {
+	path      string
+	attribute string
+}{
+	"system":        {"systems", "system_ids"},
+	"application":   {"applications", "application_ids"},
+	"radius_server": {"radiusservers", "radius_server_ids"},
+	"ldap_server":   {"ldapservers", "ldap_server_ids"},
+	"user_group":    {"memberof", "user_group_ids"},
+}
+
+// effectiveAccessEntry is the flattened view of a graph connection
+type effectiveAccessEntry struct {
+	ID          string
+	Type        string
+	Direct      bool
+	ViaGroupIDs []string
+	Paths       []string
+}
+
+// DataSourceEffectiveAccess returns the data source reporting everything a user can reach
+func DataSourceEffectiveAccess() *schema.Resource {
+	resourceTypes := make([]string, 0, len(effectiveAccessEndpoints))
+	for t := range effectiveAccessEndpoints {
+		resourceTypes = append(resourceTypes, t)
+	}
+	sort.Strings(resourceTypes)
+
+	idListSchema := func(description string) *schema.Schema {
+		return &schema.Schema{
+			Type:        schema.TypeList,
+			Computed:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+			Description: description,
+		}
+	}
+
+	return &schema.Resource{
+		ReadContext: dataSourceEffectiveAccessRead,
+		Schema: map[string]*schema.Schema{
+			"user_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the JumpCloud user",
+			},
+			"resource_types": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(resourceTypes, false),
+				},
+				Description: fmt.Sprintf("Resource types to report. Defaults to all of: %s", strings.Join(resourceTypes, ", ")),
+			},
+			// Output fields
+			"resources": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Every resource the user can reach, with the paths that grant access",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the resource",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Type of the resource",
+						},
+						"direct": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the resource is bound directly to the user",
+						},
+						"via_group_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "IDs of the user groups through which the resource is granted",
+						},
+						"paths": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Human-readable paths granting access, e.g. 'user_group:<id> -> system:<id>'",
+						},
+					},
+				},
+			},
+			"system_ids":        idListSchema("IDs of the systems the user can reach"),
+			"application_ids":   idListSchema("IDs of the applications the user can reach"),
+			"radius_server_ids": idListSchema("IDs of the RADIUS servers the user can reach"),
+			"ldap_server_ids":   idListSchema("IDs of the LDAP servers the user can reach"),
+			"user_group_ids":    idListSchema("IDs of the user groups the user is a member of"),
+		},
+		Description: "Reports every system, application, RADIUS server, LDAP server and user group a JumpCloud user can reach, directly or through groups.",
+	}
+}
+
+func dataSourceEffectiveAccessRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	userID := d.Get("user_id").(string)
+
+	var types []string
+	if v, ok := d.GetOk("resource_types"); ok {
+		for _, t := range v.(*schema.Set).List() {
+			types = append(types, t.(string))
+		}
+	} else {
+		for t := range effectiveAccessEndpoints {
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+
+	var entries []effectiveAccessEntry
+	idsByAttribute := make(map[string][]string)
+
+	for _, t := range types {
+		endpoint := effectiveAccessEndpoints[t]
+		connections, err := listGraphConnections(ctx, c, fmt.Sprintf("/api/v2/users/%s/%s", userID, endpoint.path))
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error reading %s access for user %s: %v", t, userID, err))
+		}
+
+		ids := make([]string, 0, len(connections))
+		for _, conn := range connections {
+			entry := flattenGraphConnection(conn)
+			if entry.Type == "" {
+				entry.Type = t
+			}
+			entries = append(entries, entry)
+			ids = append(ids, conn.ID)
+		}
+		idsByAttribute[endpoint.attribute] = ids
+	}
+
+	resources := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		resources = append(resources, map[string]interface{}{
+			"id":            e.ID,
+			"type":          e.Type,
+			"direct":        e.Direct,
+			"via_group_ids": e.ViaGroupIDs,
+			"paths":         e.Paths,
+		})
+	}
+
+	d.SetId(userID)
+
+	if err := d.Set("resources", resources); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting resources: %v", err))
+	}
+
+	for _, endpoint := range effectiveAccessEndpoints {
+		ids := idsByAttribute[endpoint.attribute]
+		if ids == nil {
+			ids = []string{}
+		}
+		if err := d.Set(endpoint.attribute, ids); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting %s: %v", endpoint.attribute, err))
+		}
+	}
+
+	return diags
+}
+
+// flattenGraphConnection summarizes the traversal paths of a graph connection.
+// A path of a single hop is a direct binding, longer paths go through groups.
+func flattenGraphConnection(conn common.GraphConnection) effectiveAccessEntry {
+	entry := effectiveAccessEntry{
+		ID:          conn.ID,
+		Type:        conn.Type,
+		ViaGroupIDs: []string{},
+		Paths:       []string{},
+	}
+
+	seenGroups := make(map[string]bool)
+	for _, path := range conn.Paths {
+		if len(path) <= 1 {
+			entry.Direct = true
+		}
+
+		hops := make([]string, 0, len(path))
+		for i, edge := range path {
+			hops = append(hops, fmt.Sprintf("%s:%s", edge.To.Type, edge.To.ID))
+
+			// The last hop is the resource itself
+			if i < len(path)-1 && edge.To.Type == "user_group" && !seenGroups[edge.To.ID] {
+				seenGroups[edge.To.ID] = true
+				entry.ViaGroupIDs = append(entry.ViaGroupIDs, edge.To.ID)
+			}
+		}
+		if len(hops) > 0 {
+			entry.Paths = append(entry.Paths, strings.Join(hops, " -> "))
+		}
+	}
+
+	// Connections reported without paths are direct bindings
+	if len(conn.Paths) == 0 {
+		entry.Direct = true
+	}
+
+	sort.Strings(entry.ViaGroupIDs)
+	return entry
+}
+
+// listGraphConnections retrieves every entry of a v2 graph traversal endpoint, following pagination
+func listGraphConnections(ctx context.Context, c common.ClientInterface, basePath string) ([]common.GraphConnection, error) {
+	var connections []common.GraphConnection
+
+	for skip := 0; ; skip += graphPageSize {
+		path := fmt.Sprintf("%s?limit=%d&skip=%d", basePath, graphPageSize, skip)
+		tflog.Debug(ctx, fmt.Sprintf("Reading graph connections with URL: %s", path))
+
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []common.GraphConnection
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing graph response: %v", err)
+		}
+
+		connections = append(connections, page...)
+
+		if len(page) < graphPageSize {
+			break
+		}
+	}
+
+	return connections, nil
+}
@@ -0,0 +1,78 @@
+package user_associations
+
+import (
+	"reflect"
+	"testing"
+
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// TestDataSourceEffectiveAccessSchema tests the schema structure of the effective access data source
+func TestDataSourceEffectiveAccessSchema(t *testing.T) {
+	s := DataSourceEffectiveAccess()
+
+	if s.Schema["user_id"] == nil || !s.Schema["user_id"].Required {
+		t.Error("Expected user_id to be a required field")
+	}
+	for _, endpoint := range effectiveAccessEndpoints {
+		if s.Schema[endpoint.attribute] == nil || !s.Schema[endpoint.attribute].Computed {
+			t.Errorf("Expected %s to be a computed field", endpoint.attribute)
+		}
+	}
+	if s.Schema["resources"] == nil || !s.Schema["resources"].Computed {
+		t.Error("Expected resources to be a computed field")
+	}
+}
+
+func TestFlattenGraphConnection(t *testing.T) {
+	conn := common.GraphConnection{
+		ID:   "sys1",
+		Type: "system",
+		Paths: [][]common.GraphPathEdge{
+			{
+				{To: common.GraphObject{ID: "sys1", Type: "system"}},
+			},
+			{
+				{To: common.GraphObject{ID: "grp2", Type: "user_group"}},
+				{To: common.GraphObject{ID: "sys1", Type: "system"}},
+			},
+			{
+				{To: common.GraphObject{ID: "grp1", Type: "user_group"}},
+				{To: common.GraphObject{ID: "sys1", Type: "system"}},
+			},
+			{
+				{To: common.GraphObject{ID: "grp1", Type: "user_group"}},
+				{To: common.GraphObject{ID: "sysgrp", Type: "system_group"}},
+				{To: common.GraphObject{ID: "sys1", Type: "system"}},
+			},
+		},
+	}
+
+	entry := flattenGraphConnection(conn)
+	if !entry.Direct {
+		t.Error("Expected connection with a single-hop path to be direct")
+	}
+	if !reflect.DeepEqual(entry.ViaGroupIDs, []string{"grp1", "grp2"}) {
+		t.Errorf("Unexpected via_group_ids: %v", entry.ViaGroupIDs)
+	}
+	if len(entry.Paths) != 4 {
+		t.Fatalf("Expected 4 paths, got %d", len(entry.Paths))
+	}
+	if entry.Paths[3] != "user_group:grp1 -> system_group:sysgrp -> system:sys1" {
+		t.Errorf("Unexpected path: %s", entry.Paths[3])
+	}
+
+	indirect := flattenGraphConnection(common.GraphConnection{
+		ID:   "app1",
+		Type: "application",
+		Paths: [][]common.GraphPathEdge{
+			{
+				{To: common.GraphObject{ID: "grp1", Type: "user_group"}},
+				{To: common.GraphObject{ID: "app1", Type: "application"}},
+			},
+		},
+	})
+	if indirect.Direct {
+		t.Error("Expected connection only reached through a group to be indirect")
+	}
+}
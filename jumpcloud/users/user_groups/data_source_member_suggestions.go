@@ -0,0 +1,149 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// DataSourceMemberSuggestions returns the data source listing pending membership suggestions of a user group
+func DataSourceMemberSuggestions() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceMemberSuggestionsRead,
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the user group. Suggestions are only produced for DYNAMIC_REVIEW_REQUIRED groups",
+			},
+			// Output fields
+			"suggestions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Pending membership suggestions",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"user_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the suggested user",
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Username of the suggested user",
+						},
+						"email": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Email of the suggested user",
+						},
+						"operation": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Suggested operation, 'add' or 'remove'",
+						},
+					},
+				},
+			},
+			"add_user_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the users suggested for addition",
+			},
+			"remove_user_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the users suggested for removal",
+			},
+		},
+	}
+}
+
+func dataSourceMemberSuggestionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	groupID := d.Get("group_id").(string)
+
+	suggestions, err := listMemberSuggestions(ctx, c, groupID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	result := make([]map[string]interface{}, 0, len(suggestions))
+	addIDs := make([]string, 0)
+	removeIDs := make([]string, 0)
+	for _, s := range suggestions {
+		result = append(result, map[string]interface{}{
+			"user_id":   s.Object.ID,
+			"username":  s.Object.Username,
+			"email":     s.Object.Email,
+			"operation": s.Op,
+		})
+		if s.Op == "remove" {
+			removeIDs = append(removeIDs, s.Object.ID)
+		} else {
+			addIDs = append(addIDs, s.Object.ID)
+		}
+	}
+
+	d.SetId(groupID)
+
+	if err := d.Set("suggestions", result); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting suggestions: %v", err))
+	}
+	if err := d.Set("add_user_ids", addIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting add_user_ids: %v", err))
+	}
+	if err := d.Set("remove_user_ids", removeIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting remove_user_ids: %v", err))
+	}
+
+	return diags
+}
+
+// listMemberSuggestions retrieves every pending membership suggestion of a user group, sorted by username
+func listMemberSuggestions(ctx context.Context, c common.ClientInterface, groupID string) ([]common.UserGroupMemberSuggestion, error) {
+	var suggestions []common.UserGroupMemberSuggestion
+
+	for skip := 0; ; skip += userGroupsPageSize {
+		path := fmt.Sprintf("/api/v2/usergroups/%s/suggestions?limit=%d&skip=%d", groupID, userGroupsPageSize, skip)
+		tflog.Debug(ctx, fmt.Sprintf("Listing member suggestions with URL: %s", path))
+
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error listing member suggestions of user group %s: %w", groupID, err)
+		}
+
+		var page []common.UserGroupMemberSuggestion
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing member suggestions response: %v", err)
+		}
+
+		suggestions = append(suggestions, page...)
+
+		if len(page) < userGroupsPageSize {
+			break
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Object.Username < suggestions[j].Object.Username
+	})
+
+	return suggestions, nil
+}
@@ -0,0 +1,291 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// userGroupsFilter holds the client-side filters of the user groups data source
+type userGroupsFilter struct {
+	NamePrefix       string
+	NameRegex        *regexp.Regexp
+	MembershipMethod string
+	Attributes       map[string]string
+}
+
+// DataSourceUserGroups returns the data source listing every user group matching a set of filters
+func DataSourceUserGroups() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceUserGroupsRead,
+		Schema: map[string]*schema.Schema{
+			"name_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return groups whose name starts with this prefix",
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+				Description:  "Only return groups whose name matches this regular expression",
+			},
+			"membership_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"STATIC", "DYNAMIC_REVIEW_REQUIRED", "DYNAMIC_AUTOMATED"}, false),
+				Description:  "Only return groups with this membership method (STATIC, DYNAMIC_REVIEW_REQUIRED or DYNAMIC_AUTOMATED)",
+			},
+			"attribute": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Only return groups having this custom attribute. All attribute blocks must match",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Name of the attribute",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Expected value of the attribute. When omitted, only the presence of the attribute is checked",
+						},
+					},
+				},
+			},
+			"include_member_count": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether to count the members of each matching group. Requires one additional request per group",
+			},
+			// Output fields
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the matching groups, sorted by name",
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of the matching groups, sorted",
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching groups, sorted by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the user group",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the user group",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The description of the user group",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the user group",
+						},
+						"membership_method": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Method for determining group membership",
+						},
+						"attributes": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Custom attributes for the user group",
+						},
+						"member_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of users in the group, -1 when include_member_count is false",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceUserGroupsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	filter := userGroupsFilter{
+		NamePrefix:       d.Get("name_prefix").(string),
+		MembershipMethod: d.Get("membership_method").(string),
+		Attributes:       make(map[string]string),
+	}
+	if v, ok := d.GetOk("name_regex"); ok {
+		re, err := regexp.Compile(v.(string))
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("invalid name_regex: %v", err))
+		}
+		filter.NameRegex = re
+	}
+	for _, a := range d.Get("attribute").([]interface{}) {
+		attr := a.(map[string]interface{})
+		filter.Attributes[attr["key"].(string)] = attr["value"].(string)
+	}
+
+	// A name prefix can be narrowed down server-side with a search filter
+	serverFilter := ""
+	if filter.NamePrefix != "" {
+		serverFilter = fmt.Sprintf("name:search:%s", filter.NamePrefix)
+	}
+
+	groups, err := listUserGroups(ctx, c, serverFilter)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var matched []common.UserGroup
+	for _, g := range groups {
+		if filter.matches(g) {
+			matched = append(matched, g)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Name < matched[j].Name
+	})
+
+	includeCount := d.Get("include_member_count").(bool)
+
+	ids := make([]string, 0, len(matched))
+	names := make([]string, 0, len(matched))
+	result := make([]map[string]interface{}, 0, len(matched))
+	for _, g := range matched {
+		memberCount := -1
+		if includeCount {
+			memberCount, err = countUserGroupMembers(ctx, c, g.ID)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+		}
+
+		attributes := make(map[string]interface{})
+		for k, v := range g.Attributes {
+			switch v.(type) {
+			case map[string]interface{}, []interface{}:
+				// Nested attributes such as ldapGroups are not representable as strings
+				continue
+			default:
+				attributes[k] = fmt.Sprintf("%v", v)
+			}
+		}
+
+		ids = append(ids, g.ID)
+		names = append(names, g.Name)
+		result = append(result, map[string]interface{}{
+			"id":                g.ID,
+			"name":              g.Name,
+			"description":       g.Description,
+			"type":              g.Type,
+			"membership_method": g.MembershipMethod,
+			"attributes":        attributes,
+			"member_count":      memberCount,
+		})
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Found %d user groups matching filters out of %d", len(matched), len(groups)))
+
+	d.SetId(fmt.Sprintf("%d", schema.HashString(strings.Join(ids, ","))))
+
+	if err := d.Set("ids", ids); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting ids: %v", err))
+	}
+	if err := d.Set("names", names); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting names: %v", err))
+	}
+	if err := d.Set("groups", result); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting groups: %v", err))
+	}
+
+	return diags
+}
+
+// matches reports whether a group satisfies every configured filter
+func (f userGroupsFilter) matches(g common.UserGroup) bool {
+	if f.NamePrefix != "" && !strings.HasPrefix(g.Name, f.NamePrefix) {
+		return false
+	}
+	if f.NameRegex != nil && !f.NameRegex.MatchString(g.Name) {
+		return false
+	}
+	if f.MembershipMethod != "" {
+		// Groups created without a method are static
+		method := g.MembershipMethod
+		if method == "" {
+			method = "STATIC"
+		}
+		if method != f.MembershipMethod {
+			return false
+		}
+	}
+	for key, value := range f.Attributes {
+		actual, ok := g.Attributes[key]
+		if !ok {
+			return false
+		}
+		if value != "" && fmt.Sprintf("%v", actual) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// countUserGroupMembers returns the number of users in a group, following pagination
+func countUserGroupMembers(ctx context.Context, c common.ClientInterface, groupID string) (int, error) {
+	count := 0
+
+	for skip := 0; ; skip += userGroupsPageSize {
+		path := fmt.Sprintf("/api/v2/usergroups/%s/members?limit=%d&skip=%d", groupID, userGroupsPageSize, skip)
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return 0, fmt.Errorf("error listing members of user group %s: %v", groupID, err)
+		}
+
+		var members []json.RawMessage
+		if err := json.Unmarshal(resp, &members); err != nil {
+			return 0, fmt.Errorf("error parsing members of user group %s: %v", groupID, err)
+		}
+
+		count += len(members)
+
+		if len(members) < userGroupsPageSize {
+			break
+		}
+	}
+
+	return count, nil
+}
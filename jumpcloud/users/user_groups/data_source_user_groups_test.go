@@ -0,0 +1,60 @@
+package users
+
+import (
+	"regexp"
+	"testing"
+
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// TestDataSourceUserGroupsSchema tests the schema structure of the user groups data source
+func TestDataSourceUserGroupsSchema(t *testing.T) {
+	s := DataSourceUserGroups()
+
+	for _, field := range []string{"name_prefix", "name_regex", "membership_method", "attribute", "include_member_count"} {
+		if s.Schema[field] == nil || !s.Schema[field].Optional {
+			t.Errorf("Expected %s to be an optional field", field)
+		}
+	}
+	for _, field := range []string{"ids", "names", "groups"} {
+		if s.Schema[field] == nil || !s.Schema[field].Computed {
+			t.Errorf("Expected %s to be a computed field", field)
+		}
+	}
+}
+
+func TestUserGroupsFilterMatches(t *testing.T) {
+	appGroup := common.UserGroup{
+		Name:             "app-billing",
+		MembershipMethod: "DYNAMIC_AUTOMATED",
+		Attributes:       map[string]interface{}{"team": "finance", "sudo": map[string]interface{}{"enabled": true}},
+	}
+	staticGroup := common.UserGroup{Name: "engineering"}
+
+	cases := []struct {
+		name   string
+		filter userGroupsFilter
+		group  common.UserGroup
+		want   bool
+	}{
+		{"no filters", userGroupsFilter{}, staticGroup, true},
+		{"prefix match", userGroupsFilter{NamePrefix: "app-"}, appGroup, true},
+		{"prefix mismatch", userGroupsFilter{NamePrefix: "app-"}, staticGroup, false},
+		{"regex match", userGroupsFilter{NameRegex: regexp.MustCompile(`^app-(billing|sales)$`)}, appGroup, true},
+		{"regex mismatch", userGroupsFilter{NameRegex: regexp.MustCompile(`^app-sales$`)}, appGroup, false},
+		{"method match", userGroupsFilter{MembershipMethod: "DYNAMIC_AUTOMATED"}, appGroup, true},
+		{"empty method is static", userGroupsFilter{MembershipMethod: "STATIC"}, staticGroup, true},
+		{"attribute value match", userGroupsFilter{Attributes: map[string]string{"team": "finance"}}, appGroup, true},
+		{"attribute value mismatch", userGroupsFilter{Attributes: map[string]string{"team": "sales"}}, appGroup, false},
+		{"attribute presence", userGroupsFilter{Attributes: map[string]string{"sudo": ""}}, appGroup, true},
+		{"attribute missing", userGroupsFilter{Attributes: map[string]string{"team": ""}}, staticGroup, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.matches(tc.group); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// memberQueryFilters extracts the filters of a member_query block, regardless of the query type
+func memberQueryFilters(input []interface{}) []common.UserGroupFilter {
+	if len(input) == 0 || input[0] == nil {
+		return nil
+	}
+
+	queryMap := input[0].(map[string]interface{})
+
+	var items []interface{}
+	if filterSet, ok := queryMap["filter"].(*schema.Set); ok {
+		items = filterSet.List()
+	} else if filterList, ok := queryMap["filter"].([]interface{}); ok {
+		items = filterList
+	}
+
+	filters := make([]common.UserGroupFilter, 0, len(items))
+	for _, item := range items {
+		filterMap := item.(map[string]interface{})
+		filters = append(filters, common.UserGroupFilter{
+			Field:    filterMap["field"].(string),
+			Operator: filterMap["operator"].(string),
+			Value:    filterMap["value"].(string),
+		})
+	}
+
+	return filters
+}
+
+// previewMemberQuery returns the sorted IDs of the users matching every filter, excluding exempted users
+func previewMemberQuery(users []map[string]interface{}, filters []common.UserGroupFilter, exemptions []common.UserGroupExemption) []string {
+	exempt := make(map[string]bool, len(exemptions))
+	for _, e := range exemptions {
+		exempt[e.ID] = true
+	}
+
+	ids := make([]string, 0)
+	if len(filters) == 0 {
+		return ids
+	}
+
+	for _, user := range users {
+		id, _ := user["_id"].(string)
+		if id == "" || exempt[id] {
+			continue
+		}
+
+		matched := true
+		for _, f := range filters {
+			if !memberQueryFilterMatches(user, f) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// memberQueryFilterMatches evaluates a single member query filter against a user
+func memberQueryFilterMatches(user map[string]interface{}, f common.UserGroupFilter) bool {
+	actual, found := memberQueryUserValue(user, f.Field)
+
+	switch f.Operator {
+	case "eq":
+		return found && actual == f.Value
+	case "ne":
+		return !found || actual != f.Value
+	case "in":
+		if !found {
+			return false
+		}
+		for _, v := range strings.Split(f.Value, "|") {
+			if strings.TrimSpace(v) == actual {
+				return true
+			}
+		}
+		return false
+	case "gt", "ge", "lt", "le":
+		if !found {
+			return false
+		}
+		cmp := compareMemberQueryValues(actual, f.Value)
+		switch f.Operator {
+		case "gt":
+			return cmp > 0
+		case "ge":
+			return cmp >= 0
+		case "lt":
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	}
+
+	return false
+}
+
+// memberQueryUserValue resolves a filter field against a user returned by /api/systemusers,
+// using the same field mapping as the queries sent to the API
+func memberQueryUserValue(user map[string]interface{}, field string) (string, bool) {
+	apiField := convertFieldForAPI(field)
+
+	if strings.HasPrefix(apiField, "attributes[name=") {
+		name := strings.TrimSuffix(strings.TrimPrefix(apiField, "attributes[name="), "].value")
+		attributes, _ := user["attributes"].([]interface{})
+		for _, a := range attributes {
+			attr, ok := a.(map[string]interface{})
+			if !ok || attr["name"] != name {
+				continue
+			}
+			if attr["value"] == nil {
+				return "", false
+			}
+			return fmt.Sprintf("%v", attr["value"]), true
+		}
+		return "", false
+	}
+
+	value, ok := user[apiField]
+	if !ok || value == nil {
+		return "", false
+	}
+	return fmt.Sprintf("%v", value), true
+}
+
+// compareMemberQueryValues compares two values numerically when both are numbers, lexically otherwise
+func compareMemberQueryValues(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// listUsersForPreview retrieves every user as a raw object, following pagination
+func listUsersForPreview(ctx context.Context, c common.ClientInterface) ([]map[string]interface{}, error) {
+	var users []map[string]interface{}
+
+	for skip := 0; ; skip += userGroupsPageSize {
+		path := fmt.Sprintf("/api/systemusers?limit=%d&skip=%d", userGroupsPageSize, skip)
+		tflog.Debug(ctx, fmt.Sprintf("Listing users for member query preview with URL: %s", path))
+
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error listing users: %v", err)
+		}
+
+		var page struct {
+			Results    []map[string]interface{} `json:"results"`
+			TotalCount int                      `json:"totalCount"`
+		}
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing users response: %v", err)
+		}
+
+		users = append(users, page.Results...)
+
+		if len(page.Results) < userGroupsPageSize {
+			break
+		}
+	}
+
+	return users, nil
+}
+
+// customizeUserGroupDiff refreshes member_query_preview at plan time when preview_member_query is enabled
+func customizeUserGroupDiff(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+	if !diff.Get("preview_member_query").(bool) {
+		return nil
+	}
+
+	if !diff.NewValueKnown("member_query") || !diff.NewValueKnown("member_query_exemptions") {
+		return diff.SetNewComputed("member_query_preview")
+	}
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return fmt.Errorf("error getting client for member query preview: %v", diagErr[0].Summary)
+	}
+
+	users, err := listUsersForPreview(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	exemptions, err := expandMemberQueryExemptions(diff.Get("member_query_exemptions").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	preview := previewMemberQuery(users, memberQueryFilters(diff.Get("member_query").([]interface{})), exemptions)
+
+	current := make([]string, 0)
+	for _, id := range diff.Get("member_query_preview").([]interface{}) {
+		current = append(current, id.(string))
+	}
+	if strings.Join(current, ",") == strings.Join(preview, ",") {
+		return nil
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("member_query would match %d users", len(preview)))
+	return diff.SetNew("member_query_preview", preview)
+}
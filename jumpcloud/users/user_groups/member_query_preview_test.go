@@ -0,0 +1,59 @@
+package users
+
+import (
+	"reflect"
+	"testing"
+
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+func TestPreviewMemberQuery(t *testing.T) {
+	users := []map[string]interface{}{
+		{
+			"_id":        "u1",
+			"department": "Engineering",
+			"state":      "ACTIVATED",
+			"costCenter": "120",
+			"attributes": []interface{}{map[string]interface{}{"name": "tribe", "value": "payments"}},
+		},
+		{
+			"_id":        "u2",
+			"department": "Engineering",
+			"state":      "SUSPENDED",
+			"costCenter": "95",
+		},
+		{
+			"_id":        "u3",
+			"department": "Sales",
+			"state":      "ACTIVATED",
+			"attributes": []interface{}{map[string]interface{}{"name": "tribe", "value": "growth"}},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		filters    []common.UserGroupFilter
+		exemptions []common.UserGroupExemption
+		want       []string
+	}{
+		{"no filters", nil, nil, []string{}},
+		{"eq", []common.UserGroupFilter{{Field: "department", Operator: "eq", Value: "Engineering"}}, nil, []string{"u1", "u2"}},
+		{"userState maps to state", []common.UserGroupFilter{{Field: "userState", Operator: "eq", Value: "ACTIVATED"}}, nil, []string{"u1", "u3"}},
+		{"ne matches missing", []common.UserGroupFilter{{Field: "tribe", Operator: "ne", Value: "growth"}}, nil, []string{"u1", "u2"}},
+		{"in custom attribute", []common.UserGroupFilter{{Field: "tribe", Operator: "in", Value: "payments|growth"}}, nil, []string{"u1", "u3"}},
+		{"numeric gt", []common.UserGroupFilter{{Field: "costCenter", Operator: "gt", Value: "100"}}, nil, []string{"u1"}},
+		{"filters are combined", []common.UserGroupFilter{
+			{Field: "department", Operator: "eq", Value: "Engineering"},
+			{Field: "state", Operator: "eq", Value: "ACTIVATED"},
+		}, nil, []string{"u1"}},
+		{"exemptions", []common.UserGroupFilter{{Field: "department", Operator: "eq", Value: "Engineering"}}, []common.UserGroupExemption{{ID: "u1", Type: "USER"}}, []string{"u2"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := previewMemberQuery(users, tc.filters, tc.exemptions); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
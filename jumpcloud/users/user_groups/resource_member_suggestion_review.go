@@ -0,0 +1,312 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// suggestionAllowRule describes which membership suggestions may be approved
+type suggestionAllowRule struct {
+	UsernameRegex *regexp.Regexp
+	EmailRegex    *regexp.Regexp
+	UserIDs       map[string]bool
+	Operations    map[string]bool
+}
+
+// suggestionAllowConditions are the conditions selecting the users of an allow rule. A rule without
+// any of them would approve every suggestion
+var suggestionAllowConditions = []string{"allow.0.username_regex", "allow.0.email_regex", "allow.0.user_ids"}
+
+// ResourceMemberSuggestionReview returns the resource that approves or rejects membership suggestions of a user group
+func ResourceMemberSuggestionReview() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceMemberSuggestionReviewCreate,
+		ReadContext:   resourceMemberSuggestionReviewRead,
+		UpdateContext: resourceMemberSuggestionReviewUpdate,
+		DeleteContext: resourceMemberSuggestionReviewDelete,
+		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			// Pending suggestions found during refresh are processed on the next apply
+			if diff.Id() != "" && diff.Get("pending_count").(int) > 0 {
+				return diff.SetNew("pending_count", 0)
+			}
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the DYNAMIC_REVIEW_REQUIRED user group",
+			},
+			"allow": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MaxItems:    1,
+				Description: "Rule selecting the suggestions to approve. All configured conditions must match, and at least one of username_regex, email_regex or user_ids must be set",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username_regex": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							AtLeastOneOf: suggestionAllowConditions,
+							ValidateFunc: validation.StringIsValidRegExp,
+							Description:  "Regular expression the username must match",
+						},
+						"email_regex": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							AtLeastOneOf: suggestionAllowConditions,
+							ValidateFunc: validation.StringIsValidRegExp,
+							Description:  "Regular expression the email must match",
+						},
+						"user_ids": {
+							Type:         schema.TypeSet,
+							Optional:     true,
+							AtLeastOneOf: suggestionAllowConditions,
+							Elem:         &schema.Schema{Type: schema.TypeString},
+							Description:  "IDs of the users that may be approved",
+						},
+						"operations": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringInSlice([]string{"add", "remove"}, false),
+							},
+							Description: "Suggested operations that may be approved, 'add' and/or 'remove'. Defaults to both",
+						},
+					},
+				},
+			},
+			"reject_unmatched": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to reject suggestions that do not match the allow rule. When false they are left pending",
+			},
+			"pending_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of pending suggestions this resource will approve or reject on the next apply",
+			},
+			"approved_user_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the users approved during the last apply",
+			},
+			"rejected_user_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the users rejected during the last apply",
+			},
+		},
+		Description: "Approves or rejects pending membership suggestions of a DYNAMIC_REVIEW_REQUIRED user group according to an allow rule. New suggestions are processed on every apply.",
+	}
+}
+
+func resourceMemberSuggestionReviewCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId(d.Get("group_id").(string))
+
+	if diags := reviewMemberSuggestions(ctx, d, meta); diags.HasError() {
+		d.SetId("")
+		return diags
+	}
+
+	return resourceMemberSuggestionReviewRead(ctx, d, meta)
+}
+
+func resourceMemberSuggestionReviewRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	groupID := d.Id()
+
+	suggestions, err := listMemberSuggestions(ctx, c, groupID)
+	if err != nil {
+		if common.IsNotFoundError(err) {
+			tflog.Warn(ctx, fmt.Sprintf("User group %s not found, removing suggestion review from state", groupID))
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
+
+	rule, err := expandSuggestionAllowRule(d.Get("allow").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	approve, reject := classifyMemberSuggestions(suggestions, rule, d.Get("reject_unmatched").(bool))
+
+	if err := d.Set("group_id", groupID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting group_id: %v", err))
+	}
+	if err := d.Set("pending_count", len(approve)+len(reject)); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting pending_count: %v", err))
+	}
+
+	return diags
+}
+
+func resourceMemberSuggestionReviewUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := reviewMemberSuggestions(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+
+	return resourceMemberSuggestionReviewRead(ctx, d, meta)
+}
+
+func resourceMemberSuggestionReviewDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Approved and rejected suggestions cannot be undone, removing the resource
+	// only stops reviewing new ones
+	tflog.Debug(ctx, fmt.Sprintf("Removing suggestion review for user group %s from state", d.Id()))
+	d.SetId("")
+	return nil
+}
+
+// reviewMemberSuggestions approves and rejects the pending suggestions of the group
+func reviewMemberSuggestions(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	groupID := d.Id()
+
+	rule, err := expandSuggestionAllowRule(d.Get("allow").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	suggestions, err := listMemberSuggestions(ctx, c, groupID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	approve, reject := classifyMemberSuggestions(suggestions, rule, d.Get("reject_unmatched").(bool))
+	path := fmt.Sprintf("/api/v2/usergroups/%s/suggestions", groupID)
+
+	if len(approve) > 0 {
+		body, err := json.Marshal(map[string]interface{}{"user_ids": approve})
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error serializing approved suggestions: %v", err))
+		}
+		tflog.Info(ctx, fmt.Sprintf("Approving %d member suggestions for user group %s", len(approve), groupID))
+		if _, err := c.DoRequest(http.MethodPost, path, body); err != nil {
+			return diag.FromErr(fmt.Errorf("error approving member suggestions of user group %s: %v", groupID, err))
+		}
+	}
+
+	if len(reject) > 0 {
+		body, err := json.Marshal(map[string]interface{}{"user_ids": reject})
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("error serializing rejected suggestions: %v", err))
+		}
+		tflog.Info(ctx, fmt.Sprintf("Rejecting %d member suggestions for user group %s", len(reject), groupID))
+		if _, err := c.DoRequest(http.MethodDelete, path, body); err != nil {
+			return diag.FromErr(fmt.Errorf("error rejecting member suggestions of user group %s: %v", groupID, err))
+		}
+	}
+
+	if err := d.Set("approved_user_ids", approve); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting approved_user_ids: %v", err))
+	}
+	if err := d.Set("rejected_user_ids", reject); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting rejected_user_ids: %v", err))
+	}
+
+	return nil
+}
+
+// expandSuggestionAllowRule converts the allow block into a suggestionAllowRule
+func expandSuggestionAllowRule(input []interface{}) (suggestionAllowRule, error) {
+	rule := suggestionAllowRule{
+		UserIDs:    make(map[string]bool),
+		Operations: make(map[string]bool),
+	}
+
+	if len(input) == 0 || input[0] == nil {
+		return rule, fmt.Errorf("the allow rule needs at least one of username_regex, email_regex or user_ids")
+	}
+
+	m := input[0].(map[string]interface{})
+
+	if v, ok := m["username_regex"].(string); ok && v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return rule, fmt.Errorf("invalid username_regex: %v", err)
+		}
+		rule.UsernameRegex = re
+	}
+	if v, ok := m["email_regex"].(string); ok && v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return rule, fmt.Errorf("invalid email_regex: %v", err)
+		}
+		rule.EmailRegex = re
+	}
+	if v, ok := m["user_ids"].(*schema.Set); ok {
+		for _, id := range v.List() {
+			rule.UserIDs[id.(string)] = true
+		}
+	}
+	if v, ok := m["operations"].(*schema.Set); ok {
+		for _, op := range v.List() {
+			rule.Operations[op.(string)] = true
+		}
+	}
+
+	if rule.UsernameRegex == nil && rule.EmailRegex == nil && len(rule.UserIDs) == 0 {
+		return rule, fmt.Errorf("the allow rule needs at least one of username_regex, email_regex or user_ids")
+	}
+
+	return rule, nil
+}
+
+// matches reports whether a suggestion satisfies every configured condition of the rule
+func (r suggestionAllowRule) matches(s common.UserGroupMemberSuggestion) bool {
+	if len(r.Operations) > 0 && !r.Operations[s.Op] {
+		return false
+	}
+	if len(r.UserIDs) > 0 && !r.UserIDs[s.Object.ID] {
+		return false
+	}
+	if r.UsernameRegex != nil && !r.UsernameRegex.MatchString(s.Object.Username) {
+		return false
+	}
+	if r.EmailRegex != nil && !r.EmailRegex.MatchString(s.Object.Email) {
+		return false
+	}
+	return true
+}
+
+// classifyMemberSuggestions splits suggestions into the user IDs to approve and to reject
+func classifyMemberSuggestions(suggestions []common.UserGroupMemberSuggestion, rule suggestionAllowRule, rejectUnmatched bool) ([]string, []string) {
+	approve := make([]string, 0)
+	reject := make([]string, 0)
+
+	for _, s := range suggestions {
+		if rule.matches(s) {
+			approve = append(approve, s.Object.ID)
+		} else if rejectUnmatched {
+			reject = append(reject, s.Object.ID)
+		}
+	}
+
+	return approve, reject
+}
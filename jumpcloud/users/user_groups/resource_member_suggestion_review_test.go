@@ -0,0 +1,88 @@
+package users
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// TestResourceMemberSuggestionReviewSchema tests the schema structure of the suggestion review resource
+func TestResourceMemberSuggestionReviewSchema(t *testing.T) {
+	s := ResourceMemberSuggestionReview()
+
+	if s.Schema["group_id"] == nil || !s.Schema["group_id"].Required || !s.Schema["group_id"].ForceNew {
+		t.Error("Expected group_id to be a required ForceNew field")
+	}
+	if s.Schema["allow"] == nil || s.Schema["allow"].MaxItems != 1 {
+		t.Error("Expected allow to be a single block")
+	}
+	for _, field := range []string{"pending_count", "approved_user_ids", "rejected_user_ids"} {
+		if s.Schema[field] == nil || !s.Schema[field].Computed {
+			t.Errorf("Expected %s to be a computed field", field)
+		}
+	}
+}
+
+func TestClassifyMemberSuggestions(t *testing.T) {
+	suggestions := []common.UserGroupMemberSuggestion{
+		{Op: "add", Object: common.UserGroupSuggestionUser{ID: "u1", Username: "alice", Email: "alice@example.com"}},
+		{Op: "add", Object: common.UserGroupSuggestionUser{ID: "u2", Username: "bob", Email: "bob@contractor.com"}},
+		{Op: "remove", Object: common.UserGroupSuggestionUser{ID: "u3", Username: "carol", Email: "carol@example.com"}},
+	}
+
+	cases := []struct {
+		name            string
+		rule            suggestionAllowRule
+		rejectUnmatched bool
+		wantApprove     []string
+		wantReject      []string
+	}{
+		{"empty rule approves all", suggestionAllowRule{}, false, []string{"u1", "u2", "u3"}, []string{}},
+		{"email regex", suggestionAllowRule{EmailRegex: regexp.MustCompile(`@example\.com$`)}, false, []string{"u1", "u3"}, []string{}},
+		{"reject unmatched", suggestionAllowRule{EmailRegex: regexp.MustCompile(`@example\.com$`)}, true, []string{"u1", "u3"}, []string{"u2"}},
+		{"operations", suggestionAllowRule{Operations: map[string]bool{"add": true}}, false, []string{"u1", "u2"}, []string{}},
+		{"user ids and username", suggestionAllowRule{UserIDs: map[string]bool{"u1": true, "u2": true}, UsernameRegex: regexp.MustCompile(`^b`)}, true, []string{"u2"}, []string{"u1", "u3"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			approve, reject := classifyMemberSuggestions(suggestions, tc.rule, tc.rejectUnmatched)
+			if !reflect.DeepEqual(approve, tc.wantApprove) {
+				t.Errorf("Expected approved %v, got %v", tc.wantApprove, approve)
+			}
+			if !reflect.DeepEqual(reject, tc.wantReject) {
+				t.Errorf("Expected rejected %v, got %v", tc.wantReject, reject)
+			}
+		})
+	}
+}
+
+func TestExpandSuggestionAllowRuleRequiresCondition(t *testing.T) {
+	for _, allow := range [][]interface{}{
+		nil,
+		{map[string]interface{}{}},
+		{map[string]interface{}{"operations": schema.NewSet(schema.HashString, []interface{}{"add"})}},
+	} {
+		if _, err := expandSuggestionAllowRule(allow); err == nil {
+			t.Errorf("Expected an error for an allow rule without condition: %v", allow)
+		}
+	}
+
+	rule, err := expandSuggestionAllowRule([]interface{}{map[string]interface{}{"email_regex": `@example\.com$`}})
+	if err != nil || rule.EmailRegex == nil {
+		t.Errorf("Expected an email rule, got %v: %v", rule, err)
+	}
+
+	// An empty allow block is rejected at plan time
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"group_id": "g1",
+		"allow":    []interface{}{map[string]interface{}{}},
+	})
+	if diags := ResourceMemberSuggestionReview().Validate(config); !diags.HasError() {
+		t.Error("Expected an error for an empty allow block")
+	}
+}
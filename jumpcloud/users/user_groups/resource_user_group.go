@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
-	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -15,6 +16,9 @@ import (
 	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
 )
 
+// userGroupsPageSize is the number of groups requested per page from /api/v2/usergroups
+const userGroupsPageSize = 100
+
 // sanitizeAttributeName ensures attribute names only contain letters and numbers
 // as required by the JumpCloud API
 func sanitizeAttributeName(name string) string {
@@ -85,13 +89,24 @@ func convertFieldFromAPI(field string) string {
 	return field
 }
 
+// userGroupImportLookup resolves name:<name> import IDs
+var userGroupImportLookup = common.ImportLookup{
+	Kind:     "user group",
+	ListPath: "/api/v2/usergroups",
+	Fields:   map[string]string{"name": "name"},
+}
+
 // ResourceUserGroup returns the resource for JumpCloud user groups
 func ResourceUserGroup() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceUserGroupCreate,
 		ReadContext:   resourceUserGroupRead,
 		UpdateContext: resourceUserGroupUpdate,
 		DeleteContext: resourceUserGroupDelete,
+		CustomizeDiff: customizeUserGroupDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: common.ImportStateResolver(userGroupImportLookup),
+		},
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:     schema.TypeString,
@@ -119,7 +134,8 @@ func ResourceUserGroup() *schema.Resource {
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Description: "Custom attributes for the group (key-value pairs)",
+				ValidateFunc: validateFreeFormAttributes,
+				Description:  "Custom attributes for the group (key-value pairs). sudo, posixGroups, ldapGroups, radius and sambaEnabled are configured with their dedicated blocks",
 			},
 			"membership_method": {
 				Type:        schema.TypeString,
@@ -242,6 +258,18 @@ func ResourceUserGroup() *schema.Resource {
 				Default:     false,
 				Description: "Whether to send email notifications for membership suggestions. Only applicable for DYNAMIC_REVIEW_REQUIRED groups",
 			},
+			"preview_member_query": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to evaluate member_query against the current users during plan and report the matches in member_query_preview. Lists every user on each plan",
+			},
+			"member_query_preview": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the users member_query would match, excluding exemptions. Only populated when preview_member_query is enabled",
+			},
 			"member_count": {
 				Type:        schema.TypeInt,
 				Computed:    true,
@@ -259,6 +287,12 @@ func ResourceUserGroup() *schema.Resource {
 			},
 		},
 	}
+
+	for name, attrSchema := range userGroupAttributeSchemas() {
+		r.Schema[name] = attrSchema
+	}
+
+	return r
 }
 
 func resourceUserGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -274,16 +308,8 @@ func resourceUserGroupCreate(ctx context.Context, d *schema.ResourceData, meta i
 		Type:        d.Get("type").(string),
 	}
 
-	// Process custom attributes
-	if attr, ok := d.GetOk("attributes"); ok {
-		attributes := make(map[string]interface{})
-		for k, v := range attr.(map[string]interface{}) {
-			// Sanitize attribute name for API
-			sanitizedName := sanitizeAttributeName(k)
-			attributes[sanitizedName] = v
-		}
-		group.Attributes = attributes
-	}
+	// Process custom attributes and typed attribute blocks
+	group.Attributes = expandUserGroupAttributes(d)
 
 	// Set membership method if provided
 	if v, ok := d.GetOk("membership_method"); ok {
@@ -505,49 +531,54 @@ func resourceUserGroupRead(ctx context.Context, d *schema.ResourceData, meta int
 		}
 	}
 
-	// Process attributes, preserving original names
-	if group.Attributes != nil {
-		// Get the original attributes from the configuration
-		oldAttrs := d.Get("attributes").(map[string]interface{})
+	// Evaluate the member query locally when the preview is enabled
+	if d.Get("preview_member_query").(bool) {
+		users, err := listUsersForPreview(ctx, c)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		exemptions, err := expandMemberQueryExemptions(d.Get("member_query_exemptions").([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		preview := previewMemberQuery(users, memberQueryFilters(d.Get("member_query").([]interface{})), exemptions)
+		if err := d.Set("member_query_preview", preview); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting member_query_preview: %v", err))
+		}
+	}
 
-		// Create a map of sanitized name -> original name
-		sanitizedToOriginal := make(map[string]string)
-		for origName := range oldAttrs {
-			sanitizedToOriginal[sanitizeAttributeName(origName)] = origName
+	// Split attributes into the typed blocks and the free-form map
+	typed, freeForm := flattenUserGroupAttributes(group.Attributes)
+	for _, block := range sortedTypedAttributeBlocks() {
+		if err := d.Set(block, typed[block]); err != nil {
+			return diag.FromErr(fmt.Errorf("error setting %s: %v", block, err))
 		}
+	}
 
-		// Create new attribute map preserving original names
-		attributes := make(map[string]interface{})
-		for attrName, attrValue := range group.Attributes {
-			// Skip the ldapGroups attribute as it's managed by JumpCloud
-			if attrName == "ldapGroups" {
-				continue
-			}
+	// Process free-form attributes, preserving original names
+	oldAttrs := d.Get("attributes").(map[string]interface{})
 
-			// Check if we have this attribute in the old configuration
-			if origName, exists := sanitizedToOriginal[attrName]; exists {
-				// Use the original name
-				attributes[origName] = fmt.Sprintf("%v", attrValue)
-			} else {
-				// Use the name from the API
-				attributes[attrName] = fmt.Sprintf("%v", attrValue)
-			}
-		}
+	// Create a map of sanitized name -> original name
+	sanitizedToOriginal := make(map[string]string)
+	for origName := range oldAttrs {
+		sanitizedToOriginal[sanitizeAttributeName(origName)] = origName
+	}
 
-		// Only set attributes if we have any (avoid setting empty map)
-		if len(attributes) > 0 {
-			if err := d.Set("attributes", attributes); err != nil {
-				return diag.FromErr(fmt.Errorf("error setting attributes: %v", err))
-			}
-		} else if len(oldAttrs) > 0 {
-			// If we had attributes before but now they're all filtered out,
-			// keep the original attributes to avoid unnecessary changes
-			if err := d.Set("attributes", oldAttrs); err != nil {
-				return diag.FromErr(fmt.Errorf("error setting attributes: %v", err))
-			}
+	attributes := make(map[string]interface{})
+	for attrName, attrValue := range freeForm {
+		if origName, exists := sanitizedToOriginal[attrName]; exists {
+			// Use the original name
+			attributes[origName] = attrValue
+		} else {
+			// Use the name from the API
+			attributes[attrName] = attrValue
 		}
 	}
 
+	if err := d.Set("attributes", attributes); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting attributes: %v", err))
+	}
+
 	return diags
 }
 
@@ -566,16 +597,8 @@ func resourceUserGroupUpdate(ctx context.Context, d *schema.ResourceData, meta i
 		Type:        d.Get("type").(string),
 	}
 
-	// Process custom attributes
-	if attr, ok := d.GetOk("attributes"); ok {
-		attributes := make(map[string]interface{})
-		for k, v := range attr.(map[string]interface{}) {
-			// Sanitize attribute name for API
-			sanitizedName := sanitizeAttributeName(k)
-			attributes[sanitizedName] = v
-		}
-		group.Attributes = attributes
-	}
+	// Process custom attributes and typed attribute blocks
+	group.Attributes = expandUserGroupAttributes(d)
 
 	// Set membership method if provided
 	if v, ok := d.GetOk("membership_method"); ok {
@@ -649,353 +672,30 @@ func resourceUserGroupDelete(ctx context.Context, d *schema.ResourceData, meta i
 
 // expandMemberQuery converts the Terraform schema representation of a member query to the API format
 func expandMemberQuery(input []interface{}) (interface{}, error) {
-	if len(input) == 0 || input[0] == nil {
-		return nil, nil
-	}
-
-	queryMap := input[0].(map[string]interface{})
-	queryType := queryMap["query_type"].(string)
-
-	// Handle both TypeSet and TypeList for backward compatibility
-	var filters []interface{}
-	if filterSet, ok := queryMap["filter"].(*schema.Set); ok {
-		filters = filterSet.List()
-	} else if filterList, ok := queryMap["filter"].([]interface{}); ok {
-		filters = filterList
-	}
-
-	if len(filters) > 0 {
-		switch queryType {
-		case "FilterQuery":
-			// Use FilterQuery specific type
-			query := &common.UserGroupFilterQuery{
-				QueryType: queryType,
-				Filters:   make([]common.UserGroupFilter, 0, len(filters)),
-			}
-
-			for _, f := range filters {
-				filterMap := f.(map[string]interface{})
-				filter := common.UserGroupFilter{
-					Field:    filterMap["field"].(string),
-					Operator: filterMap["operator"].(string),
-					Value:    filterMap["value"].(string),
-				}
-				query.Filters = append(query.Filters, filter)
-			}
-
-			return query, nil
-		case "Search":
-			// Convert filters to searchFilters format for Search query
-			var searchFilters []string
-			for _, f := range filters {
-				filterMap := f.(map[string]interface{})
-				field := filterMap["field"].(string)
-				operator := filterMap["operator"].(string)
-				value := filterMap["value"].(string)
-
-				// Auto-detect if field is a custom attribute
-				field = convertFieldForAPI(field)
-
-				// Convert operator format and handle special cases
-				if operator == "in" {
-					// For 'in' operator, split values and create separate filters with $eq
-					values := strings.Split(value, "|")
-					for _, val := range values {
-						val = strings.TrimSpace(val)
-						if val != "" {
-							searchFilter := fmt.Sprintf("%s:$eq:%s", field, val)
-							searchFilters = append(searchFilters, searchFilter)
-						}
-					}
-				} else {
-					var searchOp string
-					switch operator {
-					case "eq":
-						searchOp = "$eq"
-					case "ne":
-						searchOp = "$ne"
-					default:
-						searchOp = "$eq" // default to equals
-					}
-
-					searchFilter := fmt.Sprintf("%s:%s:%s", field, searchOp, value)
-					searchFilters = append(searchFilters, searchFilter)
-				}
-			}
-
-			// Create SearchQuery specific type
-			if len(searchFilters) > 0 {
-				// Create searchFilters as JSON string (as expected by JumpCloud API)
-				searchFiltersObj := map[string]interface{}{
-					"filter": searchFilters,
-				}
-				searchFiltersJSON, _ := json.Marshal(searchFiltersObj)
-
-				query := &common.UserGroupSearchQuery{
-					QueryType:     queryType,
-					SearchFilters: string(searchFiltersJSON), // Send as string, not object
-				}
-
-				return query, nil
-			}
-		}
-	}
-
-	// Return basic query if no filters
-	return &common.UserGroupQuery{QueryType: queryType}, nil
+	return common.ExpandMemberQuery(input, convertFieldForAPI)
 }
 
 // expandMemberQueryExemptions converts the Terraform schema representation of member query exemptions to the API format
 func expandMemberQueryExemptions(input []interface{}) ([]common.UserGroupExemption, error) {
-	if len(input) == 0 {
-		return nil, nil
-	}
-
-	exemptions := make([]common.UserGroupExemption, 0, len(input))
-	for _, item := range input {
-		exemptionMap := item.(map[string]interface{})
-		exemption := common.UserGroupExemption{
-			ID:   exemptionMap["id"].(string),
-			Type: exemptionMap["type"].(string),
-		}
-		exemptions = append(exemptions, exemption)
-	}
-
-	return exemptions, nil
+	return common.ExpandMemberQueryExemptions(input)
 }
 
 // flattenMemberQuery converts the API representation of a member query to the Terraform schema format
 func flattenMemberQuery(query interface{}) []interface{} {
-	if query == nil {
-		return []interface{}{}
-	}
-
-	result := make(map[string]interface{})
-	var filters []interface{}
-
-	switch q := query.(type) {
-	case *common.UserGroupFilterQuery:
-		result["query_type"] = q.QueryType
-		if len(q.Filters) > 0 {
-			// Sort filters by field name for consistent ordering
-			sortedFilters := make([]common.UserGroupFilter, len(q.Filters))
-			copy(sortedFilters, q.Filters)
-			sort.Slice(sortedFilters, func(i, j int) bool {
-				return sortedFilters[i].Field < sortedFilters[j].Field
-			})
-
-			filters = make([]interface{}, 0, len(sortedFilters))
-			for _, filter := range sortedFilters {
-				filterMap := make(map[string]interface{})
-				filterMap["field"] = filter.Field
-				filterMap["operator"] = filter.Operator
-				filterMap["value"] = filter.Value
-				filters = append(filters, filterMap)
-			}
-		}
-	case *common.UserGroupSearchQuery:
-		result["query_type"] = q.QueryType
-		if q.SearchFilters != "" {
-			filters = parseSearchFilters(q.SearchFilters)
-		}
-	case *common.UserGroupQuery:
-		result["query_type"] = q.QueryType
-		if q.QueryType == "FilterQuery" && len(q.Filters) > 0 {
-			filters = make([]interface{}, 0, len(q.Filters))
-			for _, filter := range q.Filters {
-				filterMap := make(map[string]interface{})
-				filterMap["field"] = filter.Field
-				filterMap["operator"] = filter.Operator
-				filterMap["value"] = filter.Value
-				filters = append(filters, filterMap)
-			}
-		} else if q.QueryType == "Search" && q.SearchFilters != nil {
-			filters = parseSearchFiltersFromObject(q.SearchFilters)
-		}
-	case map[string]interface{}:
-		// Handle the case when JSON is deserialized as generic map
-		if queryType, ok := q["queryType"].(string); ok {
-			result["query_type"] = queryType
-
-			switch queryType {
-			case "FilterQuery":
-				if filtersArray, ok := q["filters"].([]interface{}); ok {
-					// Create a temporary slice to sort filters
-					var tempFilters []map[string]interface{}
-					for _, f := range filtersArray {
-						if filterMap, ok := f.(map[string]interface{}); ok {
-							newFilter := make(map[string]interface{})
-							if field, ok := filterMap["field"].(string); ok {
-								newFilter["field"] = field
-							}
-							if operator, ok := filterMap["operator"].(string); ok {
-								newFilter["operator"] = operator
-							}
-							if value, ok := filterMap["value"].(string); ok {
-								newFilter["value"] = value
-							}
-							tempFilters = append(tempFilters, newFilter)
-						}
-					}
-
-					// Sort filters by field name for consistent ordering
-					sort.Slice(tempFilters, func(i, j int) bool {
-						return tempFilters[i]["field"].(string) < tempFilters[j]["field"].(string)
-					})
-
-					// Convert to interface{} slice
-					filters = make([]interface{}, 0, len(tempFilters))
-					for _, filter := range tempFilters {
-						filters = append(filters, filter)
-					}
-				}
-			case "Search":
-				if searchFilters, ok := q["searchFilters"].(string); ok {
-					filters = parseSearchFilters(searchFilters)
-				}
-			}
-		}
-	default:
-		return []interface{}{}
-	}
-
-	result["filter"] = filters
-	return []interface{}{result}
-}
-
-// parseSearchFilters converts searchFilters JSON string back to filter array format
-func parseSearchFilters(searchFilters string) []interface{} {
-	var filters []interface{}
-
-	// Parse the JSON string to extract filters
-	// Example: {"filter":["company:$eq:Agilize","department:$eq:Administration","state:$eq:ACTIVATED","attributes[name=area].value:$eq:asd"]}
-
-	var searchFiltersObj map[string]interface{}
-	if err := json.Unmarshal([]byte(searchFilters), &searchFiltersObj); err != nil {
-		return filters
-	}
-
-	if filterArray, ok := searchFiltersObj["filter"].([]interface{}); ok {
-		// Group filters by field to handle multiple values (like jobTitle)
-		fieldGroups := make(map[string][]string)
-
-		for _, filterItem := range filterArray {
-			if filterStr, ok := filterItem.(string); ok {
-				parts := strings.Split(filterStr, ":")
-				if len(parts) >= 3 {
-					field := parts[0]
-					value := strings.Join(parts[2:], ":") // In case value contains colons
-
-					// Convert back from API format
-					field = convertFieldFromAPI(field)
-
-					// Group values by field
-					if _, exists := fieldGroups[field]; !exists {
-						fieldGroups[field] = []string{}
-					}
-					fieldGroups[field] = append(fieldGroups[field], value)
-				}
-			}
-		}
-
-		// Convert grouped filters back to Terraform format
-		// Sort fields to ensure consistent ordering
-		var sortedFields []string
-		for field := range fieldGroups {
-			sortedFields = append(sortedFields, field)
-		}
-		sort.Strings(sortedFields)
-
-		for _, field := range sortedFields {
-			values := fieldGroups[field]
-			filterMap := make(map[string]interface{})
-			filterMap["field"] = field
-
-			if len(values) > 1 {
-				// Multiple values = "in" operator
-				filterMap["operator"] = "in"
-				filterMap["value"] = strings.Join(values, "|")
-			} else {
-				// Single value = "eq" operator
-				filterMap["operator"] = "eq"
-				filterMap["value"] = values[0]
-			}
-
-			filters = append(filters, filterMap)
-		}
-	}
-
-	return filters
-}
-
-// parseSearchFiltersFromObject converts searchFilters object back to filter array format
-func parseSearchFiltersFromObject(searchFilters map[string]interface{}) []interface{} {
-	var filters []interface{}
-
-	if filterArray, ok := searchFilters["filter"].([]interface{}); ok {
-		for _, filterItem := range filterArray {
-			if filterStr, ok := filterItem.(string); ok {
-				parts := strings.Split(filterStr, ":")
-				if len(parts) >= 3 {
-					field := parts[0]
-					operator := parts[1]
-					value := strings.Join(parts[2:], ":") // In case value contains colons
-
-					// Convert back from API format
-					field = convertFieldFromAPI(field)
-
-					// Convert operator format
-					switch operator {
-					case "$eq":
-						operator = "eq"
-					case "$ne":
-						operator = "ne"
-					case "$in":
-						operator = "in"
-					}
-
-					filterMap := make(map[string]interface{})
-					filterMap["field"] = field
-					filterMap["operator"] = operator
-					filterMap["value"] = value
-					filters = append(filters, filterMap)
-				}
-			}
-		}
-	}
-
-	return filters
+	return common.FlattenMemberQuery(query, convertFieldFromAPI)
 }
 
 // flattenMemberQueryExemptions converts the API representation of member query exemptions to the Terraform schema format
 func flattenMemberQueryExemptions(exemptions []common.UserGroupExemption) []interface{} {
-	if len(exemptions) == 0 {
-		return []interface{}{}
-	}
-
-	result := make([]interface{}, 0, len(exemptions))
-	for _, exemption := range exemptions {
-		exemptionMap := make(map[string]interface{})
-		exemptionMap["id"] = exemption.ID
-		exemptionMap["type"] = exemption.Type
-		result = append(result, exemptionMap)
-	}
-
-	return result
+	return common.FlattenMemberQueryExemptions(exemptions)
 }
 
 // getUserGroupsByName retrieves user groups by name
 func getUserGroupsByName(ctx context.Context, c common.ClientInterface, name string) ([]common.UserGroup, error) {
-	// Get all user groups
-	resp, err := c.DoRequest(http.MethodGet, "/api/v2/usergroups", nil)
+	// Narrow the listing server-side, the exact match is still checked below
+	groups, err := listUserGroups(ctx, c, fmt.Sprintf("name:eq:%s", name))
 	if err != nil {
-		return nil, fmt.Errorf("error listing user groups: %v", err)
-	}
-
-	// Parse response
-	var groups []common.UserGroup
-	if err := json.Unmarshal(resp, &groups); err != nil {
-		return nil, fmt.Errorf("error parsing user groups response: %v", err)
+		return nil, err
 	}
 
 	// Filter by name
@@ -1008,3 +708,38 @@ func getUserGroupsByName(ctx context.Context, c common.ClientInterface, name str
 
 	return matchingGroups, nil
 }
+
+// listUserGroups retrieves every user group matching the optional v2 filter, following pagination
+func listUserGroups(ctx context.Context, c common.ClientInterface, filter string) ([]common.UserGroup, error) {
+	var groups []common.UserGroup
+
+	for skip := 0; ; skip += userGroupsPageSize {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(userGroupsPageSize))
+		query.Set("skip", strconv.Itoa(skip))
+		if filter != "" {
+			query.Set("filter", filter)
+		}
+
+		path := fmt.Sprintf("/api/v2/usergroups?%s", query.Encode())
+		tflog.Debug(ctx, fmt.Sprintf("Listing user groups with URL: %s", path))
+
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error listing user groups: %v", err)
+		}
+
+		var page []common.UserGroup
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing user groups response: %v", err)
+		}
+
+		groups = append(groups, page...)
+
+		if len(page) < userGroupsPageSize {
+			break
+		}
+	}
+
+	return groups, nil
+}
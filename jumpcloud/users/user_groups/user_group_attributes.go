@@ -0,0 +1,270 @@
+package users
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// typedUserGroupAttributes lists the nested group attributes managed through dedicated blocks
+// instead of the free-form attributes map
+var typedUserGroupAttributes = map[string]string{
+	"sudo":         "sudo",
+	"posixGroups":  "posix_groups",
+	"ldapGroups":   "ldap_groups",
+	"radius":       "radius_reply",
+	"sambaEnabled": "samba_enabled",
+}
+
+// userGroupAttributeSchemas returns the typed attribute blocks of the user group resource
+func userGroupAttributeSchemas() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"sudo": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			MaxItems:    1,
+			Description: "Sudo settings granted to the members on every device associated through device groups",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"enabled": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "Whether members are administrators (sudo) on the associated devices",
+					},
+					"without_password": {
+						Type:        schema.TypeBool,
+						Optional:    true,
+						Default:     false,
+						Description: "Whether members can run sudo without a password",
+					},
+				},
+			},
+		},
+		"posix_groups": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "Linux group created on the associated devices for the members",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Name of the Linux group",
+					},
+					"id": {
+						Type:        schema.TypeInt,
+						Optional:    true,
+						Computed:    true,
+						Description: "GID of the Linux group. Assigned by JumpCloud when omitted",
+					},
+				},
+			},
+		},
+		"ldap_groups": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Computed:    true,
+			Description: "LDAP groups the members belong to in JumpCloud LDAP",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Name of the LDAP group",
+					},
+				},
+			},
+		},
+		"radius_reply": {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: "RADIUS reply attributes returned when members authenticate",
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"name": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Name of the RADIUS attribute, e.g. 'Filter-Id'",
+					},
+					"value": {
+						Type:        schema.TypeString,
+						Required:    true,
+						Description: "Value of the RADIUS attribute",
+					},
+				},
+			},
+		},
+		"samba_enabled": {
+			Type:        schema.TypeBool,
+			Optional:    true,
+			Default:     false,
+			Description: "Whether Samba authentication is enabled for the members",
+		},
+	}
+}
+
+// validateFreeFormAttributes rejects attribute names managed through typed blocks
+func validateFreeFormAttributes(val interface{}, key string) (warns []string, errs []error) {
+	for name := range val.(map[string]interface{}) {
+		if block, ok := typedUserGroupAttributes[sanitizeAttributeName(name)]; ok {
+			errs = append(errs, fmt.Errorf("%q: attribute %q must be configured with the %s block", key, name, block))
+		}
+	}
+	return
+}
+
+// expandUserGroupAttributes builds the API attributes from the free-form map and the typed blocks
+func expandUserGroupAttributes(d *schema.ResourceData) map[string]interface{} {
+	attributes := make(map[string]interface{})
+
+	for k, v := range d.Get("attributes").(map[string]interface{}) {
+		// Sanitize attribute name for API
+		attributes[sanitizeAttributeName(k)] = v
+	}
+
+	if v := d.Get("sudo").([]interface{}); len(v) > 0 && v[0] != nil {
+		sudo := v[0].(map[string]interface{})
+		attributes["sudo"] = map[string]interface{}{
+			"enabled":         sudo["enabled"].(bool),
+			"withoutPassword": sudo["without_password"].(bool),
+		}
+	}
+
+	if v := d.Get("posix_groups").([]interface{}); len(v) > 0 {
+		groups := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			g := item.(map[string]interface{})
+			group := map[string]interface{}{"name": g["name"].(string)}
+			if id := g["id"].(int); id > 0 {
+				group["id"] = id
+			}
+			groups = append(groups, group)
+		}
+		attributes["posixGroups"] = groups
+	}
+
+	if v := d.Get("ldap_groups").([]interface{}); len(v) > 0 {
+		groups := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			groups = append(groups, map[string]interface{}{"name": item.(map[string]interface{})["name"].(string)})
+		}
+		attributes["ldapGroups"] = groups
+	}
+
+	if v := d.Get("radius_reply").([]interface{}); len(v) > 0 {
+		reply := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			r := item.(map[string]interface{})
+			reply = append(reply, map[string]interface{}{
+				"name":  r["name"].(string),
+				"value": r["value"].(string),
+			})
+		}
+		attributes["radius"] = map[string]interface{}{"reply": reply}
+	}
+
+	if d.Get("samba_enabled").(bool) {
+		attributes["sambaEnabled"] = true
+	}
+
+	if len(attributes) == 0 {
+		return nil
+	}
+	return attributes
+}
+
+// flattenUserGroupAttributes splits the API attributes into the typed blocks and the
+// remaining free-form attributes
+func flattenUserGroupAttributes(attributes map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	typed := map[string]interface{}{
+		"sudo":          []interface{}{},
+		"posix_groups":  []interface{}{},
+		"ldap_groups":   []interface{}{},
+		"radius_reply":  []interface{}{},
+		"samba_enabled": false,
+	}
+	freeForm := make(map[string]interface{})
+
+	for name, value := range attributes {
+		switch name {
+		case "sudo":
+			if sudo, ok := value.(map[string]interface{}); ok {
+				enabled, _ := sudo["enabled"].(bool)
+				withoutPassword, _ := sudo["withoutPassword"].(bool)
+				typed["sudo"] = []interface{}{map[string]interface{}{
+					"enabled":          enabled,
+					"without_password": withoutPassword,
+				}}
+			}
+		case "posixGroups":
+			groups := make([]interface{}, 0)
+			for _, item := range toInterfaceList(value) {
+				g, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				group := map[string]interface{}{"name": fmt.Sprintf("%v", g["name"]), "id": 0}
+				if id, ok := g["id"].(float64); ok {
+					group["id"] = int(id)
+				}
+				groups = append(groups, group)
+			}
+			typed["posix_groups"] = groups
+		case "ldapGroups":
+			groups := make([]interface{}, 0)
+			for _, item := range toInterfaceList(value) {
+				if g, ok := item.(map[string]interface{}); ok {
+					groups = append(groups, map[string]interface{}{"name": fmt.Sprintf("%v", g["name"])})
+				}
+			}
+			typed["ldap_groups"] = groups
+		case "radius":
+			reply := make([]interface{}, 0)
+			if radius, ok := value.(map[string]interface{}); ok {
+				for _, item := range toInterfaceList(radius["reply"]) {
+					if r, ok := item.(map[string]interface{}); ok {
+						reply = append(reply, map[string]interface{}{
+							"name":  fmt.Sprintf("%v", r["name"]),
+							"value": fmt.Sprintf("%v", r["value"]),
+						})
+					}
+				}
+			}
+			typed["radius_reply"] = reply
+		case "sambaEnabled":
+			enabled, ok := value.(bool)
+			if !ok {
+				enabled = strings.EqualFold(fmt.Sprintf("%v", value), "true")
+			}
+			typed["samba_enabled"] = enabled
+		default:
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				// Other nested attributes are not representable as strings
+				continue
+			}
+			freeForm[name] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	return typed, freeForm
+}
+
+// toInterfaceList returns the value as a list, or nil when it is not one
+func toInterfaceList(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	return list
+}
+
+// sortedTypedAttributeBlocks returns the names of the typed blocks in a stable order
+func sortedTypedAttributeBlocks() []string {
+	blocks := make([]string, 0, len(typedUserGroupAttributes))
+	for _, block := range typedUserGroupAttributes {
+		blocks = append(blocks, block)
+	}
+	sort.Strings(blocks)
+	return blocks
+}
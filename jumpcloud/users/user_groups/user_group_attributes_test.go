@@ -0,0 +1,77 @@
+package users
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandUserGroupAttributes(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, ResourceUserGroup().Schema, map[string]interface{}{
+		"name":          "engineering",
+		"attributes":    map[string]interface{}{"cost-center": "42"},
+		"sudo":          []interface{}{map[string]interface{}{"enabled": true, "without_password": false}},
+		"posix_groups":  []interface{}{map[string]interface{}{"name": "devs"}},
+		"ldap_groups":   []interface{}{map[string]interface{}{"name": "engineering"}},
+		"radius_reply":  []interface{}{map[string]interface{}{"name": "Filter-Id", "value": "eng"}},
+		"samba_enabled": true,
+	})
+
+	want := map[string]interface{}{
+		"costcenter":   "42",
+		"sudo":         map[string]interface{}{"enabled": true, "withoutPassword": false},
+		"posixGroups":  []interface{}{map[string]interface{}{"name": "devs"}},
+		"ldapGroups":   []interface{}{map[string]interface{}{"name": "engineering"}},
+		"radius":       map[string]interface{}{"reply": []interface{}{map[string]interface{}{"name": "Filter-Id", "value": "eng"}}},
+		"sambaEnabled": true,
+	}
+
+	if got := expandUserGroupAttributes(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestExpandUserGroupAttributesEmpty(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, ResourceUserGroup().Schema, map[string]interface{}{"name": "engineering"})
+
+	if got := expandUserGroupAttributes(d); got != nil {
+		t.Errorf("Expected no attributes, got %v", got)
+	}
+}
+
+func TestFlattenUserGroupAttributes(t *testing.T) {
+	typed, freeForm := flattenUserGroupAttributes(map[string]interface{}{
+		"team":         "platform",
+		"sudo":         map[string]interface{}{"enabled": true, "withoutPassword": true},
+		"posixGroups":  []interface{}{map[string]interface{}{"id": float64(5001), "name": "devs"}},
+		"ldapGroups":   []interface{}{map[string]interface{}{"name": "engineering"}},
+		"radius":       map[string]interface{}{"reply": []interface{}{map[string]interface{}{"name": "Filter-Id", "value": "eng"}}},
+		"sambaEnabled": true,
+		"unknown":      map[string]interface{}{"nested": true},
+	})
+
+	if !reflect.DeepEqual(freeForm, map[string]interface{}{"team": "platform"}) {
+		t.Errorf("Unexpected free-form attributes: %v", freeForm)
+	}
+
+	want := map[string]interface{}{
+		"sudo":          []interface{}{map[string]interface{}{"enabled": true, "without_password": true}},
+		"posix_groups":  []interface{}{map[string]interface{}{"id": 5001, "name": "devs"}},
+		"ldap_groups":   []interface{}{map[string]interface{}{"name": "engineering"}},
+		"radius_reply":  []interface{}{map[string]interface{}{"name": "Filter-Id", "value": "eng"}},
+		"samba_enabled": true,
+	}
+	if !reflect.DeepEqual(typed, want) {
+		t.Errorf("Expected %v, got %v", want, typed)
+	}
+}
+
+func TestValidateFreeFormAttributes(t *testing.T) {
+	if _, errs := validateFreeFormAttributes(map[string]interface{}{"team": "platform"}, "attributes"); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+	if _, errs := validateFreeFormAttributes(map[string]interface{}{"sambaEnabled": "true"}, "attributes"); len(errs) != 1 {
+		t.Errorf("Expected an error for a typed attribute, got %v", errs)
+	}
+}
@@ -0,0 +1,256 @@
+package users_directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// usersPageSize is the number of users requested per page from /api/systemusers
+const usersPageSize = 100
+
+// userReport represents a user found while walking the manager hierarchy
+type userReport struct {
+	ID        string
+	Username  string
+	Email     string
+	ManagerID string
+	Depth     int
+}
+
+// DataSourceUserReports returns the data source that walks the manager_id hierarchy below a user
+func DataSourceUserReports() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceUserReportsRead,
+		Schema: map[string]*schema.Schema{
+			"user_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the user whose reports should be returned",
+			},
+			"max_depth": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Maximum number of levels to walk below the user. 1 returns only direct reports, 0 walks the whole hierarchy",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					if v := val.(int); v < 0 {
+						errs = append(errs, fmt.Errorf("%q must be >= 0, got: %d", key, v))
+					}
+					return
+				},
+			},
+			// Output fields
+			"direct_report_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of the users that report directly to the user",
+			},
+			"report_ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "IDs of all direct and transitive reports up to max_depth",
+			},
+			"reports": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Direct and transitive reports up to max_depth, ordered by depth",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the user",
+						},
+						"username": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Username of the user",
+						},
+						"email": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Email of the user",
+						},
+						"manager_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the user's manager",
+						},
+						"depth": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Distance from the queried user, 1 for direct reports",
+						},
+					},
+				},
+			},
+			"cycle_detected": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether a cycle was found in the manager relationships below the user",
+			},
+		},
+	}
+}
+
+func dataSourceUserReportsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	userID := d.Get("user_id").(string)
+	maxDepth := d.Get("max_depth").(int)
+
+	users, err := listAllUsers(ctx, c)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	found := false
+	for _, u := range users {
+		if u.ID == userID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return diag.FromErr(fmt.Errorf("no user found with ID: %s", userID))
+	}
+
+	reports, cycle := buildUserReports(users, userID, maxDepth)
+	if cycle {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Cycle detected in manager hierarchy",
+			Detail:   fmt.Sprintf("The manager relationships below user %s contain a cycle. Users already visited were skipped.", userID),
+		})
+	}
+
+	directIDs := make([]string, 0)
+	allIDs := make([]string, 0, len(reports))
+	reportList := make([]map[string]interface{}, 0, len(reports))
+	for _, r := range reports {
+		if r.Depth == 1 {
+			directIDs = append(directIDs, r.ID)
+		}
+		allIDs = append(allIDs, r.ID)
+		reportList = append(reportList, map[string]interface{}{
+			"id":         r.ID,
+			"username":   r.Username,
+			"email":      r.Email,
+			"manager_id": r.ManagerID,
+			"depth":      r.Depth,
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%s:%d", userID, maxDepth))
+
+	if err := d.Set("direct_report_ids", directIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting direct_report_ids: %v", err))
+	}
+	if err := d.Set("report_ids", allIDs); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting report_ids: %v", err))
+	}
+	if err := d.Set("reports", reportList); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting reports: %v", err))
+	}
+	if err := d.Set("cycle_detected", cycle); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting cycle_detected: %v", err))
+	}
+
+	return diags
+}
+
+// buildUserReports walks the manager hierarchy breadth-first starting at rootID.
+// A maxDepth of 0 walks the whole hierarchy. The second return value reports
+// whether a user was reached more than once, which means the data has a cycle.
+func buildUserReports(users []User, rootID string, maxDepth int) ([]userReport, bool) {
+	byManager := make(map[string][]User)
+	for _, u := range users {
+		if u.Manager == nil || u.Manager.ID == "" {
+			continue
+		}
+		byManager[u.Manager.ID] = append(byManager[u.Manager.ID], u)
+	}
+
+	// Sort each level for a stable output order
+	for id := range byManager {
+		reports := byManager[id]
+		sort.Slice(reports, func(i, j int) bool {
+			return reports[i].Username < reports[j].Username
+		})
+	}
+
+	visited := map[string]bool{rootID: true}
+	cycle := false
+	var result []userReport
+
+	current := []string{rootID}
+	for depth := 1; len(current) > 0 && (maxDepth == 0 || depth <= maxDepth); depth++ {
+		var next []string
+		for _, managerID := range current {
+			for _, u := range byManager[managerID] {
+				if visited[u.ID] {
+					cycle = true
+					continue
+				}
+				visited[u.ID] = true
+				result = append(result, userReport{
+					ID:        u.ID,
+					Username:  u.Username,
+					Email:     u.Email,
+					ManagerID: managerID,
+					Depth:     depth,
+				})
+				next = append(next, u.ID)
+			}
+		}
+		current = next
+	}
+
+	return result, cycle
+}
+
+// listAllUsers retrieves every user of the organization, following pagination
+func listAllUsers(ctx context.Context, c common.ClientInterface) ([]User, error) {
+	var users []User
+
+	for skip := 0; ; skip += usersPageSize {
+		path := fmt.Sprintf("/api/systemusers?limit=%d&skip=%d", usersPageSize, skip)
+		tflog.Debug(ctx, fmt.Sprintf("Listing users with URL: %s", path))
+
+		resp, err := c.DoRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error listing users: %v", err)
+		}
+
+		var page struct {
+			Results    []User `json:"results"`
+			TotalCount int    `json:"totalCount"`
+		}
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("error parsing users response: %v", err)
+		}
+
+		users = append(users, page.Results...)
+
+		if len(page.Results) < usersPageSize || len(users) >= page.TotalCount {
+			break
+		}
+	}
+
+	return users, nil
+}
@@ -0,0 +1,89 @@
+package users_directory
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func testUserWithManager(id, managerID string) User {
+	u := User{ID: id, Username: id}
+	if managerID != "" {
+		u.Manager = &Manager{ID: managerID}
+	}
+	return u
+}
+
+// TestDataSourceUserReportsSchema tests the schema structure of the user reports data source
+func TestDataSourceUserReportsSchema(t *testing.T) {
+	s := DataSourceUserReports()
+
+	if s.Schema["user_id"] == nil || !s.Schema["user_id"].Required {
+		t.Error("Expected user_id to be a required field")
+	}
+	if s.Schema["max_depth"] == nil || s.Schema["max_depth"].Type != schema.TypeInt {
+		t.Error("Expected max_depth to be an int field")
+	}
+	for _, field := range []string{"direct_report_ids", "report_ids", "reports", "cycle_detected"} {
+		if s.Schema[field] == nil || !s.Schema[field].Computed {
+			t.Errorf("Expected %s to be a computed field", field)
+		}
+	}
+}
+
+func TestBuildUserReports(t *testing.T) {
+	users := []User{
+		testUserWithManager("vp", ""),
+		testUserWithManager("dir-b", "vp"),
+		testUserWithManager("dir-a", "vp"),
+		testUserWithManager("eng-1", "dir-a"),
+		testUserWithManager("eng-2", "dir-b"),
+		testUserWithManager("intern", "eng-1"),
+		testUserWithManager("other", ""),
+	}
+
+	reports, cycle := buildUserReports(users, "vp", 0)
+	if cycle {
+		t.Error("Expected no cycle")
+	}
+	want := []struct {
+		id    string
+		depth int
+	}{
+		{"dir-a", 1}, {"dir-b", 1}, {"eng-1", 2}, {"eng-2", 2}, {"intern", 3},
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("Expected %d reports, got %d: %+v", len(want), len(reports), reports)
+	}
+	for i, w := range want {
+		if reports[i].ID != w.id || reports[i].Depth != w.depth {
+			t.Errorf("Report %d: expected %s at depth %d, got %s at depth %d", i, w.id, w.depth, reports[i].ID, reports[i].Depth)
+		}
+	}
+
+	direct, _ := buildUserReports(users, "vp", 1)
+	if len(direct) != 2 {
+		t.Errorf("Expected 2 direct reports with max_depth 1, got %d", len(direct))
+	}
+
+	none, _ := buildUserReports(users, "intern", 0)
+	if len(none) != 0 {
+		t.Errorf("Expected no reports for a leaf user, got %d", len(none))
+	}
+}
+
+func TestBuildUserReports_cycle(t *testing.T) {
+	users := []User{
+		testUserWithManager("a", "c"),
+		testUserWithManager("b", "a"),
+		testUserWithManager("c", "b"),
+	}
+
+	reports, cycle := buildUserReports(users, "a", 0)
+	if !cycle {
+		t.Error("Expected a cycle to be detected")
+	}
+	if len(reports) != 2 {
+		t.Errorf("Expected each user to be reported once, got %d reports", len(reports))
+	}
+}
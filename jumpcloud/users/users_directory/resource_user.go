@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
 )
 
@@ -297,7 +298,7 @@ type User struct {
 }
 
 func ResourceUser() *schema.Resource {
-	return &schema.Resource{
+	r := &schema.Resource{
 		CreateContext: resourceUserCreate,
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
@@ -306,6 +307,10 @@ func ResourceUser() *schema.Resource {
 			StateContext: resourceUserImport,
 		},
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) error {
+			if err := validateManagedFields(diff); err != nil {
+				return err
+			}
+
 			// Validate allowed state transitions
 			if diff.HasChange("state") {
 				oldState, newState := diff.GetChange("state")
@@ -473,6 +478,15 @@ func ResourceUser() *schema.Resource {
 				Default:    false,
 				Deprecated: "This field is deprecated and will be removed in a future version. Use password_authority instead.",
 			},
+			"managed_fields": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Profile attributes Terraform keeps authoritative when externally_managed is true. The remaining synced attributes are read-only and never written. Defaults to none",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(syncedUserFieldNames(), false),
+				},
+			},
 			"ldap_binding_user": {
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -585,9 +599,10 @@ func ResourceUser() *schema.Resource {
 				},
 			},
 			"ssh_keys": {
-				Type:     schema.TypeList,
-				Optional: true,
-				Computed: true,
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				Description: "SSH keys of the user. Use jumpcloud_user_ssh_key instead to manage keys independently of the user",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"name": {
@@ -744,6 +759,9 @@ func ResourceUser() *schema.Resource {
 			},
 		},
 	}
+
+	applyManagedFieldSuppression(r.Schema)
+	return r
 }
 
 func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta any) diag.Diagnostics {
@@ -949,6 +967,10 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta any) d
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("error serializing user: %v", err))
 	}
+	userJSON, err = omitUnmanagedUserFields(d, userJSON)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing user: %v", err))
+	}
 
 	// Create user via API
 	// Use the constant for the system users path
@@ -1679,8 +1701,9 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta any) d
 		user.PhoneNumbers = userPhones
 	}
 
-	// Set SSH keys if present
-	if v, ok := d.GetOk("ssh_keys"); ok {
+	// Only send SSH keys when they changed in the configuration, so keys
+	// managed through jumpcloud_user_ssh_key are not rewritten on every update
+	if v, ok := d.GetOk("ssh_keys"); ok && d.HasChange("ssh_keys") {
 		keys := v.([]any)
 		userKeys := make([]SSHKey, 0, len(keys))
 
@@ -1727,6 +1750,10 @@ func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta any) d
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("error serializing user: %v", err))
 	}
+	userJSON, err = omitUnmanagedUserFields(d, userJSON)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing user: %v", err))
+	}
 
 	// Update user via API
 	// Use the same direct API path as in create and read
@@ -1943,16 +1970,30 @@ func logMFAConversion(ctx context.Context, userState string, days int, config *M
 	}
 }
 
+// userImportLookup resolves username:<username> and email:<email> import IDs
+var userImportLookup = common.ImportLookup{
+	Kind:     "user",
+	ListPath: "/api/systemusers",
+	Fields:   map[string]string{"username": "username", "email": "email"},
+}
+
 // resourceUserImport imports an existing user by ID
 func resourceUserImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	// The ID provided during import should be the JumpCloud user ID
-	userID := d.Id()
-
-	// Validate that the ID is not empty
-	if userID == "" {
+	// The import ID is either the JumpCloud user ID or a lookup such as username:jdoe
+	if d.Id() == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
 
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return nil, fmt.Errorf("error getting client: %s", diagErr[0].Summary)
+	}
+
+	userID, err := common.ResolveImportID(ctx, c, userImportLookup, d.Id())
+	if err != nil {
+		return nil, err
+	}
+
 	// Set the ID in the resource data
 	d.SetId(userID)
 
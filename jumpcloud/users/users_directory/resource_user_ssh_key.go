@@ -0,0 +1,273 @@
+package users_directory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"registry.terraform.io/agilize/jumpcloud/jumpcloud/common"
+)
+
+// supportedSSHKeyTypes lists the public key algorithms accepted by JumpCloud
+var supportedSSHKeyTypes = map[string]bool{
+	"ssh-ed25519":         true,
+	"ssh-rsa":             true,
+	"ecdsa-sha2-nistp256": true,
+	"ecdsa-sha2-nistp384": true,
+	"ecdsa-sha2-nistp521": true,
+}
+
+// ResourceUserSSHKey returns the resource for managing a single SSH key of a JumpCloud user
+func ResourceUserSSHKey() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceUserSSHKeyCreate,
+		ReadContext:   resourceUserSSHKeyRead,
+		DeleteContext: resourceUserSSHKeyDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceUserSSHKeyImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"user_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "ID of the JumpCloud user that owns the key",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the SSH key",
+			},
+			"public_key": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateSSHPublicKey,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return strings.TrimSpace(old) == strings.TrimSpace(new)
+				},
+				Description: "Public key in OpenSSH authorized_keys format (ssh-ed25519, ssh-rsa or ecdsa-sha2-nistp*)",
+			},
+			"key_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the SSH key in JumpCloud",
+			},
+			"create_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Date when the SSH key was created",
+			},
+		},
+		Description: "Manages a single named SSH key of a JumpCloud user. Do not combine with the ssh_keys block of jumpcloud_user for the same user.",
+	}
+}
+
+// validateSSHPublicKey checks that the value is an OpenSSH public key of a supported type
+func validateSSHPublicKey(val interface{}, key string) (warns []string, errs []error) {
+	v := strings.TrimSpace(val.(string))
+
+	fields := strings.Fields(v)
+	if len(fields) < 2 {
+		errs = append(errs, fmt.Errorf("%q must be in the format '<type> <base64-key> [comment]'", key))
+		return
+	}
+
+	keyType := fields[0]
+	if !supportedSSHKeyTypes[keyType] {
+		errs = append(errs, fmt.Errorf("%q has unsupported key type %q, must be one of ssh-ed25519, ssh-rsa, ecdsa-sha2-nistp256, ecdsa-sha2-nistp384 or ecdsa-sha2-nistp521", key, keyType))
+		return
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%q contains invalid base64 key data: %v", key, err))
+		return
+	}
+
+	// The key blob starts with the length-prefixed algorithm name, which must
+	// match the declared type
+	if len(blob) < 4 {
+		errs = append(errs, fmt.Errorf("%q key data is too short", key))
+		return
+	}
+	nameLen := binary.BigEndian.Uint32(blob[:4])
+	if uint64(nameLen) > uint64(len(blob)-4) {
+		errs = append(errs, fmt.Errorf("%q key data is truncated", key))
+		return
+	}
+	if embedded := string(blob[4 : 4+nameLen]); embedded != keyType {
+		errs = append(errs, fmt.Errorf("%q declares type %q but the key data is of type %q", key, keyType, embedded))
+	}
+
+	return
+}
+
+func resourceUserSSHKeyCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	userID := d.Get("user_id").(string)
+	key := SSHKey{
+		Name:      d.Get("name").(string),
+		PublicKey: strings.TrimSpace(d.Get("public_key").(string)),
+	}
+
+	keyJSON, err := json.Marshal(key)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error serializing SSH key: %v", err))
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Creating SSH key %s for user %s", key.Name, userID))
+	resp, err := c.DoRequest(http.MethodPost, fmt.Sprintf("/api/systemusers/%s/sshkeys", userID), keyJSON)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error creating SSH key for user %s: %v", userID, err))
+	}
+
+	var newKey SSHKey
+	if err := json.Unmarshal(resp, &newKey); err != nil {
+		return diag.FromErr(fmt.Errorf("error deserializing SSH key response: %v", err))
+	}
+
+	if newKey.ID == "" {
+		return diag.FromErr(fmt.Errorf("SSH key created for user %s but no ID was returned", userID))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", userID, newKey.ID))
+
+	return resourceUserSSHKeyRead(ctx, d, meta)
+}
+
+func resourceUserSSHKeyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	userID, keyID, err := parseUserSSHKeyID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	keys, err := listUserSSHKeys(c, userID)
+	if err != nil {
+		if common.IsNotFoundError(err) {
+			tflog.Warn(ctx, fmt.Sprintf("User %s not found, removing SSH key %s from state", userID, keyID))
+			d.SetId("")
+			return diags
+		}
+		return diag.FromErr(err)
+	}
+
+	var key *SSHKey
+	for i := range keys {
+		if keys[i].ID == keyID {
+			key = &keys[i]
+			break
+		}
+	}
+
+	if key == nil {
+		tflog.Warn(ctx, fmt.Sprintf("SSH key %s not found for user %s, removing from state", keyID, userID))
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("user_id", userID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting user_id: %v", err))
+	}
+	if err := d.Set("key_id", key.ID); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting key_id: %v", err))
+	}
+	if err := d.Set("name", key.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting name: %v", err))
+	}
+	if err := d.Set("public_key", key.PublicKey); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting public_key: %v", err))
+	}
+	if err := d.Set("create_date", key.CreateDate); err != nil {
+		return diag.FromErr(fmt.Errorf("error setting create_date: %v", err))
+	}
+
+	return diags
+}
+
+func resourceUserSSHKeyDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c, diagErr := common.GetClientFromMeta(meta)
+	if diagErr != nil {
+		return diagErr
+	}
+
+	userID, keyID, err := parseUserSSHKeyID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Deleting SSH key %s of user %s", keyID, userID))
+	_, err = c.DoRequest(http.MethodDelete, fmt.Sprintf("/api/systemusers/%s/sshkeys/%s", userID, keyID), nil)
+	if err != nil && !common.IsNotFoundError(err) {
+		return diag.FromErr(fmt.Errorf("error deleting SSH key %s of user %s: %v", keyID, userID, err))
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// resourceUserSSHKeyImport imports an SSH key using the 'user_id/key_id' format
+func resourceUserSSHKeyImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if _, _, err := parseUserSSHKeyID(d.Id()); err != nil {
+		return nil, err
+	}
+
+	diags := resourceUserSSHKeyRead(ctx, d, meta)
+	if diags.HasError() {
+		var errMsgs []string
+		for _, diag := range diags {
+			errMsgs = append(errMsgs, diag.Summary)
+		}
+		return nil, fmt.Errorf("failed to read SSH key during import: %s", strings.Join(errMsgs, "; "))
+	}
+
+	if d.Id() == "" {
+		return nil, fmt.Errorf("SSH key not found, expected an existing 'user_id/key_id'")
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// listUserSSHKeys retrieves all SSH keys of a user
+func listUserSSHKeys(c common.ClientInterface, userID string) ([]SSHKey, error) {
+	resp, err := c.DoRequest(http.MethodGet, fmt.Sprintf("/api/systemusers/%s/sshkeys", userID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error listing SSH keys for user %s: %w", userID, err)
+	}
+
+	var keys []SSHKey
+	if err := json.Unmarshal(resp, &keys); err != nil {
+		return nil, fmt.Errorf("error parsing SSH keys response: %v", err)
+	}
+
+	return keys, nil
+}
+
+// parseUserSSHKeyID extracts user_id and key_id from the resource ID
+func parseUserSSHKeyID(id string) (string, string, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid SSH key ID %q, expected format 'user_id/key_id'", id)
+	}
+	return parts[0], parts[1], nil
+}
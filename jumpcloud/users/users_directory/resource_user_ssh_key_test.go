@@ -0,0 +1,153 @@
+package users_directory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	jctest "registry.terraform.io/agilize/jumpcloud/jumpcloud/common/testing"
+)
+
+const testEd25519PublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOJlbZI77vvS+ZqHkLJpwRcD0VJe6CaImpmMlhHTNuGq user@example.com"
+
+// TestResourceUserSSHKeySchema tests the schema structure of the user SSH key resource
+func TestResourceUserSSHKeySchema(t *testing.T) {
+	s := ResourceUserSSHKey()
+
+	for _, field := range []string{"user_id", "name", "public_key"} {
+		if s.Schema[field] == nil {
+			t.Fatalf("Expected %s in schema, but it does not exist", field)
+		}
+		if s.Schema[field].Type != schema.TypeString {
+			t.Errorf("Expected %s to be of type string", field)
+		}
+		if !s.Schema[field].Required {
+			t.Errorf("Expected %s to be required", field)
+		}
+		if !s.Schema[field].ForceNew {
+			t.Errorf("Expected %s to force a new resource", field)
+		}
+	}
+
+	for _, field := range []string{"key_id", "create_date"} {
+		if s.Schema[field] == nil {
+			t.Fatalf("Expected %s in schema, but it does not exist", field)
+		}
+		if !s.Schema[field].Computed {
+			t.Errorf("Expected %s to be computed", field)
+		}
+	}
+
+	if s.Importer == nil {
+		t.Error("Expected resource to support import")
+	}
+}
+
+func TestValidateSSHPublicKey(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"ed25519", testEd25519PublicKey, false},
+		{"ed25519 with trailing newline", testEd25519PublicKey + "\n", false},
+		{"ed25519 without comment", "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOJlbZI77vvS+ZqHkLJpwRcD0VJe6CaImpmMlhHTNuGq", false},
+		{"unsupported type", "ssh-dss AAAAB3NzaC1kc3MAAACBAP", true},
+		{"missing key data", "ssh-rsa", true},
+		{"invalid base64", "ssh-rsa not-base64!", true},
+		{"type mismatch", "ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIOJlbZI77vvS+ZqHkLJpwRcD0VJe6CaImpmMlhHTNuGq", true},
+		{"truncated data", "ssh-ed25519 AAAA", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, errs := validateSSHPublicKey(tc.value, "public_key")
+			if tc.wantErr && len(errs) == 0 {
+				t.Errorf("Expected an error for %q", tc.value)
+			}
+			if !tc.wantErr && len(errs) > 0 {
+				t.Errorf("Expected no error for %q, got: %v", tc.value, errs)
+			}
+		})
+	}
+}
+
+func TestParseUserSSHKeyID(t *testing.T) {
+	userID, keyID, err := parseUserSSHKeyID("5f1b1b1b1b1b1b1b1b1b1b1b/6a2c2c2c2c2c2c2c2c2c2c2c")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if userID != "5f1b1b1b1b1b1b1b1b1b1b1b" || keyID != "6a2c2c2c2c2c2c2c2c2c2c2c" {
+		t.Errorf("Unexpected parse result: %s, %s", userID, keyID)
+	}
+
+	for _, id := range []string{"", "only-user", "user/", "/key", "a/b/c"} {
+		if _, _, err := parseUserSSHKeyID(id); err == nil {
+			t.Errorf("Expected an error for ID %q", id)
+		}
+	}
+}
+
+func TestAccResourceUserSSHKey_basic(t *testing.T) {
+	resourceName := "jumpcloud_user_ssh_key.test"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:          func() { jctest.TestAccPreCheck(t) },
+		ProviderFactories: jctest.GetProviderFactories(),
+		CheckDestroy:      testAccCheckJumpCloudUserSSHKeyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccJumpCloudUserSSHKeyConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckJumpCloudUserSSHKeyExists(resourceName),
+					resource.TestCheckResourceAttr(resourceName, "name", "laptop"),
+					resource.TestCheckResourceAttrSet(resourceName, "key_id"),
+				),
+			},
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckJumpCloudUserSSHKeyDestroy(s *terraform.State) error {
+	// Implementation would verify that the key is deleted on the API side
+	return nil
+}
+
+func testAccCheckJumpCloudUserSSHKeyExists(n string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No SSH key ID is set")
+		}
+
+		return nil
+	}
+}
+
+func testAccJumpCloudUserSSHKeyConfig() string {
+	return fmt.Sprintf(`
+resource "jumpcloud_user" "test" {
+  username  = "test-acc-ssh-key"
+  email     = "test-acc-ssh-key@example.com"
+  firstname = "Test"
+  lastname  = "User"
+}
+
+resource "jumpcloud_user_ssh_key" "test" {
+  user_id    = jumpcloud_user.test.id
+  name       = "laptop"
+  public_key = %q
+}
+`, testEd25519PublicKey)
+}
@@ -0,0 +1,107 @@
+package users_directory
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// externallySyncedUserFields maps the profile attributes an external directory (SCIM, Workday,
+// Entra ID) can own to their names in the JumpCloud API
+var externallySyncedUserFields = map[string]string{
+	"firstname":           "firstname",
+	"lastname":            "lastname",
+	"middlename":          "middlename",
+	"displayname":         "displayname",
+	"description":         "description",
+	"alternate_email":     "alternateEmail",
+	"company":             "company",
+	"cost_center":         "costCenter",
+	"department":          "department",
+	"employee_identifier": "employeeIdentifier",
+	"employee_type":       "employeeType",
+	"job_title":           "jobTitle",
+	"location":            "location",
+	"manager_id":          "manager",
+	"attributes":          "attributes",
+	"addresses":           "addresses",
+	"phone_numbers":       "phoneNumbers",
+}
+
+// userFieldGetter is satisfied by both schema.ResourceData and schema.ResourceDiff
+type userFieldGetter interface {
+	Get(key string) interface{}
+}
+
+// syncedUserFieldNames returns the names accepted in managed_fields in a stable order
+func syncedUserFieldNames() []string {
+	names := make([]string, 0, len(externallySyncedUserFields))
+	for name := range externallySyncedUserFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isUserFieldManaged reports whether Terraform plans and writes the given attribute. Every
+// attribute is managed unless the user is externally managed, in which case only the synced
+// attributes listed in managed_fields are
+func isUserFieldManaged(d userFieldGetter, field string) bool {
+	if _, synced := externallySyncedUserFields[field]; !synced {
+		return true
+	}
+	if externallyManaged, _ := d.Get("externally_managed").(bool); !externallyManaged {
+		return true
+	}
+	managed, ok := d.Get("managed_fields").(*schema.Set)
+	return ok && managed.Contains(field)
+}
+
+// suppressUnmanagedUserField hides the diff of synced attributes Terraform does not manage, so the
+// values written by the external directory are kept as read-only state
+func suppressUnmanagedUserField(k, old, new string, d *schema.ResourceData) bool {
+	return !isUserFieldManaged(d, strings.SplitN(k, ".", 2)[0])
+}
+
+// applyManagedFieldSuppression attaches suppressUnmanagedUserField to every synced attribute
+func applyManagedFieldSuppression(s map[string]*schema.Schema) {
+	for field := range externallySyncedUserFields {
+		if attr, ok := s[field]; ok && attr.DiffSuppressFunc == nil {
+			attr.DiffSuppressFunc = suppressUnmanagedUserField
+		}
+	}
+}
+
+// validateManagedFields rejects managed_fields on users that are not externally managed
+func validateManagedFields(d *schema.ResourceDiff) error {
+	managed, ok := d.Get("managed_fields").(*schema.Set)
+	if !ok || managed.Len() == 0 {
+		return nil
+	}
+	if !d.Get("externally_managed").(bool) {
+		return fmt.Errorf("managed_fields can only be set when externally_managed is true")
+	}
+	return nil
+}
+
+// omitUnmanagedUserFields removes the synced attributes Terraform does not manage from a
+// serialized user, leaving their values untouched in JumpCloud
+func omitUnmanagedUserFields(d userFieldGetter, userJSON []byte) ([]byte, error) {
+	if externallyManaged, _ := d.Get("externally_managed").(bool); !externallyManaged {
+		return userJSON, nil
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(userJSON, &body); err != nil {
+		return nil, err
+	}
+	for field, apiName := range externallySyncedUserFields {
+		if !isUserFieldManaged(d, field) {
+			delete(body, apiName)
+		}
+	}
+	return json.Marshal(body)
+}
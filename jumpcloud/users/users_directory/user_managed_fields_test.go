@@ -0,0 +1,139 @@
+package users_directory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestIsUserFieldManaged(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		field    string
+		expected bool
+	}{
+		{
+			name:     "not externally managed",
+			raw:      map[string]interface{}{"username": "jdoe", "email": "jdoe@example.com"},
+			field:    "job_title",
+			expected: true,
+		},
+		{
+			name:     "externally managed defaults to read-only",
+			raw:      map[string]interface{}{"username": "jdoe", "email": "jdoe@example.com", "externally_managed": true},
+			field:    "department",
+			expected: false,
+		},
+		{
+			name: "externally managed with field listed",
+			raw: map[string]interface{}{
+				"username": "jdoe", "email": "jdoe@example.com", "externally_managed": true,
+				"managed_fields": []interface{}{"department"},
+			},
+			field:    "department",
+			expected: true,
+		},
+		{
+			name:     "non synced field is always managed",
+			raw:      map[string]interface{}{"username": "jdoe", "email": "jdoe@example.com", "externally_managed": true},
+			field:    "state",
+			expected: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, ResourceUser().Schema, tc.raw)
+			if got := isUserFieldManaged(d, tc.field); got != tc.expected {
+				t.Errorf("isUserFieldManaged(%q) = %v, want %v", tc.field, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestOmitUnmanagedUserFields(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, ResourceUser().Schema, map[string]interface{}{
+		"username":           "jdoe",
+		"email":              "jdoe@example.com",
+		"externally_managed": true,
+		"managed_fields":     []interface{}{"company"},
+	})
+
+	user := &User{Username: "jdoe", Email: "jdoe@example.com", Company: "Acme", JobTitle: "Engineer", Manager: &Manager{ID: "5f1b1b1b1b1b1b1b1b1b1b1b"}}
+	userJSON, _ := json.Marshal(user)
+
+	out, err := omitUnmanagedUserFields(d, userJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(out, &body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["company"] != "Acme" {
+		t.Errorf("expected managed company to be sent, got %v", body["company"])
+	}
+	for _, key := range []string{"jobTitle", "manager"} {
+		if _, ok := body[key]; ok {
+			t.Errorf("expected unmanaged %s to be omitted", key)
+		}
+	}
+	if body["username"] != "jdoe" {
+		t.Errorf("expected username to be kept, got %v", body["username"])
+	}
+}
+
+func TestResourceUserDiffSuppressesUnmanagedFields(t *testing.T) {
+	r := ResourceUser()
+	state := &terraform.InstanceState{
+		ID: "5f1b1b1b1b1b1b1b1b1b1b1b",
+		Attributes: map[string]string{
+			"id":                 "5f1b1b1b1b1b1b1b1b1b1b1b",
+			"username":           "jdoe",
+			"email":              "jdoe@example.com",
+			"externally_managed": "true",
+			"job_title":          "Synced Title",
+			"department":         "Synced Department",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"username":           "jdoe",
+		"email":              "jdoe@example.com",
+		"externally_managed": true,
+		"managed_fields":     []interface{}{"department"},
+		"job_title":          "Configured Title",
+		"department":         "Configured Department",
+	})
+
+	diff, err := r.Diff(context.Background(), state, config, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff == nil {
+		t.Fatal("expected a diff for the managed department")
+	}
+	if _, ok := diff.Attributes["job_title"]; ok {
+		t.Error("expected the unmanaged job_title diff to be suppressed")
+	}
+	if attr, ok := diff.Attributes["department"]; !ok || attr.New != "Configured Department" {
+		t.Errorf("expected the managed department to be planned, got %#v", attr)
+	}
+}
+
+func TestResourceUserManagedFieldsRequireExternallyManaged(t *testing.T) {
+	r := ResourceUser()
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"username":       "jdoe",
+		"email":          "jdoe@example.com",
+		"managed_fields": []interface{}{"department"},
+	})
+
+	if _, err := r.Diff(context.Background(), nil, config, nil); err == nil {
+		t.Error("expected an error when managed_fields is set without externally_managed")
+	}
+}